@@ -11,17 +11,54 @@ import (
 // Protocol constants
 const (
 	version = "1.0.0"
+	// DRPC_PROTOCOL_PREFIX is the versionless prefix of the dRPC protocol identifier
+	DRPC_PROTOCOL_PREFIX = "/drpc/"
+	// DRPC_WEB_STREAM_PROTOCOL_PREFIX is the versionless prefix of the web stream protocol identifier
+	DRPC_WEB_STREAM_PROTOCOL_PREFIX = "/drpc-webstream/"
+	// DRPC_SERVICE_PROTOCOL_PREFIX prefixes the marker protocols listing the services a host serves.
+	// They are announced through identify; streams opened on them are reset.
+	DRPC_SERVICE_PROTOCOL_PREFIX = "/drpc-service/"
+	// DRPC_RESOURCE_SERVICE is the libp2p resource manager service inbound dRPC streams are attached to
+	DRPC_RESOURCE_SERVICE = "drpc"
+	// DRPC_WEB_STREAM_RESOURCE_SERVICE is the libp2p resource manager service inbound web streams are attached to
+	DRPC_WEB_STREAM_RESOURCE_SERVICE = "drpc-webstream"
 	// DRPC_PROTOCOL_ID is the protocol identifier used for dRPC communications
-	DRPC_PROTOCOL_ID protocol.ID = "/drpc/" + version
+	DRPC_PROTOCOL_ID protocol.ID = DRPC_PROTOCOL_PREFIX + version
 	// DRPC_WEB_STREAM_PROTOCOL_ID is used for web clients requiring a streaming bridge
 	// to enable client-side and bidirectional streaming with ConnectRPC handlers.
-	DRPC_WEB_STREAM_PROTOCOL_ID protocol.ID = "/drpc-webstream/" + version
+	DRPC_WEB_STREAM_PROTOCOL_ID protocol.ID = DRPC_WEB_STREAM_PROTOCOL_PREFIX + version
 )
 
+// DRPC_PROTOCOL_VERSIONS lists the wire versions spoken by default.
+// Servers register every version; clients and gateways negotiate the highest one both sides support.
+var DRPC_PROTOCOL_VERSIONS = []string{version}
+
+// DEFAULT_LISTEN_ADDRS are the libp2p listen addresses used unless others are configured.
+// WebSocket is listed first for browser compatibility.
+var DEFAULT_LISTEN_ADDRS = []string{"/ip4/0.0.0.0/tcp/0/ws", "/ip4/0.0.0.0/tcp/0"}
+
 // Connection constants
 const (
 	// CONNECTION_TIMEOUT is the maximum time to wait when establishing connections
 	CONNECTION_TIMEOUT = 60 * time.Second
+
+	// CONN_MANAGER_LOW_WATER is the connection count the connection manager trims down to
+	CONN_MANAGER_LOW_WATER = 100
+
+	// CONN_MANAGER_HIGH_WATER is the connection count above which the connection manager starts trimming
+	CONN_MANAGER_HIGH_WATER = 400
+
+	// CONN_MANAGER_GRACE_PERIOD is how long new connections are protected from trimming
+	CONN_MANAGER_GRACE_PERIOD = time.Minute
+)
+
+// Connection pool constants
+const (
+	// POOL_MAX_IDLE_TIME is how long an idle peer's pooled streams are kept
+	POOL_MAX_IDLE_TIME = 5 * time.Minute
+
+	// POOL_MAX_STREAMS is the number of idle streams pooled per peer
+	POOL_MAX_STREAMS = 10
 )
 
 // Discovery constants
@@ -32,12 +69,45 @@ const (
 	// pubsub discovery topic
 	DISCOVERY_PUBSUB_TOPIC = DISCOVERY_TAG + "._peer-discovery._p2p._pubsub"
 
+	// DISCOVERY_SERVICE_TAG_PREFIX prefixes the DHT namespace of each advertised dRPC service
+	DISCOVERY_SERVICE_TAG_PREFIX = DISCOVERY_TAG + "/service/"
+
+	// DHT_SERVICE_PROVIDE_TTL is the lifetime of service provider records; they are re-provided before expiry
+	DHT_SERVICE_PROVIDE_TTL = time.Hour
+
+	// DHT_SERVICE_PROVIDER_LIMIT caps the number of providers returned by a single service lookup
+	DHT_SERVICE_PROVIDER_LIMIT = 20
+
+	// DHT_SERVICE_LOOKUP_INTERVAL is the wait between provider lookups while none are found
+	DHT_SERVICE_LOOKUP_INTERVAL = time.Second
+
 	// DHT_PEER_DISCOVERY_INTERVAL is the interval between DHT peer discovery attempts
 	DHT_PEER_DISCOVERY_INTERVAL = 60 * time.Second
 
+	// PUBSUB_BROADCAST_INTERVAL is the interval between pubsub presence broadcasts
+	PUBSUB_BROADCAST_INTERVAL = 30 * time.Second
+
 	// PEER_CONNECTION_TIMEOUT is the timeout for connecting to a discovered peer
 	PEER_CONNECTION_TIMEOUT = 60 * time.Second
 
+	// ADDRESS_BOOK_SAVE_INTERVAL is how often a changed address book is written to disk
+	ADDRESS_BOOK_SAVE_INTERVAL = 30 * time.Second
+
+	// ADDRESS_BOOK_MAX_PEERS caps the peers kept in an address book, dropping the least recently connected
+	ADDRESS_BOOK_MAX_PEERS = 1000
+
+	// ADDRESS_BOOK_MAX_AGE drops address book peers not connected for this long
+	ADDRESS_BOOK_MAX_AGE = 7 * 24 * time.Hour
+
+	// ADDRESS_BOOK_MAX_ADDRS caps the addresses kept per address book peer
+	ADDRESS_BOOK_MAX_ADDRS = 10
+
+	// ADDRESS_BOOK_RECONNECT_PEERS is how many of the best known peers are redialed at startup
+	ADDRESS_BOOK_RECONNECT_PEERS = 20
+
+	// ADDRESS_BOOK_RECONNECT_CONCURRENCY is how many peers are redialed at once at startup
+	ADDRESS_BOOK_RECONNECT_CONCURRENCY = 4
+
 	// AUTONAT_REFRESH_INTERVAL is how often to refresh NAT status
 	AUTONAT_REFRESH_INTERVAL = 30 * time.Second
 )
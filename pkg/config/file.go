@@ -0,0 +1,468 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"gopkg.in/yaml.v3"
+)
+
+// CONFIG_ENV_PREFIX prefixes the environment variables overriding config file
+// fields: DRPC_<SECTION>_<FIELD>, e.g. DRPC_HTTP_PORT or DRPC_DISCOVERY_MDNS.
+// Lists are comma separated; lists of sections can only be set in the file.
+const CONFIG_ENV_PREFIX = "DRPC"
+
+// redacted replaces secrets in dumps
+const redacted = "<redacted>"
+
+// File is the declarative configuration of a dRPC node, read by LoadFile.
+// Servers use every section; clients ignore http, cors, limits.rate and admin.
+type File struct {
+	HTTP        HTTP        `yaml:"http" json:"http"`
+	Libp2p      Libp2p      `yaml:"libp2p" json:"libp2p"`
+	Identity    Identity    `yaml:"identity" json:"identity"`
+	Discovery   Discovery   `yaml:"discovery" json:"discovery"`
+	Relay       Relay       `yaml:"relay" json:"relay"`
+	CORS        CORS        `yaml:"cors" json:"cors"`
+	Connections Connections `yaml:"connections" json:"connections"`
+	Limits      Limits      `yaml:"limits" json:"limits"`
+	Pool        Pool        `yaml:"pool" json:"pool"`
+	Admin       Admin       `yaml:"admin" json:"admin"`
+}
+
+// HTTP configures the server's HTTP listener
+type HTTP struct {
+	Host string `yaml:"host" json:"host"`
+	Port int    `yaml:"port" json:"port"` // -1 disables the listener, 0 picks a free port
+	TLS  TLS    `yaml:"tls" json:"tls"`
+	H2C  *bool  `yaml:"h2c,omitempty" json:"h2c,omitempty"` // unset: on without TLS, off with TLS
+}
+
+// TLS names the key pair served on the HTTP listener; both or neither are set
+type TLS struct {
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+}
+
+// Libp2p configures the libp2p host
+type Libp2p struct {
+	ListenAddrs      []string `yaml:"listen_addrs" json:"listen_addrs"`
+	ProtocolVersions []string `yaml:"protocol_versions" json:"protocol_versions"`
+	// SwarmKeyFile is a swarm.key pre-shared key restricting the host to a private network
+	SwarmKeyFile string `yaml:"swarm_key_file,omitempty" json:"swarm_key_file,omitempty"`
+}
+
+// Identity sets the host's key, either inline or in a key file that is
+// generated on first start. Without one a random key is generated on every start.
+type Identity struct {
+	// PrivateKey is a base64 libp2p protobuf-encoded private key
+	PrivateKey string `yaml:"private_key,omitempty" json:"private_key,omitempty"`
+	KeyFile    string `yaml:"key_file,omitempty" json:"key_file,omitempty"`
+	KeyType    string `yaml:"key_type,omitempty" json:"key_type,omitempty"` // ed25519 (default), secp256k1 or rsa
+	// Passphrase encrypts the key file; best set through DRPC_IDENTITY_PASSPHRASE
+	Passphrase string `yaml:"passphrase,omitempty" json:"passphrase,omitempty"`
+}
+
+// Discovery configures how peers are found
+type Discovery struct {
+	MDNS                 bool     `yaml:"mdns" json:"mdns"`
+	Pubsub               bool     `yaml:"pubsub" json:"pubsub"`
+	BroadcastInterval    Duration `yaml:"broadcast_interval" json:"broadcast_interval"`
+	DHTMode              string   `yaml:"dht_mode" json:"dht_mode"` // auto, client or server; clients are always in client mode
+	DHTDiscoveryInterval Duration `yaml:"dht_discovery_interval" json:"dht_discovery_interval"`
+	// BootstrapPeers are /p2p multiaddrs; empty uses the public IPFS bootstrap peers
+	BootstrapPeers []string `yaml:"bootstrap_peers,omitempty" json:"bootstrap_peers,omitempty"`
+	// AddressBook is a file persisting known peers across restarts
+	AddressBook string `yaml:"address_book,omitempty" json:"address_book,omitempty"`
+}
+
+// Relay configures circuit relay v2
+type Relay struct {
+	Enabled bool `yaml:"enabled" json:"enabled"` // dial and listen through relays
+	Service bool `yaml:"service" json:"service"` // relay for other peers when publicly reachable
+	// StaticRelays are /p2p multiaddrs of relays to reserve slots on when not publicly reachable
+	StaticRelays []string `yaml:"static_relays,omitempty" json:"static_relays,omitempty"`
+}
+
+// CORS configures the CORS headers of the HTTP listener; empty lists keep the defaults
+type CORS struct {
+	Enabled        bool     `yaml:"enabled" json:"enabled"`
+	AllowedOrigins []string `yaml:"allowed_origins,omitempty" json:"allowed_origins,omitempty"`
+	AllowedMethods []string `yaml:"allowed_methods,omitempty" json:"allowed_methods,omitempty"`
+	AllowedHeaders []string `yaml:"allowed_headers,omitempty" json:"allowed_headers,omitempty"`
+	ExposedHeaders []string `yaml:"exposed_headers,omitempty" json:"exposed_headers,omitempty"`
+}
+
+// Connections configures the connection manager and connect timeouts
+type Connections struct {
+	LowWater       int      `yaml:"low_water" json:"low_water"`
+	HighWater      int      `yaml:"high_water" json:"high_water"`
+	GracePeriod    Duration `yaml:"grace_period" json:"grace_period"`
+	ConnectTimeout Duration `yaml:"connect_timeout" json:"connect_timeout"` // clients connecting to a server
+}
+
+// Limits configures rate, resource and size limits and the libp2p accept queue
+type Limits struct {
+	Rate        RateLimits     `yaml:"rate" json:"rate"`
+	Resources   ResourceLimits `yaml:"resources" json:"resources"`
+	Size        SizeLimits     `yaml:"size" json:"size"`
+	AcceptQueue AcceptQueue    `yaml:"accept_queue" json:"accept_queue"`
+}
+
+// RateLimits mirrors the server's rate limits; no limits disable the limiter
+type RateLimits struct {
+	PerCaller  RateLimit            `yaml:"per_caller" json:"per_caller"`
+	Procedures []ProcedureRateLimit `yaml:"procedures,omitempty" json:"procedures,omitempty"`
+}
+
+// RateLimit is a token bucket refilled with Rate tokens per second and holding Burst tokens
+type RateLimit struct {
+	Rate  float64 `yaml:"rate" json:"rate"`
+	Burst int     `yaml:"burst" json:"burst"`
+}
+
+// ProcedureRateLimit limits the procedures matching Procedure
+type ProcedureRateLimit struct {
+	Procedure string `yaml:"procedure" json:"procedure"`
+	RateLimit `yaml:",inline"`
+}
+
+// ResourceLimits mirrors the libp2p resource manager limits of the dRPC protocols;
+// all zero keeps libp2p's resource manager untouched
+type ResourceLimits struct {
+	DRPC      StreamLimits `yaml:"drpc" json:"drpc"`
+	WebStream StreamLimits `yaml:"web_stream" json:"web_stream"`
+}
+
+// StreamLimits caps the streams and reserved memory of one protocol family
+type StreamLimits struct {
+	Streams        int   `yaml:"streams" json:"streams"`
+	StreamsPerPeer int   `yaml:"streams_per_peer" json:"streams_per_peer"`
+	Memory         int64 `yaml:"memory" json:"memory"`
+	MemoryPerPeer  int64 `yaml:"memory_per_peer" json:"memory_per_peer"`
+}
+
+// SizeLimits mirrors the server's request, message and response size limits in
+// bytes and its concurrent HTTP/2 streams per connection; zero leaves a limit unset
+type SizeLimits struct {
+	MaxRequestBytes      int64  `yaml:"max_request_bytes" json:"max_request_bytes"`
+	MaxMessageBytes      int64  `yaml:"max_message_bytes" json:"max_message_bytes"`
+	MaxResponseBytes     int64  `yaml:"max_response_bytes" json:"max_response_bytes"`
+	MaxConcurrentStreams uint32 `yaml:"max_concurrent_streams" json:"max_concurrent_streams"`
+}
+
+// AcceptQueue mirrors the queue of libp2p streams waiting for the server;
+// zero keeps the defaults
+type AcceptQueue struct {
+	Size              int    `yaml:"size" json:"size"`
+	MaxPendingPerPeer int    `yaml:"max_pending_per_peer" json:"max_pending_per_peer"`
+	Overflow          string `yaml:"overflow,omitempty" json:"overflow,omitempty"` // reset (default) or busy
+}
+
+// Pool tunes the libp2p stream pool
+type Pool struct {
+	MaxIdleTime Duration `yaml:"max_idle_time" json:"max_idle_time"`
+	MaxStreams  int      `yaml:"max_streams" json:"max_streams"`
+}
+
+// Admin enables the admin service of a server for the listed peers and,
+// with HTTP, for callers on the local HTTP listener
+type Admin struct {
+	Peers []string `yaml:"peers,omitempty" json:"peers,omitempty"` // peer IDs
+	HTTP  bool     `yaml:"http" json:"http"`
+}
+
+// Duration is a time.Duration written as a string such as "30s" or "5m"
+type Duration time.Duration
+
+// Std returns d as a time.Duration
+func (d Duration) Std() time.Duration { return time.Duration(d) }
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// DefaultFile returns the configuration a node runs with when nothing is set
+func DefaultFile() File {
+	return File{
+		HTTP: HTTP{Host: "localhost"},
+		Libp2p: Libp2p{
+			ListenAddrs:      slices.Clone(DEFAULT_LISTEN_ADDRS),
+			ProtocolVersions: slices.Clone(DRPC_PROTOCOL_VERSIONS),
+		},
+		Discovery: Discovery{
+			MDNS:                 true,
+			Pubsub:               true,
+			BroadcastInterval:    Duration(PUBSUB_BROADCAST_INTERVAL),
+			DHTMode:              "auto",
+			DHTDiscoveryInterval: Duration(DHT_PEER_DISCOVERY_INTERVAL),
+		},
+		Relay: Relay{Enabled: true},
+		Connections: Connections{
+			LowWater:       CONN_MANAGER_LOW_WATER,
+			HighWater:      CONN_MANAGER_HIGH_WATER,
+			GracePeriod:    Duration(CONN_MANAGER_GRACE_PERIOD),
+			ConnectTimeout: Duration(CONNECTION_TIMEOUT),
+		},
+		Pool: Pool{
+			MaxIdleTime: Duration(POOL_MAX_IDLE_TIME),
+			MaxStreams:  POOL_MAX_STREAMS,
+		},
+	}
+}
+
+// LoadFile reads a YAML (.yaml, .yml) or JSON (.json) config file over the
+// defaults, applies DRPC_* environment overrides and validates the result.
+// An empty path reads the environment only. Unknown fields are errors.
+func LoadFile(path string) (File, error) {
+	f := DefaultFile()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return File{}, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := f.decode(data, filepath.Ext(path)); err != nil {
+			return File{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(&f).Elem(), CONFIG_ENV_PREFIX, os.LookupEnv); err != nil {
+		return File{}, fmt.Errorf("invalid config environment: %w", err)
+	}
+	if err := f.Validate(); err != nil {
+		return File{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return f, nil
+}
+
+// decode merges a document in the format of the file extension ext into f
+func (f *File) decode(data []byte, ext string) error {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(f)
+	default:
+		return fmt.Errorf("unsupported extension %q, want .yaml, .yml or .json", ext)
+	}
+}
+
+// applyEnv overrides the fields of the struct v from environment variables
+// named prefix_<FIELD>, descending into nested sections
+func applyEnv(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		key := prefix + "_" + strings.ToUpper(name)
+		field := v.Field(i)
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			if err := applyEnv(field, key, lookup); err != nil {
+				return err
+			}
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.String:
+			// lists of sections are file only
+		default:
+			value, ok := lookup(key)
+			if !ok {
+				continue
+			}
+			if err := setEnvField(field, value); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setEnvField parses value into field
+func setEnvField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		// Scalars, durations and optional scalars parse like YAML values
+		return yaml.Unmarshal([]byte(value), field.Addr().Interface())
+	}
+	return nil
+}
+
+// Validate reports every invalid field of f
+func (f *File) Validate() error {
+	var errs []error
+	check := func(ok bool, field, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(f.HTTP.Host != "", "http.host", "must not be empty")
+	check(f.HTTP.Port >= -1 && f.HTTP.Port <= 65535, "http.port", "must be -1 (disabled), 0 (auto) or 1-65535")
+	check((f.HTTP.TLS.CertFile == "") == (f.HTTP.TLS.KeyFile == ""), "http.tls", "cert_file and key_file must both be set")
+
+	check(len(f.Libp2p.ListenAddrs) > 0, "libp2p.listen_addrs", "must not be empty")
+	for i, addr := range f.Libp2p.ListenAddrs {
+		_, err := multiaddr.NewMultiaddr(addr)
+		check(err == nil, fmt.Sprintf("libp2p.listen_addrs[%d]", i), "%v", err)
+	}
+	for i, v := range f.Libp2p.ProtocolVersions {
+		check(v != "", fmt.Sprintf("libp2p.protocol_versions[%d]", i), "must not be empty")
+	}
+
+	_, err := f.Identity.Key()
+	check(err == nil, "identity.private_key", "%v", err)
+	check(f.Identity.PrivateKey == "" || f.Identity.KeyFile == "", "identity", "private_key and key_file are exclusive")
+	check(slices.Contains([]string{"", "ed25519", "secp256k1", "rsa"}, f.Identity.KeyType), "identity.key_type", "must be ed25519, secp256k1 or rsa")
+	check(f.Identity.KeyFile != "" || f.Identity.KeyType == "" && f.Identity.Passphrase == "", "identity", "key_type and passphrase require key_file")
+
+	check(slices.Contains([]string{"auto", "client", "server"}, f.Discovery.DHTMode), "discovery.dht_mode", "must be auto, client or server")
+	check(f.Discovery.BroadcastInterval > 0, "discovery.broadcast_interval", "must be positive")
+	check(f.Discovery.DHTDiscoveryInterval > 0, "discovery.dht_discovery_interval", "must be positive")
+	for i, addr := range f.Discovery.BootstrapPeers {
+		_, err := peer.AddrInfoFromString(addr)
+		check(err == nil, fmt.Sprintf("discovery.bootstrap_peers[%d]", i), "%v", err)
+	}
+
+	check(f.Relay.Enabled || len(f.Relay.StaticRelays) == 0, "relay.static_relays", "require relay.enabled")
+	for i, addr := range f.Relay.StaticRelays {
+		_, err := peer.AddrInfoFromString(addr)
+		check(err == nil, fmt.Sprintf("relay.static_relays[%d]", i), "%v", err)
+	}
+
+	check(f.Connections.LowWater >= 0, "connections.low_water", "must not be negative")
+	check(f.Connections.HighWater > 0 && f.Connections.HighWater >= f.Connections.LowWater,
+		"connections.high_water", "must be positive and at least low_water")
+	check(f.Connections.GracePeriod >= 0, "connections.grace_period", "must not be negative")
+	check(f.Connections.ConnectTimeout > 0, "connections.connect_timeout", "must be positive")
+
+	checkRate := func(field string, l RateLimit) {
+		check(l.Rate >= 0 && l.Burst >= 0, field, "rate and burst must not be negative")
+		check((l.Rate > 0) == (l.Burst > 0), field, "rate and burst must both be set")
+	}
+	checkRate("limits.rate.per_caller", f.Limits.Rate.PerCaller)
+	for i, p := range f.Limits.Rate.Procedures {
+		field := fmt.Sprintf("limits.rate.procedures[%d]", i)
+		check(p.Procedure == "*" || strings.HasPrefix(p.Procedure, "/"), field, "procedure %q must start with '/' or be '*'", p.Procedure)
+		check(p.Rate > 0, field, "rate must be set")
+		checkRate(field, p.RateLimit)
+	}
+	for name, l := range map[string]StreamLimits{"drpc": f.Limits.Resources.DRPC, "web_stream": f.Limits.Resources.WebStream} {
+		check(l.Streams >= 0 && l.StreamsPerPeer >= 0 && l.Memory >= 0 && l.MemoryPerPeer >= 0,
+			"limits.resources."+name, "must not be negative")
+	}
+	check(f.Limits.Size.MaxRequestBytes >= 0 && f.Limits.Size.MaxMessageBytes >= 0 && f.Limits.Size.MaxResponseBytes >= 0,
+		"limits.size", "must not be negative")
+	check(f.Limits.AcceptQueue.Size >= 0 && f.Limits.AcceptQueue.MaxPendingPerPeer >= 0,
+		"limits.accept_queue", "must not be negative")
+	check(slices.Contains([]string{"", "reset", "busy"}, f.Limits.AcceptQueue.Overflow),
+		"limits.accept_queue.overflow", "must be reset or busy")
+
+	check(f.Pool.MaxIdleTime > 0, "pool.max_idle_time", "must be positive")
+	check(f.Pool.MaxStreams > 0, "pool.max_streams", "must be positive")
+
+	for i, id := range f.Admin.Peers {
+		_, err := peer.Decode(id)
+		check(err == nil, fmt.Sprintf("admin.peers[%d]", i), "%v", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Key decodes the private key, returning nil if none is set
+func (i Identity) Key() (crypto.PrivKey, error) {
+	if i.PrivateKey == "" {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(i.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return crypto.UnmarshalPrivateKey(data)
+}
+
+// Dump encodes f as "yaml" or "json" with secrets redacted
+func (f File) Dump(format string) ([]byte, error) {
+	if f.Identity.PrivateKey != "" {
+		f.Identity.PrivateKey = redacted
+	}
+	if f.Identity.Passphrase != "" {
+		f.Identity.Passphrase = redacted
+	}
+	switch format {
+	case "yaml":
+		return yaml.Marshal(f)
+	case "json":
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(f)
+		return buf.Bytes(), err
+	default:
+		return nil, fmt.Errorf("unsupported dump format %q, want yaml or json", format)
+	}
+}
+
+// Enabled reports whether any rate limit is set
+func (l RateLimits) Enabled() bool {
+	return l.PerCaller.Rate > 0 || len(l.Procedures) > 0
+}
+
+// Enabled reports whether any resource limit is set
+func (l ResourceLimits) Enabled() bool {
+	return l != ResourceLimits{}
+}
+
+// Enabled reports whether any size limit is set
+func (l SizeLimits) Enabled() bool {
+	return l != SizeLimits{}
+}
+
+// Enabled reports whether any caller may use the admin service
+func (a Admin) Enabled() bool {
+	return len(a.Peers) > 0 || a.HTTP
+}
+
+// Enabled reports whether the accept queue differs from the defaults
+func (q AcceptQueue) Enabled() bool {
+	return q != AcceptQueue{}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes content to a file named name in a temporary directory
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	yamlPath := writeConfig(t, "node.yaml", `
+http:
+  port: 8080
+discovery:
+  mdns: false
+  broadcast_interval: 10s
+limits:
+  rate:
+    procedures:
+      - procedure: /pkg.Service/
+        rate: 2
+        burst: 4
+`)
+	jsonPath := writeConfig(t, "node.json", `{
+  "http": {"port": 8080},
+  "discovery": {"mdns": false, "broadcast_interval": "10s"},
+  "limits": {"rate": {"procedures": [{"procedure": "/pkg.Service/", "rate": 2, "burst": 4}]}}
+}`)
+
+	want := DefaultFile()
+	want.HTTP.Port = 8080
+	want.Discovery.MDNS = false
+	want.Discovery.BroadcastInterval = Duration(10 * time.Second)
+	want.Limits.Rate.Procedures = []ProcedureRateLimit{{Procedure: "/pkg.Service/", RateLimit: RateLimit{Rate: 2, Burst: 4}}}
+
+	for _, path := range []string{yamlPath, jsonPath} {
+		f, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile(%s) failed: %v", filepath.Base(path), err)
+		}
+		if !reflect.DeepEqual(f, want) {
+			t.Errorf("LoadFile(%s) = %+v, want %+v", filepath.Base(path), f, want)
+		}
+	}
+}
+
+func TestLoadFileEnvironment(t *testing.T) {
+	t.Setenv("DRPC_HTTP_HOST", "0.0.0.0")
+	t.Setenv("DRPC_HTTP_H2C", "false")
+	t.Setenv("DRPC_LIBP2P_LISTEN_ADDRS", "/ip4/127.0.0.1/tcp/4001, /ip4/127.0.0.1/tcp/4002/ws")
+	t.Setenv("DRPC_CONNECTIONS_GRACE_PERIOD", "5s")
+	t.Setenv("DRPC_LIMITS_RATE_PER_CALLER_RATE", "1.5")
+	t.Setenv("DRPC_LIMITS_RATE_PER_CALLER_BURST", "3")
+
+	f, err := LoadFile(writeConfig(t, "node.yml", "http:\n  host: localhost\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if f.HTTP.Host != "0.0.0.0" {
+		t.Errorf("http.host = %q, want the environment to override the file", f.HTTP.Host)
+	}
+	if f.HTTP.H2C == nil || *f.HTTP.H2C {
+		t.Errorf("http.h2c = %v, want false", f.HTTP.H2C)
+	}
+	if want := []string{"/ip4/127.0.0.1/tcp/4001", "/ip4/127.0.0.1/tcp/4002/ws"}; !reflect.DeepEqual(f.Libp2p.ListenAddrs, want) {
+		t.Errorf("libp2p.listen_addrs = %q, want %q", f.Libp2p.ListenAddrs, want)
+	}
+	if f.Connections.GracePeriod != Duration(5*time.Second) {
+		t.Errorf("connections.grace_period = %s, want 5s", f.Connections.GracePeriod.Std())
+	}
+	if f.Limits.Rate.PerCaller != (RateLimit{Rate: 1.5, Burst: 3}) {
+		t.Errorf("limits.rate.per_caller = %+v", f.Limits.Rate.PerCaller)
+	}
+
+	t.Setenv("DRPC_HTTP_PORT", "http")
+	if _, err := LoadFile(""); err == nil || !strings.Contains(err.Error(), "DRPC_HTTP_PORT") {
+		t.Errorf("Expected an error naming DRPC_HTTP_PORT, got %v", err)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		file, content string
+		want          []string
+	}{
+		"unknown field": {"node.yaml", "http:\n  prot: 80\n", []string{"prot"}},
+		"unknown json":  {"node.json", `{"pool": {"size": 1}}`, []string{"size"}},
+		"extension":     {"node.toml", "", []string{".toml"}},
+		"every invalid field": {"node.yaml", `
+http:
+  port: 70000
+  tls:
+    cert_file: cert.pem
+discovery:
+  dht_mode: full
+connections:
+  low_water: 10
+  high_water: 5
+relay:
+  enabled: false
+  static_relays: [/ip4/1.2.3.4/tcp/1]
+identity:
+  key_type: dsa
+limits:
+  size:
+    max_message_bytes: -1
+  accept_queue:
+    overflow: drop
+admin:
+  peers: [not-a-peer]
+`, []string{"limits.size", "limits.accept_queue.overflow", "admin.peers[0]", "http.port", "http.tls", "discovery.dht_mode", "connections.high_water", "relay.static_relays", "identity.key_type", "require key_file"}},
+	} {
+		_, err := LoadFile(writeConfig(t, tc.file, tc.content))
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: error %q does not mention %q", name, err, want)
+			}
+		}
+	}
+}
+
+func TestDump(t *testing.T) {
+	f := DefaultFile()
+	f.Identity.PrivateKey = "CAESQNf8oLs"
+
+	for _, format := range []string{"yaml", "json"} {
+		data, err := f.Dump(format)
+		if err != nil {
+			t.Fatalf("Dump(%s) failed: %v", format, err)
+		}
+		if strings.Contains(string(data), f.Identity.PrivateKey) || !strings.Contains(string(data), redacted) {
+			t.Errorf("Dump(%s) does not redact the private key", format)
+		}
+
+		// A dump without secrets loads back to the same config
+		f.Identity.PrivateKey = ""
+		data, _ = f.Dump(format)
+		loaded, err := LoadFile(writeConfig(t, "dump."+format, string(data)))
+		if err != nil {
+			t.Fatalf("Loading the %s dump failed: %v", format, err)
+		}
+		if !reflect.DeepEqual(loaded, f) {
+			t.Errorf("%s dump loaded as %+v, want %+v", format, loaded, f)
+		}
+		f.Identity.PrivateKey = "CAESQNf8oLs"
+	}
+}
@@ -0,0 +1,197 @@
+// Package events defines the lifecycle events of dRPC nodes. They are emitted
+// on the libp2p event bus of the node's host, next to the libp2p events, so
+// they can be consumed with Subscribe or any event bus subscription.
+package events
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/omgolab/drpc/pkg/core"
+)
+
+// Source is the discovery service a peer was found by
+type Source string
+
+const (
+	SourceMDNS   Source = "mdns"
+	SourceDHT    Source = "dht"
+	SourcePubsub Source = "pubsub"
+)
+
+// EvtPeerDiscovered is emitted when a discovery service finds a peer, before
+// the host connects to it
+type EvtPeerDiscovered struct {
+	Peer   peer.AddrInfo
+	Source Source
+}
+
+// EvtPeerConnected is emitted when the host gets its first connection to a peer
+type EvtPeerConnected struct {
+	Peer peer.ID
+	// Limited reports whether the connections are resource limited, e.g. relayed
+	Limited bool
+}
+
+// EvtPeerDisconnected is emitted when the host's last connection to a peer closes
+type EvtPeerDisconnected struct {
+	Peer peer.ID
+}
+
+// EvtRPCStarted is emitted when a server starts serving an RPC, on every entry path
+type EvtRPCStarted struct {
+	Procedure string
+	Caller    core.PeerInfo
+}
+
+// EvtRPCFinished is emitted when a server is done serving an RPC
+type EvtRPCFinished struct {
+	Procedure string
+	Caller    core.PeerInfo
+	// Code is the status the RPC ended with, 0 if it succeeded
+	Code connect.Code
+	// RequestBytes and ResponseBytes are the body sizes on the wire
+	RequestBytes  int64
+	ResponseBytes int64
+	Duration      time.Duration
+}
+
+// EvtRelayReservation is emitted when the host gains or loses a reservation,
+// and so a circuit address, on a relay
+type EvtRelayReservation struct {
+	Relay    peer.ID
+	Reserved bool
+}
+
+// EvtReachabilityChanged is emitted when AutoNAT determines whether the host
+// is reachable from the public internet
+type EvtReachabilityChanged struct {
+	Reachability network.Reachability
+}
+
+// EvtHTTPListenerReady is emitted when a server's HTTP listener accepts connections
+type EvtHTTPListenerReady struct {
+	Addr string // with the http:// or https:// scheme
+}
+
+// EvtHTTPListenerFailed is emitted when a server's HTTP listener fails to
+// start or stops serving with an error
+type EvtHTTPListenerFailed struct {
+	Err error
+}
+
+// Types returns the event types defined by this package, as passed to event.Bus.Subscribe
+func Types() []any {
+	return []any{
+		new(EvtPeerDiscovered),
+		new(EvtPeerConnected),
+		new(EvtPeerDisconnected),
+		new(EvtRPCStarted),
+		new(EvtRPCFinished),
+		new(EvtRelayReservation),
+		new(EvtReachabilityChanged),
+		new(EvtHTTPListenerReady),
+		new(EvtHTTPListenerFailed),
+	}
+}
+
+// Subscribe subscribes to every dRPC event emitted on bus. Like every event
+// bus subscription, it should be drained promptly: an Emitter drops the
+// events emitted while its subscribers lag behind its queue.
+func Subscribe(bus event.Bus, opts ...event.SubscriptionOpt) (event.Subscription, error) {
+	return bus.Subscribe(Types(), opts...)
+}
+
+// emitterQueueSize is the number of events an Emitter buffers for delivery
+const emitterQueueSize = 1024
+
+// Emitter emits dRPC events on an event bus. Events are queued and delivered
+// in order by a goroutine started on first use, so that slow subscribers
+// never hold up the emitting component; events emitted while the queue is
+// full are dropped and counted.
+type Emitter struct {
+	bus     event.Bus
+	queue   chan any
+	dropped atomic.Uint64
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+}
+
+// NewEmitter returns an Emitter for bus
+func NewEmitter(bus event.Bus) *Emitter {
+	return &Emitter{bus: bus, queue: make(chan any, emitterQueueSize)}
+}
+
+// Emit queues evt, a value of one of the event types, without blocking. It
+// does nothing on a nil or closed Emitter.
+func (e *Emitter) Emit(evt any) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	if !e.started {
+		e.started = true
+		go e.deliver()
+	}
+	select {
+	case e.queue <- evt:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (e *Emitter) Dropped() uint64 {
+	if e == nil {
+		return 0
+	}
+	return e.dropped.Load()
+}
+
+// deliver emits the queued events on the bus, creating the emitter of each
+// event type on first use, until the Emitter is closed
+func (e *Emitter) deliver() {
+	emitters := make(map[reflect.Type]event.Emitter)
+	for evt := range e.queue {
+		typ := reflect.TypeOf(evt)
+		em, ok := emitters[typ]
+		if !ok {
+			var err error
+			if em, err = e.bus.Emitter(reflect.New(typ).Interface()); err != nil {
+				continue
+			}
+			emitters[typ] = em
+		}
+		_ = em.Emit(evt)
+	}
+	for _, em := range emitters {
+		_ = em.Close()
+	}
+}
+
+// Close stops the Emitter. Events already queued are still delivered, after
+// which the emitters of every event type are closed.
+func (e *Emitter) Close() error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	return nil
+}
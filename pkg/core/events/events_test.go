@@ -0,0 +1,56 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/p2p/host/eventbus"
+)
+
+func TestEmitterDoesNotBlock(t *testing.T) {
+	bus := eventbus.NewBus()
+	emitter := NewEmitter(bus)
+	defer emitter.Close()
+
+	// A subscription nobody drains stalls delivery once its buffer is full
+	sub, err := Subscribe(bus, eventbus.BufSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 2 * emitterQueueSize {
+			emitter.Emit(EvtRPCStarted{Procedure: "/svc/Method"})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Emit blocked on a stalled subscriber")
+	}
+	if emitter.Dropped() == 0 {
+		t.Error("Expected the events over the queue size to be dropped")
+	}
+
+	// Events queued before the subscription closes are still delivered in order
+	fresh, err := Subscribe(bus, eventbus.BufSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fresh.Close()
+	_ = sub.Close()
+	emitter.Emit(EvtPeerDisconnected{})
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case evt := <-fresh.Out():
+			if _, ok := evt.(EvtPeerDisconnected); ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Event emitted after the stalled subscriber closed was not delivered")
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package host
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/omgolab/drpc/pkg/config"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// addressBookVersion is the version of the address book file format
+const addressBookVersion = 1
+
+// addressBooks holds the address book of every host created with one, keyed by peer ID
+var addressBooks sync.Map
+
+// AddressBookEntry is what the address book knows about a peer
+type AddressBookEntry struct {
+	ID            peer.ID         `json:"id"`
+	Addrs         []string        `json:"addrs"`
+	FirstSeen     time.Time       `json:"first_seen"`
+	LastConnected time.Time       `json:"last_connected"`
+	Connections   int             `json:"connections"`        // times a connection was established
+	Uptime        config.Duration `json:"uptime"`             // total time connected
+	Latency       config.Duration `json:"latency,omitempty"`  // moving average, if measured
+	Failures      int             `json:"failures,omitempty"` // reconnects failed since the last connection
+
+	connectedAt time.Time // zero unless connected
+}
+
+// addressBookFile is the on-disk and export format
+type addressBookFile struct {
+	Version int                 `json:"version"`
+	Peers   []*AddressBookEntry `json:"peers"`
+}
+
+// AddressBook persists the peers a host connected to, with their addresses
+// and connection history, so a restarted host can reconnect to recently
+// useful peers before discovery finds them again.
+type AddressBook struct {
+	ctx    context.Context
+	host   host.Host
+	path   string
+	logger glog.Logger
+	notify *network.NotifyBundle
+
+	mu      sync.Mutex
+	entries map[peer.ID]*AddressBookEntry
+	dirty   bool
+	closed  bool
+}
+
+// GetAddressBook returns the address book of a host created by
+// CreateLibp2pHost with WithAddressBook, or nil if it has none
+func GetAddressBook(h host.Host) *AddressBook {
+	book, ok := addressBooks.Load(h.ID())
+	if !ok {
+		return nil
+	}
+	return book.(*AddressBook)
+}
+
+// newAddressBook loads the address book at path into h's peerstore, records
+// h's connections until ctx is done and reconnects to the best known peers
+func newAddressBook(ctx context.Context, h host.Host, path string, logger glog.Logger) (*AddressBook, error) {
+	book := &AddressBook{
+		ctx:     ctx,
+		host:    h,
+		path:    path,
+		logger:  logger,
+		entries: make(map[peer.ID]*AddressBookEntry),
+	}
+
+	f, err := os.Open(path)
+	switch {
+	case err == nil:
+		_, err = book.Import(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load address book %s: %w", path, err)
+		}
+		book.dirty = false
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("failed to open address book: %w", err)
+	}
+
+	book.notify = &network.NotifyBundle{
+		ConnectedF:    func(_ network.Network, c network.Conn) { book.connected(c) },
+		DisconnectedF: func(_ network.Network, c network.Conn) { book.disconnected(c.RemotePeer()) },
+	}
+	h.Network().Notify(book.notify)
+	addressBooks.Store(h.ID(), book)
+
+	go book.reconnect()
+	go book.saveLoop()
+	return book, nil
+}
+
+// connected records a new connection to a peer
+func (b *AddressBook) connected(c network.Conn) {
+	p := c.RemotePeer()
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	entry, ok := b.entries[p]
+	if !ok {
+		entry = &AddressBookEntry{ID: p, FirstSeen: now}
+		b.entries[p] = entry
+	}
+	entry.LastConnected = now
+	entry.Failures = 0
+	if entry.connectedAt.IsZero() {
+		entry.connectedAt = now
+		entry.Connections++
+	}
+	entry.Addrs = mergeAddrs(entry.Addrs, append([]multiaddr.Multiaddr{c.RemoteMultiaddr()}, b.host.Peerstore().Addrs(p)...))
+	b.dirty = true
+}
+
+// disconnected updates a peer's history once its last connection is closed
+func (b *AddressBook) disconnected(p peer.ID) {
+	if b.host.Network().Connectedness(p) == network.Connected {
+		return
+	}
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[p]
+	if !ok || entry.connectedAt.IsZero() {
+		return
+	}
+	entry.Uptime += config.Duration(now.Sub(entry.connectedAt))
+	entry.connectedAt = time.Time{}
+	entry.LastConnected = now
+	if latency := b.host.Peerstore().LatencyEWMA(p); latency > 0 {
+		entry.Latency = config.Duration(latency)
+	}
+	b.dirty = true
+}
+
+// mergeAddrs adds the string forms of addrs missing from known, newest first,
+// keeping at most config.ADDRESS_BOOK_MAX_ADDRS
+func mergeAddrs(known []string, addrs []multiaddr.Multiaddr) []string {
+	merged := make([]string, 0, len(known)+len(addrs))
+	for _, addr := range addrs {
+		if s := addr.String(); !slices.Contains(merged, s) {
+			merged = append(merged, s)
+		}
+	}
+	for _, s := range known {
+		if !slices.Contains(merged, s) {
+			merged = append(merged, s)
+		}
+	}
+	return merged[:min(len(merged), config.ADDRESS_BOOK_MAX_ADDRS)]
+}
+
+// Peers returns the known peers, most recently connected first
+func (b *AddressBook) Peers() []AddressBookEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	peers := make([]AddressBookEntry, 0, len(b.entries))
+	for _, entry := range b.sortedLocked() {
+		e := *entry
+		e.Addrs = slices.Clone(entry.Addrs)
+		if !e.connectedAt.IsZero() {
+			e.Uptime += config.Duration(time.Since(e.connectedAt))
+		}
+		e.connectedAt = time.Time{}
+		peers = append(peers, e)
+	}
+	return peers
+}
+
+// sortedLocked returns the entries ordered by how useful reconnecting is:
+// most recently connected first, then fewest failures, then lowest latency
+func (b *AddressBook) sortedLocked() []*AddressBookEntry {
+	entries := make([]*AddressBookEntry, 0, len(b.entries))
+	for _, entry := range b.entries {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(x, y *AddressBookEntry) int {
+		return cmp.Or(
+			y.LastConnected.Compare(x.LastConnected),
+			cmp.Compare(x.Failures, y.Failures),
+			cmp.Compare(x.Latency, y.Latency),
+			cmp.Compare(x.ID, y.ID),
+		)
+	})
+	return entries
+}
+
+// reconnect dials the best known peers, a few at a time in order
+func (b *AddressBook) reconnect() {
+	b.mu.Lock()
+	candidates := b.sortedLocked()
+	b.mu.Unlock()
+	candidates = candidates[:min(len(candidates), config.ADDRESS_BOOK_RECONNECT_PEERS)]
+
+	work := make(chan peer.ID)
+	var wg sync.WaitGroup
+	for range config.ADDRESS_BOOK_RECONNECT_CONCURRENCY {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				b.reconnectPeer(p)
+			}
+		}()
+	}
+	for _, entry := range candidates {
+		select {
+		case work <- entry.ID:
+		case <-b.ctx.Done():
+		}
+	}
+	close(work)
+	wg.Wait()
+}
+
+// reconnectPeer dials p from its peerstore addresses and counts failures
+func (b *AddressBook) reconnectPeer(p peer.ID) {
+	if b.ctx.Err() != nil || b.host.Network().Connectedness(p) == network.Connected {
+		return
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, config.PEER_CONNECTION_TIMEOUT)
+	defer cancel()
+	if err := b.host.Connect(ctx, peer.AddrInfo{ID: p}); err != nil {
+		if b.ctx.Err() != nil {
+			return
+		}
+		b.mu.Lock()
+		if entry, ok := b.entries[p]; ok {
+			entry.Failures++
+			b.dirty = true
+		}
+		b.mu.Unlock()
+		b.logger.Debug("Failed to reconnect to peer from address book", glog.LogFields{
+			"peer":  p.String(),
+			"error": err.Error(),
+		})
+	}
+}
+
+// saveLoop saves the address book periodically while it changes and once more on close
+func (b *AddressBook) saveLoop() {
+	ticker := time.NewTicker(config.ADDRESS_BOOK_SAVE_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.Save(); err != nil {
+				b.logger.Error("Failed to save address book", err)
+			}
+		case <-b.ctx.Done():
+			b.Close()
+			return
+		}
+	}
+}
+
+// Save writes the address book to its file if it changed since the last save
+func (b *AddressBook) Save() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.dirty {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(b.path), filepath.Base(b.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create address book file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := b.exportLocked(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write address book: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), b.path); err != nil {
+		return fmt.Errorf("failed to replace address book: %w", err)
+	}
+	b.dirty = false
+	return nil
+}
+
+// Export writes the address book in its file format, e.g. to seed another node with Import
+func (b *AddressBook) Export(w io.Writer) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exportLocked(w)
+}
+
+// exportLocked writes the entries, dropping the least recently connected
+// beyond config.ADDRESS_BOOK_MAX_PEERS and those unseen for config.ADDRESS_BOOK_MAX_AGE
+func (b *AddressBook) exportLocked(w io.Writer) error {
+	now := time.Now()
+	file := addressBookFile{Version: addressBookVersion}
+	for _, entry := range b.sortedLocked() {
+		if len(file.Peers) == config.ADDRESS_BOOK_MAX_PEERS || now.Sub(entry.LastConnected) > config.ADDRESS_BOOK_MAX_AGE {
+			break
+		}
+		// Peers still connected are written as of now
+		snapshot := *entry
+		if !entry.connectedAt.IsZero() {
+			snapshot.Uptime += config.Duration(now.Sub(entry.connectedAt))
+			snapshot.LastConnected = now
+		}
+		file.Peers = append(file.Peers, &snapshot)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(file); err != nil {
+		return fmt.Errorf("failed to write address book: %w", err)
+	}
+	return nil
+}
+
+// Import merges peers exported by another address book and adds their
+// addresses to the peerstore. Entries already known keep the newer history.
+// It returns the number of peers added or updated.
+func (b *AddressBook) Import(r io.Reader) (int, error) {
+	var file addressBookFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return 0, fmt.Errorf("invalid address book: %w", err)
+	}
+	if file.Version != addressBookVersion {
+		return 0, fmt.Errorf("unsupported address book version %d", file.Version)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	updated := 0
+	for _, entry := range file.Peers {
+		if entry == nil || entry.ID == b.host.ID() || entry.ID.Validate() != nil {
+			continue
+		}
+		addrs := make([]multiaddr.Multiaddr, 0, len(entry.Addrs))
+		for _, s := range entry.Addrs {
+			if addr, err := multiaddr.NewMultiaddr(s); err == nil {
+				addrs = append(addrs, addr)
+			}
+		}
+		if len(addrs) == 0 {
+			continue
+		}
+		b.host.Peerstore().AddAddrs(entry.ID, addrs, peerstore.RecentlyConnectedAddrTTL)
+
+		known, ok := b.entries[entry.ID]
+		if ok && !entry.LastConnected.After(known.LastConnected) {
+			known.Addrs = mergeAddrs(known.Addrs, addrs)
+		} else {
+			entry.connectedAt = time.Time{}
+			if ok {
+				entry.connectedAt = known.connectedAt
+			}
+			entry.Addrs = mergeAddrs(nil, addrs)
+			b.entries[entry.ID] = entry
+		}
+		updated++
+	}
+	b.dirty = b.dirty || updated > 0
+	return updated, nil
+}
+
+// Close stops recording connections, saves the address book and unregisters it
+func (b *AddressBook) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	b.host.Network().StopNotify(b.notify)
+	if err := b.Save(); err != nil {
+		b.logger.Error("Failed to save address book", err)
+	}
+	addressBooks.CompareAndDelete(b.host.ID(), b)
+}
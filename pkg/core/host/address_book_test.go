@@ -0,0 +1,152 @@
+package host
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// newLoopbackHost creates a plain libp2p host listening on 127.0.0.1
+func newLoopbackHost(t *testing.T) host.Host {
+	t.Helper()
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestAddressBookRecordsConnections(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger, _ := glog.New()
+	path := filepath.Join(t.TempDir(), "peers.json")
+
+	h, remote := newLoopbackHost(t), newLoopbackHost(t)
+	book, err := newAddressBook(ctx, h, path, logger)
+	if err != nil {
+		t.Fatalf("newAddressBook failed: %v", err)
+	}
+	if GetAddressBook(h) != book {
+		t.Error("GetAddressBook does not return the host's address book")
+	}
+
+	if err := h.Connect(ctx, peer.AddrInfo{ID: remote.ID(), Addrs: remote.Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the connection to be recorded", func() bool { return len(book.Peers()) == 1 })
+	h.Network().ClosePeer(remote.ID())
+	waitFor(t, "the disconnection to be recorded", func() bool { return book.Peers()[0].Uptime > 0 })
+
+	entry := book.Peers()[0]
+	if entry.ID != remote.ID() || entry.Connections != 1 || len(entry.Addrs) == 0 {
+		t.Errorf("Recorded %+v, want one connection to %s with its addresses", entry, remote.ID())
+	}
+
+	// Closing saves the book and a new one loads it
+	cancel()
+	waitFor(t, "the address book to be closed", func() bool { return GetAddressBook(h) == nil })
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Address book was not saved: %v", err)
+	}
+	other := newLoopbackHost(t)
+	loaded, err := newAddressBook(t.Context(), other, path, logger)
+	if err != nil {
+		t.Fatalf("Loading the saved address book failed: %v", err)
+	}
+	if peers := loaded.Peers(); len(peers) != 1 || peers[0].ID != remote.ID() || peers[0].Connections != 1 {
+		t.Errorf("Loaded %+v, want the recorded entry", peers)
+	}
+	if len(other.Peerstore().Addrs(remote.ID())) == 0 {
+		t.Error("Loaded addresses were not added to the peerstore")
+	}
+}
+
+func TestAddressBookReconnectsOnStartup(t *testing.T) {
+	ctx := t.Context()
+	logger, _ := glog.New()
+	remote := newLoopbackHost(t)
+
+	// Seed a book through Export and Import, as an operator would
+	seed, err := newAddressBook(ctx, newLoopbackHost(t), filepath.Join(t.TempDir(), "seed.json"), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed.host.Peerstore().AddAddrs(remote.ID(), remote.Addrs(), time.Hour)
+	if err := seed.host.Connect(ctx, peer.AddrInfo{ID: remote.ID()}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the connection to be recorded", func() bool { return len(seed.Peers()) == 1 })
+	var exported bytes.Buffer
+	if err := seed.Export(&exported); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "peers.json")
+	if err := os.WriteFile(path, exported.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := CreateLibp2pHost(ctx,
+		WithHostLogger(logger),
+		WithHostListenAddrs("/ip4/127.0.0.1/tcp/0"),
+		WithAddressBook(path),
+		WithMDNSDiscovery(true),
+		WithPubsubDiscovery(true),
+	)
+	if err != nil {
+		t.Fatalf("CreateLibp2pHost failed: %v", err)
+	}
+	defer h.Close()
+	waitFor(t, "the host to reconnect", func() bool {
+		return h.Network().Connectedness(remote.ID()) == network.Connected
+	})
+
+	book := GetAddressBook(h)
+	if n, err := book.Import(bytes.NewReader(exported.Bytes())); err != nil || n != 1 {
+		t.Errorf("Import = %d, %v; want 1 merged peer", n, err)
+	}
+	if len(book.Peers()) != 1 {
+		t.Errorf("Importing a known peer added an entry: %+v", book.Peers())
+	}
+	if _, err := book.Import(bytes.NewReader([]byte(`{"version": 2}`))); err == nil {
+		t.Error("Expected an unsupported version to fail")
+	}
+}
+
+func TestAddressBookInvalidFile(t *testing.T) {
+	logger, _ := glog.New()
+	path := filepath.Join(t.TempDir(), "peers.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := CreateLibp2pHost(t.Context(),
+		WithHostLogger(logger),
+		WithHostListenAddrs("/ip4/127.0.0.1/tcp/0"),
+		WithAddressBook(path),
+	)
+	if err == nil {
+		t.Error("Expected an invalid address book to fail host creation")
+	}
+}
@@ -0,0 +1,100 @@
+package host
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// BannedPeer is a peer banned with BanPeer
+type BannedPeer struct {
+	ID      peer.ID
+	Expires time.Time // zero for bans without a duration
+}
+
+// banList holds the banned peers of a host with their ban expiry, zero for none
+type banList struct {
+	mu    sync.Mutex
+	peers map[peer.ID]time.Time
+}
+
+// banned reports whether id is banned, forgetting its ban once expired
+func (b *banList) banned(id peer.ID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	expires, ok := b.peers[id]
+	if ok && !expires.IsZero() && time.Now().After(expires) {
+		delete(b.peers, id)
+		return false
+	}
+	return ok
+}
+
+// watchBans closes the connections banned peers open or are dialed on
+func watchBans(m *ManagedHost) {
+	m.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(_ network.Network, c network.Conn) {
+			if m.bans.banned(c.RemotePeer()) {
+				// Closing waits for the swarm, which is notifying us
+				go c.Close()
+			}
+		},
+	})
+}
+
+// BanPeer closes every connection to id and every new one, inbound or
+// outbound, until the ban expires after d or, if d is zero, until UnbanPeer.
+// Bans are not persisted across restarts.
+func (m *ManagedHost) BanPeer(id peer.ID, d time.Duration) error {
+	if id == m.ID() {
+		return errors.New("cannot ban the host itself")
+	}
+	if d < 0 {
+		return errors.New("ban duration cannot be negative")
+	}
+	var expires time.Time
+	if d > 0 {
+		expires = time.Now().Add(d)
+	}
+	m.bans.mu.Lock()
+	if m.bans.peers == nil {
+		m.bans.peers = make(map[peer.ID]time.Time)
+	}
+	m.bans.peers[id] = expires
+	m.bans.mu.Unlock()
+	return m.Network().ClosePeer(id)
+}
+
+// UnbanPeer lifts the ban of id, reporting whether it was banned
+func (m *ManagedHost) UnbanPeer(id peer.ID) bool {
+	banned := m.bans.banned(id)
+	m.bans.mu.Lock()
+	delete(m.bans.peers, id)
+	m.bans.mu.Unlock()
+	return banned
+}
+
+// BannedPeers returns the peers currently banned, sorted by ID
+func (m *ManagedHost) BannedPeers() []BannedPeer {
+	m.bans.mu.Lock()
+	defer m.bans.mu.Unlock()
+	now := time.Now()
+	banned := make([]BannedPeer, 0, len(m.bans.peers))
+	for id, expires := range m.bans.peers {
+		if expires.IsZero() || now.Before(expires) {
+			banned = append(banned, BannedPeer{ID: id, Expires: expires})
+		}
+	}
+	slices.SortFunc(banned, func(a, b BannedPeer) int { return strings.Compare(string(a.ID), string(b.ID)) })
+	return banned
+}
+
+// IsBanned reports whether id is banned
+func (m *ManagedHost) IsBanned(id peer.ID) bool {
+	return m.bans.banned(id)
+}
@@ -7,6 +7,7 @@ import (
 
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/omgolab/drpc/pkg/config"
 	"github.com/omgolab/drpc/pkg/core/pool"
 	pb "github.com/omgolab/drpc/pkg/core/proto"
 	"google.golang.org/protobuf/proto"
@@ -21,10 +22,10 @@ var peerDataPool = pool.NewProtobufMessagePool(func() any {
 func broadcastPeerPresence(ctx context.Context, h host.Host, topic *pubsub.Topic, subscription *pubsub.Subscription, cfg *hostCfg) {
 	defer subscription.Cancel()
 
-	// Use configured broadcast interval, default to 30 seconds if not set
+	// Use configured broadcast interval, default to config.PUBSUB_BROADCAST_INTERVAL if not set
 	interval := cfg.broadcastInterval
 	if interval == 0 {
-		interval = 30 * time.Second
+		interval = config.PUBSUB_BROADCAST_INTERVAL
 	}
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
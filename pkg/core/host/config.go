@@ -1,10 +1,16 @@
 package host
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/libp2p/go-libp2p"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/omgolab/drpc/pkg/config"
 	glog "github.com/omgolab/go-commons/pkg/log"
 )
 
@@ -17,6 +23,20 @@ type hostCfg struct {
 	// relayManager removed - libp2p's built-in AutoRelay handles this automatically
 	disablePubsubDiscovery bool
 	broadcastInterval      time.Duration // Configurable broadcast interval for peer discovery
+	disableMDNSDiscovery   bool
+	dhtDiscoveryInterval   time.Duration // Interval between DHT peer discovery rounds
+	listenAddrs            []string      // Replaces config.DEFAULT_LISTEN_ADDRS if set
+	connManager            *connManagerLimits
+	identity               crypto.PrivKey // nil generates a random key
+	addressBookPath        string         // empty keeps no address book
+	psk                    pnet.PSK       // nil joins the public network
+	eventHandler           func(evt any)  // nil unless WithHostEventHandler is set
+}
+
+// connManagerLimits are the connection manager watermarks and grace period
+type connManagerLimits struct {
+	low, high int
+	grace     time.Duration
 }
 
 // HostOption configures a Host.
@@ -78,3 +98,179 @@ func WithBroadcastInterval(interval time.Duration) HostOption {
 		return nil
 	}
 }
+
+// WithHostIdentity sets the private key, and so the peer ID, of the host.
+func WithHostIdentity(key crypto.PrivKey) HostOption {
+	return func(c *hostCfg) error {
+		if key == nil {
+			return fmt.Errorf("identity key cannot be nil")
+		}
+		c.identity = key
+		return nil
+	}
+}
+
+// WithHostPrivateNetwork restricts the host to peers holding the same 32 byte
+// pre-shared key. The public DHT bootstrap peers are not used, and the DHT,
+// mDNS and pubsub discovery names are specific to the network. Only TCP and
+// WebSocket transports support private networks.
+func WithHostPrivateNetwork(psk pnet.PSK) HostOption {
+	return func(c *hostCfg) error {
+		if len(psk) != 32 {
+			return fmt.Errorf("private network key must be 32 bytes, got %d", len(psk))
+		}
+		c.psk = psk
+		return nil
+	}
+}
+
+// WithAddressBook persists the peers the host connects to, with their
+// addresses and connection history, in a file at path. At startup the file is
+// loaded into the peerstore and the most recently useful peers are redialed.
+// See GetAddressBook to export or import it.
+func WithAddressBook(path string) HostOption {
+	return func(c *hostCfg) error {
+		if path == "" {
+			return fmt.Errorf("address book path cannot be empty")
+		}
+		c.addressBookPath = path
+		return nil
+	}
+}
+
+// WithHostEventHandler calls handler with every dRPC event of the host, see
+// package events. It is called on a single goroutine in the order of the
+// events, from before discovery starts until the host closes. A slow handler
+// does not hold up the host, but events are dropped while it lags behind.
+func WithHostEventHandler(handler func(evt any)) HostOption {
+	return func(c *hostCfg) error {
+		if handler == nil {
+			return fmt.Errorf("event handler cannot be nil")
+		}
+		c.eventHandler = handler
+		return nil
+	}
+}
+
+// WithMDNSDiscovery enables mDNS discovery for the host.
+func WithMDNSDiscovery(isDisable bool) HostOption {
+	return func(c *hostCfg) error {
+		c.disableMDNSDiscovery = isDisable
+		return nil
+	}
+}
+
+// WithDHTDiscoveryInterval sets the interval between DHT peer discovery rounds.
+// If not set, defaults to config.DHT_PEER_DISCOVERY_INTERVAL.
+func WithDHTDiscoveryInterval(interval time.Duration) HostOption {
+	return func(c *hostCfg) error {
+		if interval <= 0 {
+			return fmt.Errorf("DHT discovery interval must be positive")
+		}
+		c.dhtDiscoveryInterval = interval
+		return nil
+	}
+}
+
+// WithHostListenAddrs replaces the default listen addresses of the host.
+func WithHostListenAddrs(addrs ...string) HostOption {
+	return func(c *hostCfg) error {
+		if len(addrs) == 0 {
+			return fmt.Errorf("at least one listen address is required")
+		}
+		c.listenAddrs = addrs
+		return nil
+	}
+}
+
+// WithHostConnManager sets the connection manager watermarks and the grace
+// period protecting new connections from trimming.
+func WithHostConnManager(low, high int, grace time.Duration) HostOption {
+	return func(c *hostCfg) error {
+		if low < 0 || high <= 0 || high < low || grace < 0 {
+			return fmt.Errorf("invalid connection manager limits: low %d, high %d, grace %s", low, high, grace)
+		}
+		c.connManager = &connManagerLimits{low: low, high: high, grace: grace}
+		return nil
+	}
+}
+
+// WithHostConfig applies the libp2p, discovery, relay and connections
+// sections of a config file. Options given later override it. The identity
+// section is left to WithHostIdentity, see identity.FromConfig.
+func WithHostConfig(f config.File) HostOption {
+	return func(c *hostCfg) error {
+		var libp2pOpts []libp2p.Option
+		if !f.Relay.Enabled {
+			libp2pOpts = append(libp2pOpts, libp2p.DisableRelay())
+		}
+		if f.Relay.Service {
+			libp2pOpts = append(libp2pOpts, libp2p.EnableRelayService())
+		}
+		if len(f.Relay.StaticRelays) > 0 {
+			relays, err := parseAddrInfos(f.Relay.StaticRelays)
+			if err != nil {
+				return fmt.Errorf("invalid static relay: %w", err)
+			}
+			libp2pOpts = append(libp2pOpts, libp2p.EnableAutoRelayWithStaticRelays(relays))
+		}
+
+		var dhtOpts []dht.Option
+		switch f.Discovery.DHTMode {
+		case "client":
+			dhtOpts = append(dhtOpts, dht.Mode(dht.ModeClient))
+		case "server":
+			dhtOpts = append(dhtOpts, dht.Mode(dht.ModeServer))
+		}
+		if len(f.Discovery.BootstrapPeers) > 0 {
+			peers, err := parseAddrInfos(f.Discovery.BootstrapPeers)
+			if err != nil {
+				return fmt.Errorf("invalid bootstrap peer: %w", err)
+			}
+			dhtOpts = append(dhtOpts, dht.BootstrapPeers(peers...))
+		}
+
+		if f.Libp2p.SwarmKeyFile != "" {
+			psk, err := LoadSwarmKey(f.Libp2p.SwarmKeyFile)
+			if err != nil {
+				return err
+			}
+			if err := WithHostPrivateNetwork(psk)(c); err != nil {
+				return err
+			}
+		}
+		if f.Discovery.AddressBook != "" {
+			if err := WithAddressBook(f.Discovery.AddressBook)(c); err != nil {
+				return err
+			}
+		}
+		for _, opt := range []HostOption{
+			WithHostListenAddrs(f.Libp2p.ListenAddrs...),
+			WithHostConnManager(f.Connections.LowWater, f.Connections.HighWater, f.Connections.GracePeriod.Std()),
+			WithMDNSDiscovery(!f.Discovery.MDNS),
+			WithPubsubDiscovery(!f.Discovery.Pubsub),
+			WithBroadcastInterval(f.Discovery.BroadcastInterval.Std()),
+			WithDHTDiscoveryInterval(f.Discovery.DHTDiscoveryInterval.Std()),
+			WithHostLibp2pOptions(libp2pOpts...),
+			WithHostDHTOptions(dhtOpts...),
+		} {
+			if err := opt(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// parseAddrInfos parses /p2p multiaddrs, merging the addresses of each peer
+func parseAddrInfos(addrs []string) ([]peer.AddrInfo, error) {
+	maddrs := make([]multiaddr.Multiaddr, 0, len(addrs))
+	for _, addr := range addrs {
+		maddr, err := multiaddr.NewMultiaddr(addr)
+		if err != nil {
+			return nil, err
+		}
+		maddrs = append(maddrs, maddr)
+	}
+	return peer.AddrInfosFromP2pAddrs(maddrs...)
+}
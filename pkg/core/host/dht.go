@@ -10,18 +10,29 @@ import (
 	drouting "github.com/libp2p/go-libp2p/p2p/discovery/routing"
 	dutil "github.com/libp2p/go-libp2p/p2p/discovery/util"
 	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core/events"
 )
 
 // setupDHT initializes the DHT and starts peer discovery if applicable.
 // It relies on the provided dhtOpts to configure behavior, including bootstrapping.
-func setupDHT(ctx context.Context, h host.Host, cfg *hostCfg, userDhtOptions ...dht.Option) (*dht.IpfsDHT, error) {
+// Discovery runs in the background goroutines of m.
+func setupDHT(m *ManagedHost, h host.Host, cfg *hostCfg, userDhtOptions ...dht.Option) (*dht.IpfsDHT, error) {
+	ctx := m.ctx
+	m.bindEvents(h.EventBus())
+	emitter := m.Events()
 	// default dht options
 	dhtOptions := []dht.Option{dht.Mode(dht.ModeAuto)} // Default to server mode
 
-	// Set up DHT with default bootstrap peers
-	// This is a good starting point for peer discovery
-	peers, _ := peer.AddrInfosFromP2pAddrs(dht.DefaultBootstrapPeers...)
-	dhtOptions = append(dhtOptions, dht.BootstrapPeers(peers...))
+	if cfg.psk == nil {
+		// Set up DHT with default bootstrap peers
+		// This is a good starting point for peer discovery
+		peers, _ := peer.AddrInfosFromP2pAddrs(dht.DefaultBootstrapPeers...)
+		dhtOptions = append(dhtOptions, dht.BootstrapPeers(peers...))
+	} else {
+		// Members of a private network run their own DHT, bootstrapped from
+		// configured peers, mDNS, pubsub or the address book
+		dhtOptions = append(dhtOptions, dht.ProtocolPrefix(cfg.dhtProtocolPrefix()))
+	}
 
 	// update dht options with user-provided options
 	if len(userDhtOptions) > 0 && userDhtOptions[0] != nil {
@@ -38,28 +49,47 @@ func setupDHT(ctx context.Context, h host.Host, cfg *hostCfg, userDhtOptions ...
 	// thread that will refresh the peer table every five minutes.
 	cfg.logger.Debug("Bootstrapping the DHT")
 	if err = kademliaDHT.Bootstrap(ctx); err != nil {
+		_ = kademliaDHT.Close()
 		return nil, err
 	}
 
+	// Services are advertised on demand, see GetServiceDiscovery
+	routingDiscovery := drouting.NewRoutingDiscovery(kademliaDHT)
+	discovery := newServiceDiscovery(ctx, h, routingDiscovery, cfg.logger)
+
+	m.mu.Lock()
+	m.discovery = discovery
+	m.discoverPeers = func() error {
+		return findPeers(m.ctx, routingDiscovery, h, cfg, emitter)
+	}
+	m.mu.Unlock()
+
 	// Set up DHT discovery
-	go func() {
+	m.spawn(func(ctx context.Context) {
 		// Wait a moment for DHT to potentially stabilize before advertising/finding
-		time.Sleep(2 * time.Second)
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return
+		}
 
-		routingDiscovery := drouting.NewRoutingDiscovery(kademliaDHT)
 		cfg.logger.Info("Advertising self on DHT")
-		dutil.Advertise(ctx, routingDiscovery, config.DISCOVERY_TAG)
+		dutil.Advertise(ctx, routingDiscovery, cfg.namespaced(config.DISCOVERY_TAG))
 
 		cfg.logger.Info("Starting DHT peer discovery loop")
-		findPeersLoop(ctx, routingDiscovery, h, cfg)
+		findPeersLoop(ctx, routingDiscovery, h, cfg, emitter)
 		cfg.logger.Info("DHT peer discovery loop stopped")
-	}()
+	})
 	return kademliaDHT, nil
 }
 
 // findPeersLoop continuously searches for peers using DHT discovery
-func findPeersLoop(ctx context.Context, routingDiscovery *drouting.RoutingDiscovery, h host.Host, cfg *hostCfg) {
-	ticker := time.NewTicker(config.DHT_PEER_DISCOVERY_INTERVAL)
+func findPeersLoop(ctx context.Context, routingDiscovery *drouting.RoutingDiscovery, h host.Host, cfg *hostCfg, emitter *events.Emitter) {
+	interval := cfg.dhtDiscoveryInterval
+	if interval == 0 {
+		interval = config.DHT_PEER_DISCOVERY_INTERVAL
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -69,25 +99,31 @@ func findPeersLoop(ctx context.Context, routingDiscovery *drouting.RoutingDiscov
 			return
 		case <-ticker.C:
 			// cfg.logger.Debug("Finding peers via DHT")
-			peerChan, err := routingDiscovery.FindPeers(ctx, config.DISCOVERY_TAG)
-			if err != nil {
+			if err := findPeers(ctx, routingDiscovery, h, cfg, emitter); err != nil {
 				cfg.logger.Error("DHT FindPeers error", err)
-				continue // Wait for next tick
 			}
-
-			// Process peers found in this round
-			go connectToFoundPeers(ctx, h, cfg, peerChan)
 		}
 	}
 }
 
+// findPeers starts a DHT discovery round, connecting to the peers it finds in the background
+func findPeers(ctx context.Context, routingDiscovery *drouting.RoutingDiscovery, h host.Host, cfg *hostCfg, emitter *events.Emitter) error {
+	peerChan, err := routingDiscovery.FindPeers(ctx, cfg.namespaced(config.DISCOVERY_TAG))
+	if err != nil {
+		return err
+	}
+	go connectToFoundPeers(ctx, h, cfg, emitter, peerChan)
+	return nil
+}
+
 // connectToFoundPeers connects to peers found via DHT discovery
-func connectToFoundPeers(ctx context.Context, h host.Host, cfg *hostCfg, peerChan <-chan peer.AddrInfo) {
+func connectToFoundPeers(ctx context.Context, h host.Host, cfg *hostCfg, emitter *events.Emitter, peerChan <-chan peer.AddrInfo) {
 	for pi := range peerChan {
 		// Skip connecting to self
 		if pi.ID == h.ID() {
 			continue
 		}
+		emitter.Emit(events.EvtPeerDiscovered{Peer: pi, Source: events.SourceDHT})
 
 		// Create a context for this connection attempt with a timeout
 		connCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
@@ -3,6 +3,8 @@ package host
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	libp2pmdns "github.com/libp2p/go-libp2p/p2p/discovery/mdns"
 	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core/events"
 )
 
 var (
@@ -101,10 +104,45 @@ func (pc *peerCache) cleanExpiredLocked() {
 	}
 }
 
+// DiscoveryCacheEntry is a peer in the mDNS discovery cache, shared by the
+// hosts of the process
+type DiscoveryCacheEntry struct {
+	Peer       peer.AddrInfo
+	Discovered time.Time
+	Attempts   int  // connection attempts since discovered
+	Expired    bool // no longer used for connecting, removed when the cache fills up
+}
+
+// DiscoveryCache returns the entries of the mDNS discovery cache, sorted by peer ID
+func DiscoveryCache() []DiscoveryCacheEntry {
+	return globalPeerCache.snapshot()
+}
+
+// snapshot copies the entries of the cache
+func (pc *peerCache) snapshot() []DiscoveryCacheEntry {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	entries := make([]DiscoveryCacheEntry, 0, len(pc.entries))
+	for _, entry := range pc.entries {
+		entries = append(entries, DiscoveryCacheEntry{
+			Peer:       entry.addrInfo,
+			Discovered: entry.timestamp,
+			Attempts:   entry.attempts,
+			Expired:    time.Since(entry.timestamp) > pc.ttl,
+		})
+	}
+	slices.SortFunc(entries, func(a, b DiscoveryCacheEntry) int {
+		return strings.Compare(string(a.Peer.ID), string(b.Peer.ID))
+	})
+	return entries
+}
+
 // discoveryNotifee gets notified when we find a new peer via mDNS discovery
 type discoveryNotifee struct {
-	h   host.Host
-	cfg *hostCfg
+	h      host.Host
+	cfg    *hostCfg
+	events *events.Emitter // nil emits nothing
 }
 
 // HandlePeerFound connects to peers discovered via mDNS. On error, just log.
@@ -113,6 +151,7 @@ func (n *discoveryNotifee) HandlePeerFound(pi peer.AddrInfo) {
 	if pi.ID == n.h.ID() {
 		return
 	}
+	n.events.Emit(events.EvtPeerDiscovered{Peer: pi, Source: events.SourceMDNS})
 
 	// Check rate limiting for this peer
 	if !globalPeerCache.markAttempt(pi.ID) {
@@ -148,11 +187,12 @@ func (n *discoveryNotifee) HandlePeerFound(pi peer.AddrInfo) {
 	// n.cfg.logger.Info(fmt.Sprintf("Connected to peer via mDNS: %s", pi.ID.String()))
 }
 
-// setupMDNS initializes the mDNS discovery service
-func setupMDNS(h host.Host, cfg *hostCfg) error {
+// setupMDNS initializes the mDNS discovery service, which m stops on Close
+func setupMDNS(m *ManagedHost, cfg *hostCfg) error {
+	h := m.Host
 	// Setup mDNS discovery service
 	cfg.logger.Info("Setting up mDNS discovery")
-	notifee := &discoveryNotifee{h: h, cfg: cfg}
+	notifee := &discoveryNotifee{h: h, cfg: cfg, events: m.Events()}
 	// Use DefaultServiceTag if config.DISCOVERY_TAG is empty
 	tag := config.DISCOVERY_TAG
 	if tag == "" {
@@ -160,7 +200,15 @@ func setupMDNS(h host.Host, cfg *hostCfg) error {
 		tag = ""
 		cfg.logger.Warn("config.DISCOVERY_TAG is empty, using default mDNS tag")
 	}
+	tag = cfg.namespaced(tag)
 	cfg.logger.Debug(fmt.Sprintf("Using mDNS tag: %s", tag))
 	disc := libp2pmdns.NewMdnsService(h, tag, notifee)
-	return disc.Start()
+	if err := disc.Start(); err != nil {
+		_ = disc.Close()
+		return err
+	}
+	m.mu.Lock()
+	m.mdns = disc
+	m.mu.Unlock()
+	return nil
 }
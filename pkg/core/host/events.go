@@ -0,0 +1,112 @@
+package host
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/host/eventbus"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/omgolab/drpc/pkg/core/events"
+)
+
+// bridgeEvents re-emits the libp2p events about peer connections, relay
+// addresses and reachability of m as dRPC events, until m closes
+func bridgeEvents(m *ManagedHost) error {
+	sub, err := m.EventBus().Subscribe([]any{
+		new(event.EvtPeerConnectednessChanged),
+		new(event.EvtLocalAddressesUpdated),
+		new(event.EvtLocalReachabilityChanged),
+	}, eventbus.Name("drpc-events"))
+	if err != nil {
+		return err
+	}
+
+	emitter := m.Events()
+	m.spawn(func(ctx context.Context) {
+		defer sub.Close()
+		connected := make(map[peer.ID]bool)
+		relays := make(map[peer.ID]bool)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				switch evt := e.(type) {
+				case event.EvtPeerConnectednessChanged:
+					switch {
+					case evt.Connectedness == network.NotConnected && connected[evt.Peer]:
+						delete(connected, evt.Peer)
+						emitter.Emit(events.EvtPeerDisconnected{Peer: evt.Peer})
+					case evt.Connectedness == network.Connected || evt.Connectedness == network.Limited:
+						if !connected[evt.Peer] {
+							connected[evt.Peer] = true
+							emitter.Emit(events.EvtPeerConnected{Peer: evt.Peer, Limited: evt.Connectedness == network.Limited})
+						}
+					}
+				case event.EvtLocalAddressesUpdated:
+					relays = emitRelayChanges(emitter, relays, evt.Current)
+				case event.EvtLocalReachabilityChanged:
+					emitter.Emit(events.EvtReachabilityChanged{Reachability: evt.Reachability})
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// emitRelayChanges compares the relays of the circuit addresses in addrs
+// with those of the previous update and emits the reservations gained and lost
+func emitRelayChanges(emitter *events.Emitter, previous map[peer.ID]bool, addrs []event.UpdatedAddress) map[peer.ID]bool {
+	current := make(map[peer.ID]bool)
+	for _, a := range addrs {
+		if _, err := a.Address.ValueForProtocol(ma.P_CIRCUIT); err != nil {
+			continue
+		}
+		// Circuit addresses name the relay in the /p2p component before /p2p-circuit
+		if value, err := a.Address.ValueForProtocol(ma.P_P2P); err == nil {
+			if id, err := peer.Decode(value); err == nil {
+				current[id] = true
+			}
+		}
+	}
+	for id := range current {
+		if !previous[id] {
+			emitter.Emit(events.EvtRelayReservation{Relay: id, Reserved: true})
+		}
+	}
+	for id := range previous {
+		if !current[id] {
+			emitter.Emit(events.EvtRelayReservation{Relay: id, Reserved: false})
+		}
+	}
+	return current
+}
+
+// runEventHandler calls handler with every dRPC event of m, in order on a
+// single goroutine, until m closes
+func runEventHandler(m *ManagedHost, handler func(evt any)) error {
+	sub, err := events.Subscribe(m.EventBus(), eventbus.Name("drpc-event-handler"))
+	if err != nil {
+		return err
+	}
+	m.spawn(func(ctx context.Context) {
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				handler(evt)
+			}
+		}
+	})
+	return nil
+}
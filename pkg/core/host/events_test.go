@@ -0,0 +1,105 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/host/eventbus"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/omgolab/drpc/pkg/core/events"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// nextEvent returns the next event of type T from ch
+func nextEvent[T any](t *testing.T, ch <-chan any) T {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case evt := <-ch:
+			if e, ok := evt.(T); ok {
+				return e
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("Timed out waiting for %T", zero)
+			return zero
+		}
+	}
+}
+
+func TestEmitRelayChanges(t *testing.T) {
+	bus := eventbus.NewBus()
+	emitter := events.NewEmitter(bus)
+	defer emitter.Close()
+	sub, err := events.Subscribe(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	relayA, relayB := "12D3KooWGRUVh7AHfUJDHf4SMwNbKGzbKj1vYLrMAyNRBYpBNHFT", "12D3KooWMqUyMSeEe2jhN4NtNbJd8gGCGRLDaAF2Ks8fhf1KYzKR"
+	addrs := func(relays ...string) []event.UpdatedAddress {
+		updated := []event.UpdatedAddress{{Address: ma.StringCast("/ip4/127.0.0.1/tcp/4001")}}
+		for _, relay := range relays {
+			updated = append(updated, event.UpdatedAddress{Address: ma.StringCast("/ip4/1.2.3.4/tcp/4001/p2p/" + relay + "/p2p-circuit")})
+		}
+		return updated
+	}
+
+	relays := emitRelayChanges(emitter, nil, addrs(relayA))
+	if got := nextEvent[events.EvtRelayReservation](t, sub.Out()); got.Relay.String() != relayA || !got.Reserved {
+		t.Errorf("Unexpected event for a new relay address: %+v", got)
+	}
+	relays = emitRelayChanges(emitter, relays, addrs(relayB))
+	for range 2 {
+		got := nextEvent[events.EvtRelayReservation](t, sub.Out())
+		if got.Reserved != (got.Relay.String() == relayB) {
+			t.Errorf("Unexpected event for a changed relay: %+v", got)
+		}
+	}
+	if relays = emitRelayChanges(emitter, relays, addrs()); len(relays) != 0 {
+		t.Errorf("Relays left after the circuit addresses are gone: %v", relays)
+	}
+	if got := nextEvent[events.EvtRelayReservation](t, sub.Out()); got.Relay.String() != relayB || got.Reserved {
+		t.Errorf("Unexpected event for a lost relay: %+v", got)
+	}
+}
+
+func TestHostEventHandler(t *testing.T) {
+	logger, _ := glog.New()
+	received := make(chan any, 256)
+	h, err := CreateLibp2pHost(t.Context(),
+		WithHostLogger(logger),
+		WithHostListenAddrs("/ip4/127.0.0.1/tcp/0"),
+		WithHostPrivateNetwork(make([]byte, 32)),
+		WithHostEventHandler(func(evt any) { received <- evt }),
+	)
+	if err != nil {
+		t.Fatalf("CreateLibp2pHost failed: %v", err)
+	}
+	defer h.Close()
+
+	remote, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"), libp2p.PrivateNetwork(make([]byte, 32)), libp2p.DefaultPrivateTransports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Connect(t.Context(), peer.AddrInfo{ID: h.ID(), Addrs: h.Addrs()}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if got := nextEvent[events.EvtPeerConnected](t, received); got.Peer != remote.ID() {
+		t.Errorf("Connected event for %s, want %s", got.Peer, remote.ID())
+	}
+
+	_ = remote.Close()
+	if got := nextEvent[events.EvtPeerDisconnected](t, received); got.Peer != remote.ID() {
+		t.Errorf("Disconnected event for %s, want %s", got.Peer, remote.ID())
+	}
+
+	if err := WithHostEventHandler(nil)(&hostCfg{}); err == nil {
+		t.Error("Expected a nil event handler to be rejected")
+	}
+}
@@ -4,6 +4,8 @@ package host
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -15,11 +17,15 @@ import (
 	"github.com/libp2p/go-libp2p/core/routing"
 	connmgr "github.com/libp2p/go-libp2p/p2p/net/connmgr"
 	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core/events"
 	glog "github.com/omgolab/go-commons/pkg/log"
 )
 
-// CreateLibp2pHost creates a new libp2p Host with default settings.
-func CreateLibp2pHost(ctx context.Context, opts ...HostOption) (host.Host, error) {
+// CreateLibp2pHost creates a new libp2p Host with default settings. The
+// discovery services of the host run until it is closed or ctx is done;
+// closing the host stops them and releases its connection pool. The host
+// emits the dRPC events of package events on its event bus.
+func CreateLibp2pHost(ctx context.Context, opts ...HostOption) (*ManagedHost, error) {
 	// apply HostOption to build config
 	cfg := &hostCfg{}
 	for _, o := range opts {
@@ -28,6 +34,7 @@ func CreateLibp2pHost(ctx context.Context, opts ...HostOption) (host.Host, error
 		}
 	}
 	log := cfg.logger
+	managed := newManagedHost(ctx, log)
 	libp2pOpts := cfg.libp2pOptions
 	dhtOpts := cfg.dhtOptions
 
@@ -45,25 +52,45 @@ func CreateLibp2pHost(ctx context.Context, opts ...HostOption) (host.Host, error
 		libp2pOpts = []libp2p.Option{}
 	}
 
+	listenAddrs := cfg.listenAddrs
+	if len(listenAddrs) == 0 {
+		listenAddrs = config.DEFAULT_LISTEN_ADDRS
+	}
+	cmLimits := connManagerLimits{
+		low:   config.CONN_MANAGER_LOW_WATER,
+		high:  config.CONN_MANAGER_HIGH_WATER,
+		grace: config.CONN_MANAGER_GRACE_PERIOD,
+	}
+	if cfg.connManager != nil {
+		cmLimits = *cfg.connManager
+	}
+	cm, err := connmgr.NewConnManager(
+		cmLimits.low,                            // low watermark - start closing connections when we reach this many
+		cmLimits.high,                           // high watermark - maximum connections before more aggressive pruning
+		connmgr.WithGracePeriod(cmLimits.grace), // give connections time to stabilize
+	)
+	if err != nil {
+		managed.cancel()
+		return nil, fmt.Errorf("failed to create connection manager: %w", err)
+	}
+
+	// Private networks cannot share the TCP listener or use QUIC based transports
+	transports := []libp2p.Option{libp2p.ShareTCPListener(), libp2p.DefaultTransports}
+	if cfg.psk != nil {
+		transports = []libp2p.Option{libp2p.PrivateNetwork(cfg.psk), libp2p.DefaultPrivateTransports}
+	}
+
 	// Configure libp2p options
 	options := []libp2p.Option{
-		// Listen on default addresses with WebSocket explicitly listed first for browser compatibility
-		libp2p.ListenAddrStrings("/ip4/0.0.0.0/tcp/0/ws", "/ip4/0.0.0.0/tcp/0"),
-		libp2p.ShareTCPListener(),
+		// Listen with WebSocket explicitly listed first for browser compatibility
+		libp2p.ListenAddrStrings(listenAddrs...),
+		libp2p.ChainOptions(transports...),
 
 		// Connection management to prevent memory leaks from connection accumulation
-		libp2p.ConnectionManager(func() *connmgr.BasicConnMgr {
-			cm, _ := connmgr.NewConnManager(
-				100,                                  // low watermark - start closing connections when we reach this many
-				400,                                  // high watermark - maximum connections before more aggressive pruning
-				connmgr.WithGracePeriod(time.Minute), // give connections time to stabilize
-			)
-			return cm
-		}()),
+		libp2p.ConnectionManager(cm),
 
 		// Use ResourceManager and other defaults (but not FallbackDefaults to avoid connection manager conflict)
 		libp2p.DefaultMuxers,
-		libp2p.DefaultTransports,
 		libp2p.DefaultSecurity,
 
 		// NAT traversal enhancements
@@ -87,20 +114,55 @@ func CreateLibp2pHost(ctx context.Context, opts ...HostOption) (host.Host, error
 		libp2p.Routing(func(h host.Host) (routing.PeerRouting, error) {
 			// Use sync.Once to ensure DHT is only initialized once
 			dhtOnce.Do(func() {
-				kadDHT, dhtErr = setupDHT(ctx, h, cfg, dhtOpts...)
+				kadDHT, dhtErr = setupDHT(managed, h, cfg, dhtOpts...)
 			})
 			return kadDHT, dhtErr
 		}),
 	}
 
+	if cfg.identity != nil {
+		options = append(options, libp2p.Identity(cfg.identity))
+	}
+
 	// Add user-provided libp2p options
 	options = append(options, libp2pOpts...)
 
 	// Create host
 	h, err := libp2p.New(options...)
 	if err != nil {
+		if kadDHT != nil {
+			_ = kadDHT.Close()
+		}
+		managed.cancel()
 		return nil, err
 	}
+	managed.Host = h
+	managed.dht = kadDHT
+	managed.bindEvents(h.EventBus())
+	watchBans(managed)
+	if err := bridgeEvents(managed); err != nil {
+		_ = managed.Close()
+		return nil, fmt.Errorf("failed to subscribe to host events: %w", err)
+	}
+	if cfg.eventHandler != nil {
+		if err := runEventHandler(managed, cfg.eventHandler); err != nil {
+			_ = managed.Close()
+			return nil, fmt.Errorf("failed to subscribe event handler: %w", err)
+		}
+	}
+
+	if cfg.psk != nil {
+		if err := registerPrivateNetwork(h, cfg.psk); err != nil {
+			_ = managed.Close()
+			return nil, fmt.Errorf("failed to record private network: %w", err)
+		}
+	}
+	if cfg.addressBookPath != "" {
+		if _, err := newAddressBook(managed.ctx, h, cfg.addressBookPath, log); err != nil {
+			_ = managed.Close()
+			return nil, err
+		}
+	}
 
 	log.Info("libp2p host created", glog.LogFields{
 		"peerID":    h.ID().String(),
@@ -109,32 +171,35 @@ func CreateLibp2pHost(ctx context.Context, opts ...HostOption) (host.Host, error
 	})
 
 	// Set up discovery services
-	if err := setupMDNS(h, cfg); err != nil {
-		log.Error("Failed to set up mDNS discovery", err)
-		// Don't return error - mDNS is optional
+	if !cfg.disableMDNSDiscovery {
+		if err := setupMDNS(managed, cfg); err != nil {
+			log.Error("Failed to set up mDNS discovery", err)
+			// Don't return error - mDNS is optional
+		}
 	}
 
 	// Set up pubsub discovery if not disabled
 	if !cfg.disablePubsubDiscovery {
-		if err := setupPubsubDiscovery(ctx, h, cfg); err != nil {
+		if err := setupPubsubDiscovery(managed, cfg); err != nil {
 			log.Error("Failed to set up pubsub discovery", err)
 			// Don't return error - pubsub is optional
 		}
 	}
 
-	return h, nil
+	return managed, nil
 }
 
 // setupPubsubDiscovery sets up pubsub-based peer discovery
-func setupPubsubDiscovery(ctx context.Context, h host.Host, cfg *hostCfg) error {
-	// Create a new PubSub service using GossipSub
-	ps, err := pubsub.NewGossipSub(ctx, h)
+func setupPubsubDiscovery(m *ManagedHost, cfg *hostCfg) error {
+	h := m.Host
+	// Create a new PubSub service using GossipSub; it stops with the host's context
+	ps, err := pubsub.NewGossipSub(m.ctx, h)
 	if err != nil {
 		return err
 	}
 
 	// Join the discovery topic
-	topic, err := ps.Join(config.DISCOVERY_PUBSUB_TOPIC)
+	topic, err := ps.Join(cfg.namespaced(config.DISCOVERY_PUBSUB_TOPIC))
 	if err != nil {
 		return err
 	}
@@ -145,23 +210,26 @@ func setupPubsubDiscovery(ctx context.Context, h host.Host, cfg *hostCfg) error
 		return err
 	}
 
-	cfg.logger.Info("Joined pubsub discovery topic", glog.LogFields{"topic": config.DISCOVERY_PUBSUB_TOPIC})
+	cfg.logger.Info("Joined pubsub discovery topic", glog.LogFields{"topic": topic.String()})
+	m.mu.Lock()
+	m.subscription = subscription
+	m.mu.Unlock()
 
 	// Start listening for messages (discovery announcements from other peers)
-	go handlePubsubMessages(ctx, subscription, h, cfg)
+	m.spawn(func(ctx context.Context) { handlePubsubMessages(ctx, subscription, h, cfg, m.Events()) })
 
 	// Start broadcasting our presence periodically
-	go broadcastPeerPresence(ctx, h, topic, subscription, cfg)
+	m.spawn(func(ctx context.Context) { broadcastPeerPresence(ctx, h, topic, subscription, cfg) })
 
 	return nil
 }
 
 // handlePubsubMessages processes incoming pubsub discovery messages
-func handlePubsubMessages(ctx context.Context, subscription *pubsub.Subscription, h host.Host, cfg *hostCfg) {
+func handlePubsubMessages(ctx context.Context, subscription *pubsub.Subscription, h host.Host, cfg *hostCfg, emitter *events.Emitter) {
 	for {
 		msg, err := subscription.Next(ctx)
 		if err != nil {
-			if ctx.Err() != nil {
+			if ctx.Err() != nil || errors.Is(err, pubsub.ErrSubscriptionCancelled) {
 				cfg.logger.Info("Stopping pubsub message handling due to context cancellation")
 				return
 			}
@@ -176,6 +244,7 @@ func handlePubsubMessages(ctx context.Context, subscription *pubsub.Subscription
 
 		// Try to connect to the peer who sent the message
 		peerInfo := peer.AddrInfo{ID: msg.ReceivedFrom}
+		emitter.Emit(events.EvtPeerDiscovered{Peer: peerInfo, Source: events.SourcePubsub})
 
 		// Create a context for this connection attempt with a timeout
 		connCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
@@ -293,7 +293,7 @@ func TestConnectToFoundPeers(t *testing.T) {
 
 	// Test connectToFoundPeers
 	ctx := context.Background()
-	connectToFoundPeers(ctx, mockHost, cfg, peerChan)
+	connectToFoundPeers(ctx, mockHost, cfg, nil, peerChan)
 
 	// Give time for goroutines to complete
 	time.Sleep(100 * time.Millisecond)
@@ -332,7 +332,7 @@ func TestConnectToFoundPeersWithContextCancellation(t *testing.T) {
 	close(peerChan)
 
 	// Test connectToFoundPeers with cancelled context
-	connectToFoundPeers(ctx, mockHost, cfg, peerChan)
+	connectToFoundPeers(ctx, mockHost, cfg, nil, peerChan)
 
 	// Give time for goroutines to complete
 	time.Sleep(100 * time.Millisecond)
@@ -351,10 +351,12 @@ func TestSetupMDNS(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create test host: %v", err)
 	}
-	defer h.Close()
+	m := newManagedHost(context.Background(), logger)
+	m.Host = h
+	defer m.Close()
 
 	// Test setupMDNS function
-	err = setupMDNS(h, cfg)
+	err = setupMDNS(m, cfg)
 	assert.NoError(t, err, "setupMDNS should not return an error")
 }
 
@@ -370,9 +372,11 @@ func TestSetupDHTWithDefaultMode(t *testing.T) {
 		t.Fatalf("Failed to create test host: %v", err)
 	}
 	defer h.Close()
+	m := newManagedHost(ctx, logger)
+	defer m.Close()
 
 	// Test setupDHT with default options
-	kadDHT, err := setupDHT(ctx, h, cfg)
+	kadDHT, err := setupDHT(m, h, cfg)
 
 	// Don't require success since DHT bootstrap might fail in test environment
 	if err == nil {
@@ -444,7 +448,7 @@ func testFindPeersLoop(ctx context.Context, routingDiscovery FindPeersInterface,
 			}
 
 			// Process peers found in this round
-			go connectToFoundPeers(ctx, h, cfg, peerChan)
+			go connectToFoundPeers(ctx, h, cfg, nil, peerChan)
 		}
 	}
 }
@@ -0,0 +1,152 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/host"
+	libp2pmdns "github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+	"github.com/omgolab/drpc/pkg/core/events"
+	"github.com/omgolab/drpc/pkg/core/pool"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+var _ host.Host = (*ManagedHost)(nil)
+
+// ManagedHost is a libp2p host created by CreateLibp2pHost. It owns the
+// discovery services and background goroutines started for the host, which
+// run until Close or until the context passed to CreateLibp2pHost is done.
+type ManagedHost struct {
+	host.Host
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger glog.Logger
+	wg     sync.WaitGroup // background goroutines
+	events atomic.Pointer[events.Emitter]
+	bans   banList
+
+	mu           sync.Mutex
+	dht          *dht.IpfsDHT
+	discovery    *ServiceDiscovery
+	mdns         libp2pmdns.Service
+	subscription *pubsub.Subscription
+	// discoverPeers starts a DHT discovery round, nil until the DHT is set up
+	discoverPeers func() error
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// newManagedHost creates the wrapper of a host that is yet to be created
+func newManagedHost(ctx context.Context, logger glog.Logger) *ManagedHost {
+	m := &ManagedHost{logger: logger}
+	m.ctx, m.cancel = context.WithCancel(ctx)
+	return m
+}
+
+// spawn runs f in a background goroutine that Close waits for. f must
+// return once the host's context is done.
+func (m *ManagedHost) spawn(f func(ctx context.Context)) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		f(m.ctx)
+	}()
+}
+
+// bindEvents creates the emitter of the host's events on bus, unless it exists
+func (m *ManagedHost) bindEvents(bus event.Bus) {
+	if m.events.Load() == nil {
+		m.events.CompareAndSwap(nil, events.NewEmitter(bus))
+	}
+}
+
+// Events returns the emitter of the host's dRPC events, see package events
+func (m *ManagedHost) Events() *events.Emitter {
+	return m.events.Load()
+}
+
+// DiscoverPeers starts a DHT discovery round without waiting for the next
+// interval. The peers found are connected to in the background.
+func (m *ManagedHost) DiscoverPeers() error {
+	m.mu.Lock()
+	discover := m.discoverPeers
+	m.mu.Unlock()
+	if discover == nil {
+		return errors.New("DHT discovery is not running")
+	}
+	if m.ctx.Err() != nil {
+		return errors.New("host is closed")
+	}
+	return discover()
+}
+
+// Close stops discovery, cancels the pubsub subscription, stops mDNS, closes
+// the DHT, the service discovery and the address book, removes the host's
+// connection pool and waits for the background goroutines before closing the
+// host itself. It is safe to call more than once.
+func (m *ManagedHost) Close() error {
+	m.closeOnce.Do(func() {
+		var errs []error
+		if m.Host != nil {
+			// Save the address book while the peerstore still has every address
+			if book := GetAddressBook(m.Host); book != nil {
+				book.Close()
+			}
+		}
+		m.mu.Lock()
+		discovery := m.discovery
+		m.discovery = nil
+		m.mu.Unlock()
+		if discovery != nil {
+			discovery.Close()
+		}
+
+		m.cancel()
+		m.mu.Lock()
+		if m.subscription != nil {
+			m.subscription.Cancel()
+		}
+		if m.mdns != nil {
+			if err := m.mdns.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop mDNS discovery: %w", err))
+			}
+		}
+		if m.dht != nil {
+			if err := m.dht.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close DHT: %w", err))
+			}
+		}
+		m.mu.Unlock()
+		m.wg.Wait()
+		_ = m.Events().Close()
+
+		if m.Host != nil {
+			pool.RemovePool(m.Host)
+			if err := m.Host.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		m.closeErr = errors.Join(errs...)
+	})
+	return m.closeErr
+}
+
+// ServiceDiscovery returns the service discovery of the host, or nil if it
+// has no DHT or is closed
+func (m *ManagedHost) ServiceDiscovery() *ServiceDiscovery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.discovery
+}
+
+// Unwrap returns the underlying libp2p host
+func (m *ManagedHost) Unwrap() host.Host {
+	return m.Host
+}
@@ -0,0 +1,110 @@
+package host
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/omgolab/drpc/pkg/core/leakcheck"
+	"github.com/omgolab/drpc/pkg/core/pool"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestManagedHostCloseStopsGoroutines(t *testing.T) {
+	leakcheck.Check(t)
+	logger, _ := glog.New()
+
+	h, err := CreateLibp2pHost(t.Context(),
+		WithHostLogger(logger),
+		WithHostListenAddrs("/ip4/127.0.0.1/tcp/0"),
+		WithHostPrivateNetwork(make([]byte, 32)),
+		WithAddressBook(t.TempDir()+"/peers.json"),
+	)
+	if err != nil {
+		t.Fatalf("CreateLibp2pHost failed: %v", err)
+	}
+	if pool.LookupPool(h) != nil {
+		t.Fatal("Expected no connection pool before one is created")
+	}
+	pool.GetPool(h, logger)
+	if GetServiceDiscovery(h) == nil || GetAddressBook(h) == nil {
+		t.Fatal("Expected the host to have service discovery and an address book")
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+	if GetServiceDiscovery(h) != nil || GetAddressBook(h) != nil {
+		t.Error("Close left the service discovery or address book registered")
+	}
+	if pool.LookupPool(h) != nil {
+		t.Error("Close left the connection pool in the pool manager")
+	}
+}
+
+func TestServiceDiscoveryIsPerHost(t *testing.T) {
+	logger, _ := glog.New()
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newHost := func() *ManagedHost {
+		h, err := CreateLibp2pHost(t.Context(),
+			WithHostLogger(logger),
+			WithHostIdentity(key),
+			WithHostListenAddrs("/ip4/127.0.0.1/tcp/0"),
+			WithHostPrivateNetwork(make([]byte, 32)),
+		)
+		if err != nil {
+			t.Fatalf("CreateLibp2pHost failed: %v", err)
+		}
+		t.Cleanup(func() { h.Close() })
+		return h
+	}
+
+	// Two hosts of one identity in a process must not share discovery state
+	a, b := newHost(), newHost()
+	sd := GetServiceDiscovery(b)
+	if sd == nil || GetServiceDiscovery(a) == sd {
+		t.Fatal("Expected each host to have its own service discovery")
+	}
+	sd.Advertise("greeter.v1.GreeterService")
+	_ = a.Close()
+	if GetServiceDiscovery(b) != sd || len(sd.Services()) != 1 {
+		t.Error("Closing a host with the same identity closed the other's service discovery")
+	}
+}
+
+func TestBanPeerExpires(t *testing.T) {
+	logger, _ := glog.New()
+	h, err := CreateLibp2pHost(t.Context(),
+		WithHostLogger(logger),
+		WithHostListenAddrs("/ip4/127.0.0.1/tcp/0"),
+		WithHostPrivateNetwork(make([]byte, 32)),
+	)
+	if err != nil {
+		t.Fatalf("CreateLibp2pHost failed: %v", err)
+	}
+	defer h.Close()
+
+	if err := h.BanPeer(h.ID(), 0); err == nil {
+		t.Error("Expected banning the host itself to fail")
+	}
+	const other = peer.ID("other")
+	if err := h.BanPeer(other, 50*time.Millisecond); err != nil {
+		t.Fatalf("BanPeer failed: %v", err)
+	}
+	if !h.IsBanned(other) || len(h.BannedPeers()) != 1 {
+		t.Fatal("Expected the peer to be banned")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if h.IsBanned(other) || len(h.BannedPeers()) != 0 || h.UnbanPeer(other) {
+		t.Error("Expected the ban to have expired")
+	}
+}
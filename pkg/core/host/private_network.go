@@ -0,0 +1,76 @@
+package host
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// privateNetworkKey is the peerstore metadata key under which a host in a
+// private network records the network fingerprint for its own peer ID
+const privateNetworkKey = "drpc/private-network"
+
+// ErrPrivateNetwork is wrapped by connection errors of hosts in a private
+// network. A peer that is not a member, or uses another key, cannot be told
+// apart from an unreachable one: the handshake fails or never completes.
+var ErrPrivateNetwork = errors.New("peer is unreachable from the private network, check that it is a member with the same pre-shared key")
+
+// LoadSwarmKey reads a pre-shared key in the swarm.key format used by IPFS
+// private networks (/key/swarm/psk/1.0.0/ followed by a base16 or base64 key)
+func LoadSwarmKey(path string) (pnet.PSK, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open swarm key: %w", err)
+	}
+	defer f.Close()
+	psk, err := pnet.DecodeV1PSK(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid swarm key %s: %w", path, err)
+	}
+	return psk, nil
+}
+
+// networkFingerprint identifies the private network of psk in discovery
+// names without revealing the key
+func networkFingerprint(psk pnet.PSK) string {
+	sum := sha256.Sum256(append([]byte("drpc-pnet/"), psk...))
+	return hex.EncodeToString(sum[:8])
+}
+
+// namespaced returns tag, suffixed with the network fingerprint in a private
+// network so members never discover or gossip with other swarms
+func (c *hostCfg) namespaced(tag string) string {
+	if c.psk == nil {
+		return tag
+	}
+	return tag + "-" + networkFingerprint(c.psk)
+}
+
+// dhtProtocolPrefix returns the DHT protocol prefix of the host's private network
+func (c *hostCfg) dhtProtocolPrefix() protocol.ID {
+	return protocol.ID("/drpc/pnet/" + networkFingerprint(c.psk))
+}
+
+// registerPrivateNetwork records that h is in the private network of psk
+func registerPrivateNetwork(h host.Host, psk pnet.PSK) error {
+	return h.Peerstore().Put(h.ID(), privateNetworkKey, networkFingerprint(psk))
+}
+
+// PrivateNetworkError wraps a failed connection attempt of h with
+// ErrPrivateNetwork if h is in a private network, and returns err otherwise
+func PrivateNetworkError(h host.Host, err error) error {
+	if err == nil {
+		return nil
+	}
+	fingerprint, lookupErr := h.Peerstore().Get(h.ID(), privateNetworkKey)
+	if lookupErr != nil {
+		return err
+	}
+	return fmt.Errorf("%w (network %s): %w", ErrPrivateNetwork, fingerprint, err)
+}
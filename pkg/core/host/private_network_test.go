@@ -0,0 +1,101 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// newPrivateHost creates a host on 127.0.0.1 without public discovery, in the
+// private network of psk if it is set
+func newPrivateHost(t *testing.T, psk pnet.PSK) host.Host {
+	t.Helper()
+	logger, _ := glog.New()
+	opts := []HostOption{
+		WithHostLogger(logger),
+		WithHostListenAddrs("/ip4/127.0.0.1/tcp/0"),
+		WithMDNSDiscovery(true),
+		WithPubsubDiscovery(true),
+	}
+	if psk != nil {
+		opts = append(opts, WithHostPrivateNetwork(psk))
+	}
+	h, err := CreateLibp2pHost(t.Context(), opts...)
+	if err != nil {
+		t.Fatalf("CreateLibp2pHost failed: %v", err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestPrivateNetwork(t *testing.T) {
+	psk := make(pnet.PSK, 32)
+	other := make(pnet.PSK, 32)
+	other[0] = 1
+
+	a, b := newPrivateHost(t, psk), newPrivateHost(t, psk)
+	connect := func(from, to host.Host) error {
+		ctx, cancel := context.WithTimeout(t.Context(), 2*time.Second)
+		defer cancel()
+		return from.Connect(ctx, peer.AddrInfo{ID: to.ID(), Addrs: to.Addrs()})
+	}
+	if err := connect(a, b); err != nil {
+		t.Fatalf("Members failed to connect: %v", err)
+	}
+
+	for name, h := range map[string]host.Host{
+		"other network":  newPrivateHost(t, other),
+		"public network": newPrivateHost(t, nil),
+	} {
+		if err := connect(h, a); err == nil {
+			t.Errorf("A host in the %s connected to a member", name)
+		}
+		err := PrivateNetworkError(a, connect(a, h))
+		if !errors.Is(err, ErrPrivateNetwork) {
+			t.Errorf("Connecting a member to a host in the %s: %v, want ErrPrivateNetwork", name, err)
+		}
+	}
+	if err := PrivateNetworkError(newPrivateHost(t, nil), errors.New("dial failed")); errors.Is(err, ErrPrivateNetwork) {
+		t.Error("Errors of public hosts are wrapped with ErrPrivateNetwork")
+	}
+}
+
+func TestPrivateNetworkNamespaces(t *testing.T) {
+	public := &hostCfg{}
+	a, b := &hostCfg{psk: make(pnet.PSK, 32)}, &hostCfg{psk: make(pnet.PSK, 32)}
+	b.psk[0] = 1
+
+	if public.namespaced("drpc") != "drpc" {
+		t.Errorf("Public namespace = %q, want it unchanged", public.namespaced("drpc"))
+	}
+	if a.namespaced("drpc") == b.namespaced("drpc") || a.dhtProtocolPrefix() == b.dhtProtocolPrefix() {
+		t.Error("Different private networks share discovery namespaces")
+	}
+}
+
+func TestLoadSwarmKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarm.key")
+	key := "/key/swarm/psk/1.0.0/\n/base16/\n" + strings.Repeat("ab", 32) + "\n"
+	if err := os.WriteFile(path, []byte(key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	psk, err := LoadSwarmKey(path)
+	if err != nil {
+		t.Fatalf("LoadSwarmKey failed: %v", err)
+	}
+	if len(psk) != 32 || psk[0] != 0xab {
+		t.Errorf("LoadSwarmKey = %x", psk)
+	}
+	if err := WithHostPrivateNetwork(psk[:16])(&hostCfg{}); err == nil {
+		t.Error("Expected a 16 byte key to be refused")
+	}
+}
@@ -0,0 +1,144 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/discovery"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	drouting "github.com/libp2p/go-libp2p/p2p/discovery/routing"
+	dutil "github.com/libp2p/go-libp2p/p2p/discovery/util"
+	"github.com/omgolab/drpc/pkg/config"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// ServiceNamespace returns the DHT namespace under which providers of a
+// fully-qualified service name (e.g. "greeter.v1.GreeterService") are advertised
+func ServiceNamespace(service string) string {
+	return config.DISCOVERY_SERVICE_TAG_PREFIX + service
+}
+
+// ServiceDiscovery advertises the dRPC services served by a host as DHT
+// provider records and finds the providers of a service.
+type ServiceDiscovery struct {
+	ctx     context.Context
+	self    peer.ID
+	routing *drouting.RoutingDiscovery
+	logger  glog.Logger
+
+	mu         sync.Mutex
+	advertised map[string]context.CancelFunc
+	closed     bool
+}
+
+// GetServiceDiscovery returns the service discovery of a host created by
+// CreateLibp2pHost, or nil if the host has no DHT or is closed
+func GetServiceDiscovery(h host.Host) *ServiceDiscovery {
+	m, ok := h.(*ManagedHost)
+	if !ok {
+		return nil
+	}
+	return m.ServiceDiscovery()
+}
+
+// newServiceDiscovery creates the service discovery of h, closed once ctx is done
+func newServiceDiscovery(ctx context.Context, h host.Host, router *drouting.RoutingDiscovery, logger glog.Logger) *ServiceDiscovery {
+	sd := &ServiceDiscovery{
+		ctx:        ctx,
+		self:       h.ID(),
+		routing:    router,
+		logger:     logger,
+		advertised: make(map[string]context.CancelFunc),
+	}
+	context.AfterFunc(ctx, sd.Close)
+	return sd
+}
+
+// Advertise provides the given services on the DHT and keeps re-providing
+// them before their records expire. Services already advertised are skipped.
+func (sd *ServiceDiscovery) Advertise(services ...string) {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	if sd.closed {
+		return
+	}
+
+	for _, service := range services {
+		if _, ok := sd.advertised[service]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(sd.ctx)
+		sd.advertised[service] = cancel
+		dutil.Advertise(ctx, sd.routing, ServiceNamespace(service), discovery.TTL(config.DHT_SERVICE_PROVIDE_TTL))
+		sd.logger.Info("Advertising service on DHT", glog.LogFields{"service": service})
+	}
+}
+
+// Unadvertise stops re-providing the given services. Records already
+// published stay in the DHT until they expire.
+func (sd *ServiceDiscovery) Unadvertise(services ...string) {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	for _, service := range services {
+		if cancel, ok := sd.advertised[service]; ok {
+			cancel()
+			delete(sd.advertised, service)
+		}
+	}
+}
+
+// Services returns the advertised services, sorted by name
+func (sd *ServiceDiscovery) Services() []string {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	services := make([]string, 0, len(sd.advertised))
+	for service := range sd.advertised {
+		services = append(services, service)
+	}
+	slices.Sort(services)
+	return services
+}
+
+// FindProviders looks up the peers providing service, other than the host
+// itself. Lookups are repeated until at least one provider is found or ctx
+// is done, since the routing table may still be filling up.
+func (sd *ServiceDiscovery) FindProviders(ctx context.Context, service string) ([]peer.AddrInfo, error) {
+	for {
+		providers, err := dutil.FindPeers(ctx, sd.routing, ServiceNamespace(service),
+			discovery.Limit(config.DHT_SERVICE_PROVIDER_LIMIT))
+		if err != nil && ctx.Err() == nil {
+			sd.logger.Debug("DHT provider lookup failed", glog.LogFields{"service": service, "error": err.Error()})
+		}
+		providers = slices.DeleteFunc(providers, func(p peer.AddrInfo) bool { return p.ID == sd.self })
+		if len(providers) > 0 {
+			return providers, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no providers found for service %q: %w", service, ctx.Err())
+		case <-time.After(config.DHT_SERVICE_LOOKUP_INTERVAL):
+		}
+	}
+}
+
+// Close stops advertising every service and unregisters the service discovery
+func (sd *ServiceDiscovery) Close() {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	if sd.closed {
+		return
+	}
+	sd.closed = true
+
+	for service, cancel := range sd.advertised {
+		cancel()
+		delete(sd.advertised, service)
+	}
+}
@@ -0,0 +1,292 @@
+// Package identity loads, generates and derives the private keys that make up
+// a libp2p host's peer ID, so it can stay the same across restarts.
+package identity
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/omgolab/drpc/pkg/config"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeyType names a libp2p key algorithm
+type KeyType string
+
+// Supported key types
+const (
+	Ed25519   KeyType = "ed25519"
+	Secp256k1 KeyType = "secp256k1"
+	RSA       KeyType = "rsa"
+)
+
+// DefaultRSABits is the size of generated RSA keys
+const DefaultRSABits = 2048
+
+// Key file layout and encryption parameters
+const (
+	fileMode = 0o600
+	dirMode  = 0o700
+
+	saltSize = 16
+	scryptN  = 1 << 15
+	scryptR  = 8
+	scryptP  = 1
+)
+
+// encryptedMagic starts passphrase-encrypted key files, followed by the scrypt
+// salt, the AES-GCM nonce and the sealed protobuf key
+var encryptedMagic = []byte("drpc-encrypted-key/1\n")
+
+// ErrPassphraseRequired is returned when loading an encrypted key without a passphrase
+var ErrPassphraseRequired = errors.New("key file is encrypted, a passphrase is required")
+
+// options holds the LoadOrGenerate settings
+type options struct {
+	keyType    KeyType
+	explicit   bool // keyType was set with WithKeyType
+	rsaBits    int
+	passphrase []byte
+}
+
+// Option configures LoadOrGenerate
+type Option func(*options) error
+
+// WithKeyType sets the type of generated keys; loading a key of another type fails.
+// Defaults to Ed25519.
+func WithKeyType(t KeyType) Option {
+	return func(o *options) error {
+		if _, err := t.cryptoType(); err != nil {
+			return err
+		}
+		o.keyType = t
+		o.explicit = true
+		return nil
+	}
+}
+
+// WithRSABits sets the size of generated RSA keys
+func WithRSABits(bits int) Option {
+	return func(o *options) error {
+		if bits < DefaultRSABits {
+			return fmt.Errorf("RSA keys must have at least %d bits", DefaultRSABits)
+		}
+		o.rsaBits = bits
+		return nil
+	}
+}
+
+// WithPassphrase encrypts generated keys with a key derived from passphrase
+// and decrypts stored ones
+func WithPassphrase(passphrase []byte) Option {
+	return func(o *options) error {
+		if len(passphrase) == 0 {
+			return errors.New("passphrase cannot be empty")
+		}
+		o.passphrase = passphrase
+		return nil
+	}
+}
+
+// cryptoType returns the libp2p key type constant of t
+func (t KeyType) cryptoType() (int, error) {
+	switch t {
+	case Ed25519:
+		return crypto.Ed25519, nil
+	case Secp256k1:
+		return crypto.Secp256k1, nil
+	case RSA:
+		return crypto.RSA, nil
+	default:
+		return 0, fmt.Errorf("unsupported key type %q, want ed25519, secp256k1 or rsa", t)
+	}
+}
+
+// LoadOrGenerate loads the private key stored at path in libp2p's protobuf
+// key format. If the file does not exist, a new key is generated and written
+// with 0600 permissions. Unencrypted key files readable by others are refused.
+func LoadOrGenerate(path string, opts ...Option) (crypto.PrivKey, error) {
+	o := options{keyType: Ed25519, rsaBits: DefaultRSABits}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return generate(path, o)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key, err := decode(data, o.passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key file %s: %w", path, err)
+	}
+	if o.passphrase == nil && runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o077 != 0 {
+			return nil, fmt.Errorf("key file %s is accessible by others (mode %04o), want %04o", path, info.Mode().Perm(), fileMode)
+		}
+	}
+	if want, _ := o.keyType.cryptoType(); o.explicit && int(key.Type()) != want {
+		return nil, fmt.Errorf("key file %s holds a %s key, want %s", path, key.Type(), o.keyType)
+	}
+	return key, nil
+}
+
+// generate creates a key as configured by o and stores it at path
+func generate(path string, o options) (crypto.PrivKey, error) {
+	typ, _ := o.keyType.cryptoType()
+	key, _, err := crypto.GenerateKeyPairWithReader(typ, o.rsaBits, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s key: %w", o.keyType, err)
+	}
+	if err := Save(path, key, o.passphrase); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Save writes key to a new file at path with 0600 permissions, encrypted if a
+// passphrase is given. Missing directories are created; an existing file is
+// never overwritten.
+func Save(path string, key crypto.PrivKey, passphrase []byte) error {
+	data, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+	if passphrase != nil {
+		if data, err = encrypt(data, passphrase); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return fmt.Errorf("failed to create key file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
+
+// decode parses a stored key, decrypting it if it is encrypted
+func decode(data, passphrase []byte) (crypto.PrivKey, error) {
+	if bytes.HasPrefix(data, encryptedMagic) {
+		if passphrase == nil {
+			return nil, ErrPassphraseRequired
+		}
+		var err error
+		if data, err = decrypt(data[len(encryptedMagic):], passphrase); err != nil {
+			return nil, err
+		}
+	} else if passphrase != nil {
+		return nil, errors.New("key file is not encrypted but a passphrase was given")
+	}
+	return crypto.UnmarshalPrivateKey(data)
+}
+
+// newAEAD derives the AES-GCM cipher of passphrase and salt
+func newAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt seals plaintext with a key derived from passphrase
+func encrypt(plaintext, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(slices.Concat(encryptedMagic, salt, nonce), nonce, plaintext, encryptedMagic), nil
+}
+
+// decrypt opens the salt, nonce and ciphertext following the magic
+func decrypt(data, passphrase []byte) ([]byte, error) {
+	if len(data) < saltSize {
+		return nil, errors.New("encrypted key file is truncated")
+	}
+	aead, err := newAEAD(passphrase, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted key file is truncated")
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], encryptedMagic)
+	if err != nil {
+		return nil, errors.New("failed to decrypt key file, wrong passphrase?")
+	}
+	return plaintext, nil
+}
+
+// FromSeed derives a key deterministically from seed, so tests get the same
+// peer ID on every run. Only Ed25519 and secp256k1 keys can be derived. Never
+// use it for production identities.
+func FromSeed(seed []byte, keyType KeyType) (crypto.PrivKey, error) {
+	sum := sha256.Sum256(seed)
+	switch keyType {
+	case Ed25519:
+		return crypto.UnmarshalEd25519PrivateKey(ed25519.NewKeyFromSeed(sum[:]))
+	case Secp256k1:
+		return crypto.UnmarshalSecp256k1PrivateKey(sum[:])
+	default:
+		return nil, fmt.Errorf("cannot derive a %q key from a seed, want ed25519 or secp256k1", keyType)
+	}
+}
+
+// FromConfig returns the key described by the identity section of a config
+// file, loading or generating its key file, or nil if none is configured
+func FromConfig(c config.Identity) (crypto.PrivKey, error) {
+	if c.KeyFile == "" {
+		return c.Key()
+	}
+	var opts []Option
+	if c.KeyType != "" {
+		opts = append(opts, WithKeyType(KeyType(c.KeyType)))
+	}
+	if c.Passphrase != "" {
+		opts = append(opts, WithPassphrase([]byte(c.Passphrase)))
+	}
+	return LoadOrGenerate(c.KeyFile, opts...)
+}
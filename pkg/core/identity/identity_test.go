@@ -0,0 +1,126 @@
+package identity
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/crypto/pb"
+	"github.com/omgolab/drpc/pkg/config"
+)
+
+func TestLoadOrGenerate(t *testing.T) {
+	for _, tc := range []struct {
+		keyType KeyType
+		want    pb.KeyType
+	}{
+		{Ed25519, crypto.Ed25519},
+		{Secp256k1, crypto.Secp256k1},
+		{RSA, crypto.RSA},
+	} {
+		t.Run(string(tc.keyType), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys", "node.key")
+			key, err := LoadOrGenerate(path, WithKeyType(tc.keyType))
+			if err != nil {
+				t.Fatalf("Generating failed: %v", err)
+			}
+			if key.Type() != tc.want {
+				t.Errorf("Generated a %s key, want %s", key.Type(), tc.want)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0o600 {
+				t.Errorf("Key file mode = %04o, want 0600", info.Mode().Perm())
+			}
+
+			loaded, err := LoadOrGenerate(path)
+			if err != nil {
+				t.Fatalf("Loading failed: %v", err)
+			}
+			if !loaded.Equals(key) {
+				t.Error("Loaded key differs from the generated one")
+			}
+		})
+	}
+}
+
+func TestLoadOrGenerateErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "node.key")
+	if _, err := LoadOrGenerate(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadOrGenerate(path, WithKeyType(Secp256k1)); err == nil {
+		t.Error("Expected loading an Ed25519 key as secp256k1 to fail")
+	}
+	if _, err := LoadOrGenerate(path, WithPassphrase([]byte("secret"))); err == nil {
+		t.Error("Expected a passphrase for an unencrypted key to fail")
+	}
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrGenerate(path); err == nil {
+		t.Error("Expected a key file readable by others to be refused")
+	}
+	if _, err := LoadOrGenerate(filepath.Join(dir, "bad.key"), WithKeyType("dsa")); err == nil {
+		t.Error("Expected an unsupported key type to fail")
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node.key")
+	key, err := LoadOrGenerate(path, WithPassphrase([]byte("secret")))
+	if err != nil {
+		t.Fatalf("Generating failed: %v", err)
+	}
+
+	if _, err := LoadOrGenerate(path); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+	if _, err := LoadOrGenerate(path, WithPassphrase([]byte("wrong"))); err == nil {
+		t.Error("Expected a wrong passphrase to fail")
+	}
+	loaded, err := LoadOrGenerate(path, WithPassphrase([]byte("secret")))
+	if err != nil {
+		t.Fatalf("Loading failed: %v", err)
+	}
+	if !loaded.Equals(key) {
+		t.Error("Decrypted key differs from the generated one")
+	}
+}
+
+func TestFromSeed(t *testing.T) {
+	for _, keyType := range []KeyType{Ed25519, Secp256k1} {
+		a, err := FromSeed([]byte("node-1"), keyType)
+		if err != nil {
+			t.Fatalf("FromSeed(%s) failed: %v", keyType, err)
+		}
+		b, _ := FromSeed([]byte("node-1"), keyType)
+		c, _ := FromSeed([]byte("node-2"), keyType)
+		if !a.Equals(b) || a.Equals(c) {
+			t.Errorf("%s keys are not determined by their seed", keyType)
+		}
+	}
+	if _, err := FromSeed([]byte("node-1"), RSA); err == nil {
+		t.Error("Expected RSA keys to be refused")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node.key")
+	key, err := FromConfig(config.Identity{KeyFile: path, KeyType: "secp256k1", Passphrase: "secret"})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	if key.Type() != crypto.Secp256k1 {
+		t.Errorf("Generated a %s key, want secp256k1", key.Type())
+	}
+	if key, err := FromConfig(config.Identity{}); key != nil || err != nil {
+		t.Errorf("FromConfig of an empty section = %v, %v; want no key", key, err)
+	}
+}
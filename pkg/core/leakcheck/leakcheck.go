@@ -0,0 +1,31 @@
+// Package leakcheck verifies that tests stop the goroutines they start, such
+// as those of libp2p hosts, dRPC clients and servers.
+package leakcheck
+
+import (
+	"testing"
+
+	"go.uber.org/goleak"
+)
+
+// bounded are libp2p goroutines that may outlive a closed host but stop on
+// their own: UPnP and NAT-PMP gateway discovery and DNS lookups of dialed
+// addresses wait for their timeouts
+var bounded = []goleak.Option{
+	goleak.IgnoreAnyFunction("github.com/libp2p/go-libp2p/p2p/net/swarm.(*Swarm).resolveAddrs.func1"),
+	goleak.IgnoreAnyFunction("github.com/libp2p/go-libp2p/p2p/net/nat/internal/nat.discoverNATs.func1"),
+	goleak.IgnoreAnyFunction("github.com/libp2p/go-libp2p/p2p/net/nat/internal/nat.discoverNATPMP.func1"),
+}
+
+// Check fails tb if goroutines started from here on are still running once
+// the test and its cleanups registered after Check are done. Call it first
+// in the test so the hosts closed by later cleanups are closed by then.
+func Check(tb testing.TB, opts ...goleak.Option) {
+	tb.Helper()
+	opts = append(append([]goleak.Option{goleak.IgnoreCurrent()}, bounded...), opts...)
+	tb.Cleanup(func() {
+		if err := goleak.Find(opts...); err != nil {
+			tb.Error(err)
+		}
+	})
+}
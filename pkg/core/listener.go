@@ -2,50 +2,240 @@ package core
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 	mn "github.com/multiformats/go-multiaddr/net"
+	"github.com/omgolab/drpc/pkg/config"
 )
 
-var _ net.Listener = (*listener)(nil)
+// DefaultAcceptQueueSize is the number of streams waiting for Accept before
+// new ones overflow, unless set with WithAcceptQueue
+const DefaultAcceptQueueSize = 64
+
+var _ Libp2pListener = (*listener)(nil)
+
+// Libp2pListener is the net.Listener returned by NewLibp2pListener
+type Libp2pListener interface {
+	net.Listener
+	// Stats returns the accept queue statistics
+	Stats() AcceptStats
+}
+
+// OverflowPolicy decides what happens to streams the accept queue has no room for
+type OverflowPolicy int
+
+const (
+	// OverflowReset resets the stream
+	OverflowReset OverflowPolicy = iota
+	// OverflowBusy resets the stream with the StreamRateLimited error code,
+	// telling the remote peer the server is busy and the call can be retried
+	OverflowBusy
+)
+
+// String returns the config file name of p
+func (p OverflowPolicy) String() string {
+	if p == OverflowBusy {
+		return "busy"
+	}
+	return "reset"
+}
+
+// AcceptQueue configures the streams waiting for Accept
+type AcceptQueue struct {
+	Size              int            // streams waiting across all peers, DefaultAcceptQueueSize if 0
+	MaxPendingPerPeer int            // streams waiting per remote peer, unlimited if 0
+	Overflow          OverflowPolicy // applies to streams over either limit
+}
+
+// AcceptStats are the statistics of a listener's accept queue
+type AcceptStats struct {
+	Accepted   int64
+	Overflowed int64 // refused because the queue was full
+	PeerCapped int64 // refused because their peer had MaxPendingPerPeer streams waiting
+	Pending    int   // streams waiting for Accept
+	// Latency is the total time accepted streams waited in the queue
+	Latency    time.Duration
+	MaxLatency time.Duration
+}
+
+// MeanLatency returns the mean time accepted streams waited in the queue
+func (s AcceptStats) MeanLatency() time.Duration {
+	if s.Accepted == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Accepted)
+}
+
+// pendingStream is a stream waiting for Accept
+type pendingStream struct {
+	stream  network.Stream
+	arrived time.Time
+}
 
 type listener struct {
-	h        host.Host
-	streamCh chan network.Stream
-	ctx      context.Context
-	cancel   context.CancelFunc
+	h         host.Host
+	protocols []protocol.ID
+	queue     AcceptQueue
+	streamCh  chan pendingStream
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	mu      sync.Mutex
+	closed  bool
+	pending map[peer.ID]int // streams waiting per peer
+
+	accepted   atomic.Int64
+	overflowed atomic.Int64
+	peerCapped atomic.Int64
+	latency    atomic.Int64 // nanoseconds
+	maxLatency atomic.Int64 // nanoseconds
+}
+
+// ListenerOption configures a libp2p listener
+type ListenerOption func(*listenerCfg)
+
+type listenerCfg struct {
+	extraProtocols []protocol.ID
+	queue          AcceptQueue
+}
+
+// WithExtraProtocols accepts streams on additional protocol IDs, e.g. older
+// wire versions served side by side with the primary one
+func WithExtraProtocols(pids ...protocol.ID) ListenerOption {
+	return func(cfg *listenerCfg) {
+		cfg.extraProtocols = append(cfg.extraProtocols, pids...)
+	}
+}
+
+// WithAcceptQueue sets the size of the accept queue, the per-peer cap on
+// waiting streams and what happens to streams over them
+func WithAcceptQueue(queue AcceptQueue) ListenerOption {
+	return func(cfg *listenerCfg) {
+		cfg.queue = queue
+	}
 }
 
-// NewLibp2pListener bridges a libp2p network.Stream to a net.Conn
+// NewLibp2pListener bridges a libp2p network.Stream to a net.Conn. Incoming
+// streams wait in a bounded accept queue; streams it has no room for are
+// refused right away instead of blocking the stream handler. Close removes
+// the stream handlers and resets the streams still waiting.
 func NewLibp2pListener(
 	h host.Host,
 	pid protocol.ID,
-) net.Listener {
-	l := listener{
-		h:        h,
-		streamCh: make(chan network.Stream, 1), // Use a buffered channel (size 1)
+	opts ...ListenerOption,
+) Libp2pListener {
+	var cfg listenerCfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.queue.Size <= 0 {
+		cfg.queue.Size = DefaultAcceptQueueSize
+	}
+
+	l := &listener{
+		h:         h,
+		protocols: append([]protocol.ID{pid}, cfg.extraProtocols...),
+		queue:     cfg.queue,
+		streamCh:  make(chan pendingStream, cfg.queue.Size),
+		pending:   make(map[peer.ID]int),
 	}
 	// Use context.Background() so the listener's lifecycle isn't tied to the setup context.
 	// It will only close when l.Close() is called.
 	l.ctx, l.cancel = context.WithCancel(context.Background())
 
-	h.SetStreamHandler(pid, func(s network.Stream) {
-		l.streamCh <- s
-	})
+	for _, id := range l.protocols {
+		h.SetStreamHandler(id, l.handleStream)
+	}
+
+	return l
+}
+
+// handleStream queues s for Accept, or refuses it if the queue or the
+// remote peer's share of it is full
+func (l *listener) handleStream(s network.Stream) {
+	if err := attachStream(s, config.DRPC_RESOURCE_SERVICE, 0); err != nil {
+		rejectStream(s)
+		return
+	}
+
+	remote := s.Conn().RemotePeer()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		_ = s.Reset()
+		return
+	}
+	if l.queue.MaxPendingPerPeer > 0 && l.pending[remote] >= l.queue.MaxPendingPerPeer {
+		l.peerCapped.Add(1)
+		l.overflow(s)
+		return
+	}
+	select {
+	case l.streamCh <- pendingStream{stream: s, arrived: time.Now()}:
+		l.pending[remote]++
+	default:
+		l.overflowed.Add(1)
+		l.overflow(s)
+	}
+}
 
-	return &l
+// overflow refuses a stream the queue has no room for
+func (l *listener) overflow(s network.Stream) {
+	if l.queue.Overflow == OverflowBusy {
+		_ = s.ResetWithError(network.StreamRateLimited)
+		return
+	}
+	_ = s.Reset()
 }
 
 func (l *listener) Accept() (net.Conn, error) {
 	select {
 	case <-l.ctx.Done():
 		return nil, io.EOF
-	case s := <-l.streamCh:
-		return &Conn{Stream: s}, nil // Use the Conn struct from conn.go
+	case p := <-l.streamCh:
+		l.dequeued(p)
+		return &Conn{Stream: p.stream}, nil // Use the Conn struct from conn.go
+	}
+}
+
+// dequeued releases the queue slot of p and records its wait
+func (l *listener) dequeued(p pendingStream) {
+	l.mu.Lock()
+	remote := p.stream.Conn().RemotePeer()
+	if l.pending[remote]--; l.pending[remote] <= 0 {
+		delete(l.pending, remote)
+	}
+	l.mu.Unlock()
+
+	wait := int64(time.Since(p.arrived))
+	l.accepted.Add(1)
+	l.latency.Add(wait)
+	for {
+		prev := l.maxLatency.Load()
+		if wait <= prev || l.maxLatency.CompareAndSwap(prev, wait) {
+			break
+		}
+	}
+}
+
+// Stats returns the accept queue statistics
+func (l *listener) Stats() AcceptStats {
+	return AcceptStats{
+		Accepted:   l.accepted.Load(),
+		Overflowed: l.overflowed.Load(),
+		PeerCapped: l.peerCapped.Load(),
+		Pending:    len(l.streamCh),
+		Latency:    time.Duration(l.latency.Load()),
+		MaxLatency: time.Duration(l.maxLatency.Load()),
 	}
 }
 
@@ -63,7 +253,43 @@ func (l *listener) Addr() net.Addr {
 	return defaultLocalFallbackAddr()
 }
 
+// Close removes the stream handlers, so later streams fail protocol
+// negotiation instead of hanging, and resets the streams still waiting
 func (l *listener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	for _, id := range l.protocols {
+		l.h.RemoveStreamHandler(id)
+	}
 	l.cancel()
-	return nil
+	for {
+		select {
+		case p := <-l.streamCh:
+			_ = p.stream.Reset()
+		default:
+			return nil
+		}
+	}
+}
+
+// IsBusyError reports whether err comes from a stream the remote listener
+// refused with OverflowBusy because its accept queue was full
+func IsBusyError(err error) bool {
+	var streamErr *network.StreamError
+	return errors.As(err, &streamErr) && streamErr.ErrorCode == network.StreamRateLimited
+}
+
+// AcceptQueueFromFile converts the accept queue of a config file
+func AcceptQueueFromFile(q config.AcceptQueue) AcceptQueue {
+	queue := AcceptQueue{Size: q.Size, MaxPendingPerPeer: q.MaxPendingPerPeer}
+	if q.Overflow == OverflowBusy.String() {
+		queue.Overflow = OverflowBusy
+	}
+	return queue
 }
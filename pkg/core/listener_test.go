@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -201,3 +203,99 @@ func TestListenerMultipleStreams(t *testing.T) {
 
 	assert.Len(t, connections, numStreams)
 }
+
+// newTCPHost creates a host listening on loopback TCP only
+func newTCPHost(t *testing.T) host.Host {
+	t.Helper()
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	require.NoError(t, err)
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+// openStream opens a stream from h to target and writes a byte, so the
+// stream reaches the listener's handler
+func openStream(t *testing.T, h, target host.Host, pid protocol.ID) network.Stream {
+	t.Helper()
+	h.Peerstore().AddAddrs(target.ID(), target.Addrs(), time.Hour)
+	s, err := h.NewStream(context.Background(), target.ID(), pid)
+	require.NoError(t, err)
+	_, err = s.Write([]byte{0})
+	require.NoError(t, err)
+	return s
+}
+
+func TestListenerAcceptQueueOverflow(t *testing.T) {
+	h1, h2 := newTCPHost(t), newTCPHost(t)
+	pid := protocol.ID("/test/listener/1.0.0")
+	l := NewLibp2pListener(h1, pid, WithAcceptQueue(AcceptQueue{Size: 1, Overflow: OverflowBusy}))
+	defer l.Close()
+
+	queued := openStream(t, h2, h1, pid)
+	defer queued.Close()
+	require.Eventually(t, func() bool { return l.Stats().Pending == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// The queue is full: the next stream is refused with a busy signal instead of blocking
+	refused := openStream(t, h2, h1, pid)
+	_, err := refused.Read(make([]byte, 1))
+	assert.True(t, IsBusyError(err), "expected a busy error, got %v", err)
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	stats := l.Stats()
+	assert.Equal(t, int64(1), stats.Accepted)
+	assert.Equal(t, int64(1), stats.Overflowed)
+	assert.Equal(t, 0, stats.Pending)
+	assert.Positive(t, stats.MaxLatency)
+	assert.Equal(t, stats.Latency, stats.MeanLatency())
+}
+
+func TestListenerMaxPendingPerPeer(t *testing.T) {
+	h1, h2, h3 := newTCPHost(t), newTCPHost(t), newTCPHost(t)
+	pid := protocol.ID("/test/listener/1.0.0")
+	l := NewLibp2pListener(h1, pid, WithAcceptQueue(AcceptQueue{MaxPendingPerPeer: 1}))
+	defer l.Close()
+
+	first := openStream(t, h2, h1, pid)
+	defer first.Close()
+	require.Eventually(t, func() bool { return l.Stats().Pending == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// A second stream of the same peer is reset, another peer still gets in
+	capped := openStream(t, h2, h1, pid)
+	_, err := capped.Read(make([]byte, 1))
+	assert.Error(t, err)
+	assert.False(t, IsBusyError(err), "OverflowReset must not signal busy")
+	other := openStream(t, h3, h1, pid)
+	defer other.Close()
+	require.Eventually(t, func() bool { return l.Stats().Pending == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), l.Stats().PeerCapped)
+
+	// Accepting the first stream frees the peer's slot
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	again := openStream(t, h2, h1, pid)
+	defer again.Close()
+	require.Eventually(t, func() bool { return l.Stats().Pending == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestListenerCloseRemovesHandlers(t *testing.T) {
+	h1, h2 := newTCPHost(t), newTCPHost(t)
+	pid := protocol.ID("/test/listener/1.0.0")
+	extra := protocol.ID("/test/listener/0.9.0")
+	l := NewLibp2pListener(h1, pid, WithExtraProtocols(extra))
+
+	waiting := openStream(t, h2, h1, pid)
+	require.Eventually(t, func() bool { return l.Stats().Pending == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, l.Close())
+	assert.NotContains(t, h1.Mux().Protocols(), pid)
+	assert.NotContains(t, h1.Mux().Protocols(), extra)
+
+	// The stream still waiting is reset rather than left hanging
+	_, err := waiting.Read(make([]byte, 1))
+	assert.Error(t, err)
+	_, err = l.Accept()
+	assert.Equal(t, io.EOF, err)
+}
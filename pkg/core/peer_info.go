@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+// EntryPath identifies the transport path that carried an RPC to the handler
+type EntryPath string
+
+const (
+	// EntryLibp2p is a direct libp2p stream on the dRPC protocol
+	EntryLibp2p EntryPath = "libp2p"
+	// EntryWebStream is a libp2p stream on the web stream envelope protocol
+	EntryWebStream EntryPath = "webstream"
+	// EntryHTTP is a plain HTTP request to the local HTTP listener
+	EntryHTTP EntryPath = "http"
+	// EntryGateway is a request forwarded onto libp2p by an HTTP gateway. It
+	// is declared by the calling peer with GatewayForwardedHeader, so unlike
+	// the other paths it is advisory: any peer may claim it.
+	EntryGateway EntryPath = "gateway"
+)
+
+// GatewayForwardedHeader is set by the gateway on requests it forwards to a peer
+const GatewayForwardedHeader = "Drpc-Gateway-Forwarded"
+
+// PeerInfo describes the caller of an RPC
+type PeerInfo struct {
+	// ID is the authenticated remote peer. It is empty for EntryHTTP; for
+	// EntryGateway it is the gateway node that forwarded the call.
+	ID peer.ID
+	// Addr is the remote multiaddr of the underlying connection
+	Addr multiaddr.Multiaddr
+	// Relayed reports whether the connection goes through a circuit relay
+	Relayed bool
+	// Limited reports whether the connection is resource limited (e.g. a relayed connection with a reservation)
+	Limited bool
+	// Entry is the path that carried the call. It is meant for telemetry;
+	// access decisions should rest on ID, since EntryGateway is advisory.
+	Entry EntryPath
+	// Protocol is the negotiated libp2p protocol, including its wire version; empty for EntryHTTP
+	Protocol protocol.ID
+}
+
+type peerInfoKey struct{}
+
+// ContextWithPeerInfo returns a copy of ctx carrying info
+func ContextWithPeerInfo(ctx context.Context, info PeerInfo) context.Context {
+	return context.WithValue(ctx, peerInfoKey{}, info)
+}
+
+// PeerInfoFromContext returns the caller information attached to ctx, if any
+func PeerInfoFromContext(ctx context.Context) (PeerInfo, bool) {
+	info, ok := ctx.Value(peerInfoKey{}).(PeerInfo)
+	return info, ok
+}
+
+// PeerInfoFromStream builds the caller information for a libp2p stream
+func PeerInfoFromStream(stream network.Stream, entry EntryPath) PeerInfo {
+	conn := stream.Conn()
+	addr := conn.RemoteMultiaddr()
+	return PeerInfo{
+		ID:       conn.RemotePeer(),
+		Addr:     addr,
+		Relayed:  isRelayAddr(addr),
+		Limited:  conn.Stat().Limited,
+		Entry:    entry,
+		Protocol: stream.Protocol(),
+	}
+}
+
+// isRelayAddr reports whether addr is a circuit relay address
+func isRelayAddr(addr multiaddr.Multiaddr) bool {
+	if addr == nil {
+		return false
+	}
+	_, err := addr.ValueForProtocol(multiaddr.P_CIRCUIT)
+	return err == nil
+}
+
+// ConnContext attaches the caller information of libp2p backed connections.
+// It is meant to be used as http.Server.ConnContext for servers running on a Listener.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	if conn, ok := c.(*Conn); ok {
+		return ContextWithPeerInfo(ctx, PeerInfoFromStream(conn.Stream, EntryLibp2p))
+	}
+	return ctx
+}
+
+// PeerInfoHandler makes caller information available to every request served by next.
+// Requests without libp2p information are treated as local HTTP calls, and libp2p
+// requests carrying GatewayForwardedHeader are marked as gateway-forwarded. The
+// header is not authenticated: the caller stays identified by its peer ID.
+func PeerInfoHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := PeerInfoFromContext(r.Context())
+		switch {
+		case !ok:
+			info = PeerInfo{Entry: EntryHTTP}
+			if tcpAddr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+				info.Addr, _ = manet.FromNetAddr(tcpAddr)
+			}
+		case info.Entry == EntryLibp2p && r.Header.Get(GatewayForwardedHeader) != "":
+			info.Entry = EntryGateway
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithPeerInfo(r.Context(), info)))
+	})
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// TestConnContextAttachesPeerInfo verifies that libp2p backed connections carry the remote peer
+func TestConnContextAttachesPeerInfo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mnet := mocknet.New()
+	defer mnet.Close()
+
+	peerA, err := mnet.GenPeer()
+	if err != nil {
+		t.Fatalf("Failed to generate peerA: %v", err)
+	}
+	peerB, err := mnet.GenPeer()
+	if err != nil {
+		t.Fatalf("Failed to generate peerB: %v", err)
+	}
+	if err := mnet.LinkAll(); err != nil {
+		t.Fatalf("Failed to link all peers: %v", err)
+	}
+
+	infoCh := make(chan PeerInfo, 1)
+	peerB.SetStreamHandler(protocol.ID("/drpc/1.0.0"), func(s network.Stream) {
+		defer s.Close()
+		info, _ := PeerInfoFromContext(ConnContext(context.Background(), &Conn{Stream: s}))
+		infoCh <- info
+	})
+
+	stream, err := peerA.NewStream(ctx, peerB.ID(), protocol.ID("/drpc/1.0.0"))
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+	defer stream.Close()
+	if _, err := stream.Write([]byte{0}); err != nil {
+		t.Fatalf("Failed to write to stream: %v", err)
+	}
+
+	select {
+	case info := <-infoCh:
+		if info.ID != peerA.ID() {
+			t.Errorf("Expected peer %s, got %s", peerA.ID(), info.ID)
+		}
+		if info.Entry != EntryLibp2p {
+			t.Errorf("Expected entry %q, got %q", EntryLibp2p, info.Entry)
+		}
+		if info.Addr == nil || info.Relayed {
+			t.Errorf("Unexpected address info: addr=%v relayed=%v", info.Addr, info.Relayed)
+		}
+	case <-ctx.Done():
+		t.Fatal("Stream handler was not called")
+	}
+
+	if _, ok := PeerInfoFromContext(ConnContext(context.Background(), nil)); ok {
+		t.Error("Non-libp2p connections should not carry peer info")
+	}
+}
+
+func TestPeerInfoHandler(t *testing.T) {
+	remote, err := peer.Decode("12D3KooWGRUVh7bQNjk3mKAmNoNRfwAk5SFLQdBmpfFZwAxyeLvR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	relayAddr := multiaddr.StringCast("/ip4/1.2.3.4/tcp/4001/p2p/12D3KooWGRUVh7bQNjk3mKAmNoNRfwAk5SFLQdBmpfFZwAxyeLvR/p2p-circuit")
+
+	tests := []struct {
+		name      string
+		ctxInfo   *PeerInfo
+		forwarded bool
+		want      EntryPath
+		wantID    peer.ID
+	}{
+		{name: "local_http", want: EntryHTTP},
+		{name: "local_http_spoofed_gateway_header", forwarded: true, want: EntryHTTP},
+		{name: "libp2p", ctxInfo: &PeerInfo{ID: remote, Entry: EntryLibp2p}, want: EntryLibp2p, wantID: remote},
+		{name: "gateway", ctxInfo: &PeerInfo{ID: remote, Entry: EntryLibp2p}, forwarded: true, want: EntryGateway, wantID: remote},
+		{name: "webstream", ctxInfo: &PeerInfo{ID: remote, Entry: EntryWebStream}, forwarded: true, want: EntryWebStream, wantID: remote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PeerInfo
+			var ok bool
+			handler := PeerInfoHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, ok = PeerInfoFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/svc/Method", nil)
+			if tt.ctxInfo != nil {
+				req = req.WithContext(ContextWithPeerInfo(req.Context(), *tt.ctxInfo))
+			}
+			if tt.forwarded {
+				req.Header.Set(GatewayForwardedHeader, "1")
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !ok {
+				t.Fatal("Handler did not receive peer info")
+			}
+			if got.Entry != tt.want {
+				t.Errorf("Expected entry %q, got %q", tt.want, got.Entry)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("Expected peer %q, got %q", tt.wantID, got.ID)
+			}
+			if tt.ctxInfo == nil && got.Addr == nil {
+				t.Error("Expected remote address for local HTTP call")
+			}
+		})
+	}
+
+	if !isRelayAddr(relayAddr) {
+		t.Error("Expected circuit address to be reported as relayed")
+	}
+}
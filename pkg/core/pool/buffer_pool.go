@@ -17,14 +17,10 @@ type BufferPool struct {
 
 // NewBufferPool creates a new buffer pool with the specified buffer size
 func NewBufferPool(size int) *BufferPool {
+	// No New func: an empty pool returns nil, which Get counts as a miss
 	bp := &BufferPool{
 		size: size,
-		pool: &sync.Pool{
-			New: func() any {
-				buf := make([]byte, size)
-				return &buf
-			},
-		},
+		pool: &sync.Pool{},
 	}
 	return bp
 }
@@ -34,9 +30,9 @@ func (bp *BufferPool) Get() *[]byte {
 	atomic.AddInt64(&bp.gets, 1)
 
 	if buf, ok := bp.pool.Get().(*[]byte); ok {
-		atomic.AddInt64(&bp.hits, 1)
 		// Ensure buffer has the correct capacity and length for CopyBuffer
 		if cap(*buf) >= bp.size {
+			atomic.AddInt64(&bp.hits, 1)
 			*buf = (*buf)[:bp.size]
 			return buf
 		}
@@ -60,15 +56,27 @@ func (bp *BufferPool) Put(buf *[]byte) {
 	bp.pool.Put(buf)
 }
 
+// Size returns the length of the buffers handed out by the pool
+func (bp *BufferPool) Size() int {
+	return bp.size
+}
+
 // Stats returns pool usage statistics
 func (bp *BufferPool) Stats() PoolStats {
+	gets := atomic.LoadInt64(&bp.gets)
+	hits := atomic.LoadInt64(&bp.hits)
+	hitRatio := float64(0)
+	if gets > 0 {
+		hitRatio = float64(hits) / float64(gets)
+	}
+
 	return PoolStats{
-		Gets:     atomic.LoadInt64(&bp.gets),
+		Gets:     gets,
 		Puts:     atomic.LoadInt64(&bp.puts),
-		Hits:     atomic.LoadInt64(&bp.hits),
+		Hits:     hits,
 		Misses:   atomic.LoadInt64(&bp.misses),
 		Size:     bp.size,
-		HitRatio: float64(atomic.LoadInt64(&bp.hits)) / float64(atomic.LoadInt64(&bp.gets)),
+		HitRatio: hitRatio,
 	}
 }
 
@@ -107,26 +115,25 @@ var (
 
 // ProtobufMessagePool provides pooling for protobuf messages
 type ProtobufMessagePool struct {
-	pool *sync.Pool
-	hits int64
-	gets int64
+	pool   *sync.Pool
+	gets   int64
+	misses int64 // messages created by newFunc because the pool was empty
 }
 
 // NewProtobufMessagePool creates a new protobuf message pool
 func NewProtobufMessagePool(newFunc func() any) *ProtobufMessagePool {
-	return &ProtobufMessagePool{
-		pool: &sync.Pool{New: newFunc},
-	}
+	pmp := &ProtobufMessagePool{}
+	pmp.pool = &sync.Pool{New: func() any {
+		atomic.AddInt64(&pmp.misses, 1)
+		return newFunc()
+	}}
+	return pmp
 }
 
 // Get retrieves a protobuf message from the pool
 func (pmp *ProtobufMessagePool) Get() any {
 	atomic.AddInt64(&pmp.gets, 1)
-	msg := pmp.pool.Get()
-	if msg != nil {
-		atomic.AddInt64(&pmp.hits, 1)
-	}
-	return msg
+	return pmp.pool.Get()
 }
 
 // Put returns a protobuf message to the pool
@@ -139,7 +146,7 @@ func (pmp *ProtobufMessagePool) Put(msg any) {
 // Stats returns protobuf pool statistics
 func (pmp *ProtobufMessagePool) Stats() ProtobufPoolStats {
 	gets := atomic.LoadInt64(&pmp.gets)
-	hits := atomic.LoadInt64(&pmp.hits)
+	hits := gets - atomic.LoadInt64(&pmp.misses)
 	hitRatio := float64(0)
 	if gets > 0 {
 		hitRatio = float64(hits) / float64(gets)
@@ -0,0 +1,40 @@
+package pool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// connectStats counts the calls to ConnectToFirstAvailablePeer
+var connectStats connectCounters
+
+type connectCounters struct {
+	attempts   atomic.Int64
+	failures   atomic.Int64
+	durationNs atomic.Int64
+}
+
+func (c *connectCounters) record(d time.Duration, err error) {
+	c.attempts.Add(1)
+	if err != nil {
+		c.failures.Add(1)
+	}
+	c.durationNs.Add(int64(d))
+}
+
+// ConnectStats represents ConnectToFirstAvailablePeer statistics
+type ConnectStats struct {
+	Attempts int64
+	Failures int64
+	// Duration is the total time spent in all attempts
+	Duration time.Duration
+}
+
+// GetConnectStats returns the process-wide ConnectToFirstAvailablePeer statistics
+func GetConnectStats() ConnectStats {
+	return ConnectStats{
+		Attempts: connectStats.attempts.Load(),
+		Failures: connectStats.failures.Load(),
+		Duration: time.Duration(connectStats.durationNs.Load()),
+	}
+}
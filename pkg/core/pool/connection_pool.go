@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"hash/fnv"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,7 +15,10 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core/tracing"
 	glog "github.com/omgolab/go-commons/pkg/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Object pools for connection-related structures
@@ -26,14 +30,11 @@ var (
 			}
 		},
 	}
-
-	managedStreamPool = sync.Pool{
-		New: func() any {
-			return &ManagedStream{}
-		},
-	}
 )
 
+// errStreamReleased is returned by I/O on a ManagedStream after Close or Reset
+var errStreamReleased = errors.New("stream was released to the connection pool")
+
 // Connection pool sharding constants
 const (
 	// Number of shards to distribute connections across
@@ -44,9 +45,9 @@ const (
 
 // peerConnection holds streams for a specific peer with atomic optimizations
 type peerConnection struct {
-	streams         []network.Stream // Using a slice as a stack for O(1) operations
-	lastAccessedNs  int64           // Unix nanoseconds - atomic access
-	mu              sync.Mutex
+	streams        []network.Stream // Using a slice as a stack for O(1) operations
+	lastAccessedNs int64            // Unix nanoseconds - atomic access
+	mu             sync.Mutex
 }
 
 // getLastAccessed returns the last access time using atomic operation
@@ -66,59 +67,48 @@ type ManagedStream struct {
 	network.Stream
 	pool   *ConnectionPool
 	peerID peer.ID
-	closed bool
-	mu     sync.Mutex
+	closed atomic.Bool
+}
+
+// newManagedStream wraps a stream handed out by p
+func newManagedStream(p *ConnectionPool, peerID peer.ID, stream network.Stream) *ManagedStream {
+	return &ManagedStream{Stream: stream, pool: p, peerID: peerID}
+}
+
+// Read fails once the stream was released, as it may serve another caller by now
+func (ms *ManagedStream) Read(b []byte) (int, error) {
+	if ms.closed.Load() {
+		return 0, errStreamReleased
+	}
+	return ms.Stream.Read(b)
+}
+
+// Write fails once the stream was released, as it may serve another caller by now
+func (ms *ManagedStream) Write(b []byte) (int, error) {
+	if ms.closed.Load() {
+		return 0, errStreamReleased
+	}
+	return ms.Stream.Write(b)
 }
 
 // Close overrides the Close method to return the stream to the pool instead
-// of actually closing it. This method is optimized to minimize lock contention.
+// of actually closing it.
 func (ms *ManagedStream) Close() error {
-	ms.mu.Lock()
-	if ms.closed {
-		ms.mu.Unlock()
+	if !ms.closed.CompareAndSwap(false, true) {
 		return nil
 	}
-	ms.closed = true
-	ms.mu.Unlock()
-
-	// Call ReleaseStream outside the lock to reduce lock contention
+	ms.pool.getShard(ms.peerID).active.Add(-1)
 	ms.pool.releaseStream(ms.peerID, ms.Stream)
-	
-	// Return the ManagedStream object to the pool for reuse
-	ms.returnToPool()
-	
 	return nil
 }
 
-// Reset ensures we properly handle the Reset call with optimized locking
+// Reset resets the underlying stream, which is not returned to the pool
 func (ms *ManagedStream) Reset() error {
-	ms.mu.Lock()
-	if ms.closed {
-		ms.mu.Unlock()
+	if !ms.closed.CompareAndSwap(false, true) {
 		return nil
 	}
-	ms.closed = true
-	ms.mu.Unlock()
-
-	// Call Stream.Reset() outside the lock
-	err := ms.Stream.Reset()
-
-	// Return the ManagedStream object to the pool for reuse
-	ms.returnToPool()
-
-	return err
-}
-
-// returnToPool cleans up and returns the ManagedStream to the object pool
-func (ms *ManagedStream) returnToPool() {
-	// Clear references to prevent memory leaks
-	ms.Stream = nil
-	ms.pool = nil
-	ms.peerID = ""
-	ms.closed = false
-
-	// Return to pool for reuse
-	managedStreamPool.Put(ms)
+	ms.pool.getShard(ms.peerID).active.Add(-1)
+	return ms.Stream.Reset()
 }
 
 // ConnectionPool manages stream reuse with sharding for reduced lock contention
@@ -128,12 +118,17 @@ type ConnectionPool struct {
 	maxIdleTime time.Duration
 	maxStreams  int
 	logger      glog.Logger
+	reused      atomic.Int64  // streams served from the pool
+	created     atomic.Int64  // streams opened because the pool was empty
+	done        chan struct{} // closed by Close to stop the cleanup goroutine
+	closeOnce   sync.Once
 }
 
 // connectionShard represents a single shard of connections to reduce lock contention
 type connectionShard struct {
 	connections map[peer.ID]*peerConnection
 	mu          sync.RWMutex
+	active      atomic.Int64 // streams handed out and not yet closed
 }
 
 // hashPeerID returns a hash of the peer ID for sharding
@@ -154,6 +149,7 @@ func NewConnectionPool(p2pHost host.Host, maxIdleTime time.Duration, maxStreams
 		maxIdleTime: maxIdleTime,
 		maxStreams:  maxStreams,
 		logger:      logger,
+		done:        make(chan struct{}),
 	}
 
 	// Initialize shards
@@ -169,7 +165,14 @@ func NewConnectionPool(p2pHost host.Host, maxIdleTime time.Duration, maxStreams
 	return pool
 }
 
-func (p *ConnectionPool) GetStream(ctx context.Context, peerID peer.ID, protocolID protocol.ID) (network.Stream, error) {
+// GetStream returns a pooled or new stream to peerID. When several protocol IDs
+// are given, a new stream negotiates the first one the peer supports.
+func (p *ConnectionPool) GetStream(ctx context.Context, peerID peer.ID, protocolIDs ...protocol.ID) (_ network.Stream, err error) {
+	ctx, span := tracing.Start(ctx, "drpc.pool.get_stream", trace.WithAttributes(
+		attribute.String("drpc.peer.id", peerID.String()),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	shard := p.getShard(peerID)
 
 	// Fast path: try to get an existing connection with read lock first
@@ -191,11 +194,19 @@ func (p *ConnectionPool) GetStream(ctx context.Context, peerID peer.ID, protocol
 	}
 
 	// Get a stream from the peer connection
-	stream, freshlyCreated, err := p.getStreamFromPeerConn(ctx, peerConn, peerID, protocolID)
+	stream, freshlyCreated, err := p.getStreamFromPeerConn(ctx, peerConn, peerID, protocolIDs)
 	if err != nil {
 		return nil, err
 	}
 
+	shard.active.Add(1)
+	span.SetAttributes(attribute.Bool("drpc.pool.reused", !freshlyCreated))
+	if freshlyCreated {
+		p.created.Add(1)
+	} else {
+		p.reused.Add(1)
+	}
+
 	// Update lastAccessed time only if we're reusing a stream to reduce lock contention
 	if !freshlyCreated {
 		peerConn.mu.Lock()
@@ -213,24 +224,21 @@ func (p *ConnectionPool) getStreamFromPeerConn(
 	ctx context.Context,
 	peerConn *peerConnection,
 	peerID peer.ID,
-	protocolID protocol.ID,
+	protocolIDs []protocol.ID,
 ) (network.Stream, bool, error) {
 	peerConn.mu.Lock()
 
-	// Try to get an existing stream
-	if len(peerConn.streams) > 0 {
-		lastIdx := len(peerConn.streams) - 1
-		stream := peerConn.streams[lastIdx]
-		peerConn.streams = peerConn.streams[:lastIdx] // O(1) pop operation
+	// Try to get an existing stream on one of the protocols, most recent first;
+	// namespaces share the pool but not their streams
+	for i := len(peerConn.streams) - 1; i >= 0; i-- {
+		stream := peerConn.streams[i]
+		if len(protocolIDs) > 0 && !slices.Contains(protocolIDs, stream.Protocol()) {
+			continue
+		}
+		peerConn.streams = slices.Delete(peerConn.streams, i, i+1)
 		peerConn.mu.Unlock()
 
-		// Return wrapped stream from pool
-		ms := managedStreamPool.Get().(*ManagedStream)
-		ms.Stream = stream
-		ms.pool = p
-		ms.peerID = peerID
-		ms.closed = false
-		return ms, false, nil
+		return newManagedStream(p, peerID, stream), false, nil
 	}
 	peerConn.mu.Unlock()
 
@@ -243,19 +251,14 @@ func (p *ConnectionPool) getStreamFromPeerConn(
 	// }
 	// p.logger.Debug(fmt.Sprintf("Pool: Successfully created new stream to %s", peerID)) // Use Debug + Sprintf
 
-	// Create a new stream if none available
-	stream, err := p.p2pHost.NewStream(ctx, peerID, protocolID)
+	// Create a new stream if none available; libp2p picks the first protocol the
+	// peer advertises via identify, or negotiates through multistream otherwise
+	stream, err := p.p2pHost.NewStream(ctx, peerID, protocolIDs...)
 	if err != nil {
 		return nil, true, err
 	}
 
-	// Return wrapped stream from pool
-	ms := managedStreamPool.Get().(*ManagedStream)
-	ms.Stream = stream
-	ms.pool = p
-	ms.peerID = peerID
-	ms.closed = false
-	return ms, true, nil
+	return newManagedStream(p, peerID, stream), true, nil
 }
 
 // releaseStream puts a stream back into the pool or closes it
@@ -296,22 +299,86 @@ func (p *ConnectionPool) releaseStream(peerID peer.ID, stream network.Stream) {
 	}
 }
 
+// ShardStats represents the streams of one connection pool shard
+type ShardStats struct {
+	Idle   int // pooled streams waiting for reuse
+	Active int // streams handed out and not yet closed
+}
+
+// ConnectionPoolStats represents connection pool statistics
+type ConnectionPoolStats struct {
+	Shards     [ShardCount]ShardStats
+	Reused     int64
+	Created    int64
+	ReuseRatio float64
+}
+
+// Stats returns pool usage statistics
+func (p *ConnectionPool) Stats() ConnectionPoolStats {
+	var stats ConnectionPoolStats
+	for i, shard := range p.shards {
+		shard.mu.RLock()
+		for _, peerConn := range shard.connections {
+			peerConn.mu.Lock()
+			stats.Shards[i].Idle += len(peerConn.streams)
+			peerConn.mu.Unlock()
+		}
+		shard.mu.RUnlock()
+		stats.Shards[i].Active = int(shard.active.Load())
+	}
+
+	stats.Reused = p.reused.Load()
+	stats.Created = p.created.Load()
+	if total := stats.Reused + stats.Created; total > 0 {
+		stats.ReuseRatio = float64(stats.Reused) / float64(total)
+	}
+	return stats
+}
+
 func (p *ConnectionPool) periodicCleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		p.cleanup()
+	for {
+		select {
+		case <-ticker.C:
+			p.cleanup()
+		case <-p.done:
+			return
+		}
 	}
 }
 
+// Close stops the cleanup goroutine and closes the idle streams. Streams
+// handed out keep working and are closed rather than pooled once released.
+func (p *ConnectionPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+		var streams []network.Stream
+		for _, shard := range p.shards {
+			shard.mu.Lock()
+			for peerID, peerConn := range shard.connections {
+				peerConn.mu.Lock()
+				streams = append(streams, peerConn.streams...)
+				peerConn.streams = nil
+				peerConn.mu.Unlock()
+				delete(shard.connections, peerID)
+			}
+			shard.mu.Unlock()
+		}
+		for _, stream := range streams {
+			stream.Close()
+		}
+	})
+}
+
 func (p *ConnectionPool) cleanup() {
 	now := time.Now()
 
 	// Process each shard independently to reduce lock contention
 	for i := 0; i < ShardCount; i++ {
 		shard := p.shards[i]
-		
+
 		// Create lists for this shard to minimize lock contention
 		var peersToRemove []peer.ID
 		var streamsToClose []network.Stream
@@ -354,14 +421,27 @@ func ConnectToFirstAvailablePeer(
 	h host.Host,
 	peerInfoMap map[peer.ID]peer.AddrInfo,
 	logger glog.Logger,
-) (peer.ID, error) {
+) (_ peer.ID, err error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "drpc.peer.connect", trace.WithAttributes(
+		attribute.Int("drpc.peer.candidates", len(peerInfoMap)),
+	))
+	defer func() {
+		connectStats.record(time.Since(start), err)
+		tracing.End(span, err)
+	}()
+
 	if len(peerInfoMap) == 0 {
 		return "", fmt.Errorf("no peer addresses provided")
 	}
 
 	// Create a context with timeout if not already timeout-bound
-	connectCtx, cancel := context.WithTimeout(ctx, 60*time.Second) // Increased timeout to 60s
-	defer cancel()
+	connectCtx := ctx
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		connectCtx, cancel = context.WithTimeout(ctx, config.CONNECTION_TIMEOUT)
+		defer cancel()
+	}
 
 	// Create a child context that can be cancelled when we find the first successful connection
 	childCtx, childCancel := context.WithCancel(connectCtx)
@@ -370,6 +450,9 @@ func ConnectToFirstAvailablePeer(
 	// Channel to receive the first successful peer connection
 	successChan := make(chan peer.ID, len(peerInfoMap))
 
+	// The last dial error explains a failure to connect to any peer
+	var lastErr atomic.Pointer[error]
+
 	// Start a goroutine for each peer to try connection
 	var wg sync.WaitGroup
 	for peerID, addrInfo := range peerInfoMap {
@@ -405,6 +488,7 @@ func ConnectToFirstAvailablePeer(
 				}
 				return
 			}
+			lastErr.Store(&err)
 
 			// If fast attempt failed, start retry with backoff
 			backoff := time.Millisecond * 100
@@ -417,6 +501,9 @@ func ConnectToFirstAvailablePeer(
 				case <-time.After(backoff):
 					// Try to connect again
 					if err := h.Connect(childCtx, ai); err != nil {
+						if childCtx.Err() == nil {
+							lastErr.Store(&err)
+						}
 						if logger != nil && config.DEBUG {
 							logger.Printf("Failed to connect to peer %s: %v, retrying in %v",
 								pid, err, backoff*2)
@@ -455,10 +542,17 @@ func ConnectToFirstAvailablePeer(
 	select {
 	case pid, ok := <-successChan:
 		if ok && pid != "" {
+			span.SetAttributes(attribute.String("drpc.peer.id", pid.String()))
 			return pid, nil
 		}
+		if err := lastErr.Load(); err != nil {
+			return "", fmt.Errorf("failed to connect to any peer: %w", *err)
+		}
 		return "", errors.New("failed to connect to any peer")
 	case <-connectCtx.Done():
-		return "", fmt.Errorf("connection timeout after 60 seconds: %v", connectCtx.Err()) // Updated error message
+		if err := lastErr.Load(); err != nil {
+			return "", fmt.Errorf("connection timeout: %w, last error: %w", connectCtx.Err(), *err)
+		}
+		return "", fmt.Errorf("connection timeout: %w", connectCtx.Err())
 	}
 }
@@ -5,14 +5,15 @@ import (
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/omgolab/drpc/pkg/config"
 	glog "github.com/omgolab/go-commons/pkg/log" // Added import
 )
 
-// Default pool configuration
-const (
-	defaultMaxIdleTime = 5 * time.Minute
-	defaultMaxStreams  = 10
-)
+// Settings tunes a connection pool. Zero fields keep the defaults.
+type Settings struct {
+	MaxIdleTime time.Duration // how long an idle peer's streams are kept
+	MaxStreams  int           // idle streams pooled per peer
+}
 
 var (
 	// defaultInstance is the global connection pool manager
@@ -28,18 +29,63 @@ type PoolManager struct {
 
 // GetPool returns a connection pool for the given host, creating it if necessary
 func GetPool(h host.Host, logger glog.Logger) *ConnectionPool { // Added logger param
-	// Initialize singleton instance if not already done
+	return GetPoolWithSettings(h, logger, Settings{})
+}
+
+// GetPoolWithSettings is like GetPool but creates a missing pool with settings.
+// A pool that already exists for the host keeps its settings.
+func GetPoolWithSettings(h host.Host, logger glog.Logger, settings Settings) *ConnectionPool {
+	return manager().getOrCreate(h, logger, settings)
+}
+
+// LookupPool returns the connection pool of the host, or nil if it has none.
+// Unlike GetPool it never creates a pool, so observers such as metrics can
+// read the pool without racing its owner to set it up.
+func LookupPool(h host.Host) *ConnectionPool {
+	return manager().Lookup(h)
+}
+
+// RemovePool closes and forgets the connection pool of the host, if it has
+// one. A later GetPool creates a new pool.
+func RemovePool(h host.Host) {
+	manager().Remove(h)
+}
+
+// manager returns the global pool manager, creating it on first use
+func manager() *PoolManager {
 	once.Do(func() {
 		defaultInstance = &PoolManager{
 			pools: make(map[string]*ConnectionPool),
 		}
 	})
+	return defaultInstance
+}
 
-	return defaultInstance.GetOrCreate(h, logger) // Pass logger
+// Lookup returns the pool of the host, or nil if it has none
+func (pm *PoolManager) Lookup(h host.Host) *ConnectionPool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.pools[h.ID().String()]
+}
+
+// Remove closes and forgets the pool of the host, if any
+func (pm *PoolManager) Remove(h host.Host) {
+	pm.mu.Lock()
+	pool, exists := pm.pools[h.ID().String()]
+	delete(pm.pools, h.ID().String())
+	pm.mu.Unlock()
+
+	if exists {
+		pool.Close()
+	}
 }
 
 // GetOrCreate returns an existing pool for the host or creates a new one
 func (pm *PoolManager) GetOrCreate(h host.Host, logger glog.Logger) *ConnectionPool { // Added logger param
+	return pm.getOrCreate(h, logger, Settings{})
+}
+
+func (pm *PoolManager) getOrCreate(h host.Host, logger glog.Logger, settings Settings) *ConnectionPool {
 	// Cache the host ID string to avoid repeated conversion
 	hostID := h.ID().String()
 
@@ -61,11 +107,17 @@ func (pm *PoolManager) GetOrCreate(h host.Host, logger glog.Logger) *ConnectionP
 		return pool
 	}
 
-	// Create new pool with default configuration
+	// Create new pool, filling in the default configuration
+	if settings.MaxIdleTime == 0 {
+		settings.MaxIdleTime = config.POOL_MAX_IDLE_TIME
+	}
+	if settings.MaxStreams == 0 {
+		settings.MaxStreams = config.POOL_MAX_STREAMS
+	}
 	pool = NewConnectionPool(
 		h,
-		defaultMaxIdleTime,
-		defaultMaxStreams,
+		settings.MaxIdleTime,
+		settings.MaxStreams,
 		logger, // Pass logger to constructor
 	)
 	pm.pools[hostID] = pool
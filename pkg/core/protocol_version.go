@@ -0,0 +1,103 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/omgolab/drpc/pkg/config"
+)
+
+// ParseProtocolVersion parses a "major.minor.patch" wire version
+func ParseProtocolVersion(v string) ([3]int, error) {
+	var parsed [3]int
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return parsed, fmt.Errorf("invalid protocol version %q: expected major.minor.patch", v)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("invalid protocol version %q: %q is not a number", v, part)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// CompareProtocolVersions compares two wire versions like strings.Compare.
+// Unparsable versions sort before valid ones.
+func CompareProtocolVersions(a, b string) int {
+	pa, errA := ParseProtocolVersion(a)
+	pb, errB := ParseProtocolVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return slices.Compare(pa[:], pb[:])
+}
+
+// ProtocolIDs returns the protocol IDs for prefix and versions, highest version first
+// and without duplicates. libp2p tries them in order, so the highest common version wins.
+func ProtocolIDs(prefix string, versions []string) []protocol.ID {
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b string) int { return CompareProtocolVersions(b, a) })
+	sorted = slices.Compact(sorted)
+
+	ids := make([]protocol.ID, 0, len(sorted))
+	for _, v := range sorted {
+		ids = append(ids, protocol.ID(prefix+v))
+	}
+	return ids
+}
+
+// DRPCProtocolIDs returns the default dRPC protocol IDs in negotiation order
+func DRPCProtocolIDs() []protocol.ID {
+	return ProtocolIDs(config.DRPC_PROTOCOL_PREFIX, config.DRPC_PROTOCOL_VERSIONS)
+}
+
+// ProtocolVersion returns the version part of a versioned dRPC protocol ID,
+// namespaced or not
+func ProtocolVersion(pid protocol.ID) string {
+	parts := strings.SplitN(string(pid), "/", 4)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+// ValidateNamespace checks that name can name a namespace: 1 to 64 lowercase
+// letters, digits, '-', '_' or '.', starting with a letter or digit
+func ValidateNamespace(name string) error {
+	if name == "" || len(name) > 64 {
+		return fmt.Errorf("invalid namespace %q: must be 1 to 64 characters", name)
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case i > 0 && (c == '-' || c == '_' || c == '.'):
+		default:
+			return fmt.Errorf("invalid namespace %q: unexpected %q", name, c)
+		}
+	}
+	return nil
+}
+
+// NamespaceProtocolIDs suffixes ids with namespace, e.g. /drpc/1.0.0/tenant-a.
+// An empty namespace returns ids unchanged.
+func NamespaceProtocolIDs(ids []protocol.ID, namespace string) []protocol.ID {
+	if namespace == "" {
+		return ids
+	}
+	namespaced := make([]protocol.ID, 0, len(ids))
+	for _, id := range ids {
+		namespaced = append(namespaced, protocol.ID(string(id)+"/"+namespace))
+	}
+	return namespaced
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+func TestProtocolIDsOrdering(t *testing.T) {
+	got := ProtocolIDs("/drpc/", []string{"1.0.0", "1.10.0", "1.2.0", "1.10.0", "2.0.0"})
+	want := []protocol.ID{"/drpc/2.0.0", "/drpc/1.10.0", "/drpc/1.2.0", "/drpc/1.0.0"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ProtocolIDs() = %v, want %v", got, want)
+	}
+	if v := ProtocolVersion(got[1]); v != "1.10.0" {
+		t.Errorf("ProtocolVersion() = %q, want 1.10.0", v)
+	}
+}
+
+func TestParseProtocolVersion(t *testing.T) {
+	for _, v := range []string{"1", "1.0", "1.0.x", "-1.0.0", "v1.0.0"} {
+		if _, err := ParseProtocolVersion(v); err == nil {
+			t.Errorf("Expected %q to be rejected", v)
+		}
+	}
+	if CompareProtocolVersions("1.0.0", "bogus") <= 0 {
+		t.Error("Valid versions should sort after invalid ones")
+	}
+}
+
+func TestNamespaceProtocolIDs(t *testing.T) {
+	got := NamespaceProtocolIDs(ProtocolIDs("/drpc/", []string{"1.0.0"}), "tenant-a")
+	if want := []protocol.ID{"/drpc/1.0.0/tenant-a"}; !slices.Equal(got, want) {
+		t.Errorf("NamespaceProtocolIDs() = %v, want %v", got, want)
+	}
+	if v := ProtocolVersion(got[0]); v != "1.0.0" {
+		t.Errorf("ProtocolVersion() of a namespaced ID = %q, want 1.0.0", v)
+	}
+	for _, name := range []string{"", "Tenant", "-a", "a/b", strings.Repeat("a", 65)} {
+		if ValidateNamespace(name) == nil {
+			t.Errorf("Expected namespace %q to be rejected", name)
+		}
+	}
+	if err := ValidateNamespace("tenant_a.v2"); err != nil {
+		t.Errorf("ValidateNamespace() = %v", err)
+	}
+}
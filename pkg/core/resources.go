@@ -0,0 +1,115 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	"github.com/omgolab/drpc/pkg/config"
+)
+
+// StreamLimits caps the streams and reserved memory of one dRPC protocol
+// family. Zero fields keep the resource manager's default.
+type StreamLimits struct {
+	Streams        int   // open streams across all peers
+	StreamsPerPeer int   // open streams per remote peer
+	Memory         int64 // bytes reserved across all peers
+	MemoryPerPeer  int64 // bytes reserved per remote peer
+}
+
+// ResourceLimits sets the libp2p resource manager limits of the dRPC protocols
+type ResourceLimits struct {
+	DRPC      StreamLimits // the /drpc/<version> protocols and the drpc service
+	WebStream StreamLimits // the /drpc-webstream/<version> protocols and the drpc-webstream service
+}
+
+// NewResourceManager creates a libp2p resource manager with the default
+// limits and limits for the dRPC protocols of the given wire versions (the
+// defaults if empty), in the default namespace and the given ones. Inbound
+// streams are limited through their service scope; outbound streams, which
+// have no service, through their protocol.
+// Pass it to the host with libp2p.ResourceManager.
+func NewResourceManager(limits ResourceLimits, versions []string, namespaces ...string) (network.ResourceManager, error) {
+	if len(versions) == 0 {
+		versions = config.DRPC_PROTOCOL_VERSIONS
+	}
+
+	scaling := rcmgr.DefaultLimits
+	libp2p.SetDefaultServiceLimits(&scaling)
+
+	partial := rcmgr.PartialLimitConfig{
+		Service:      make(map[string]rcmgr.ResourceLimits),
+		ServicePeer:  make(map[string]rcmgr.ResourceLimits),
+		Protocol:     make(map[protocol.ID]rcmgr.ResourceLimits),
+		ProtocolPeer: make(map[protocol.ID]rcmgr.ResourceLimits),
+	}
+	for _, family := range []struct {
+		service string
+		prefix  string
+		limits  StreamLimits
+	}{
+		{config.DRPC_RESOURCE_SERVICE, config.DRPC_PROTOCOL_PREFIX, limits.DRPC},
+		{config.DRPC_WEB_STREAM_RESOURCE_SERVICE, config.DRPC_WEB_STREAM_PROTOCOL_PREFIX, limits.WebStream},
+	} {
+		if family.limits.Streams < 0 || family.limits.StreamsPerPeer < 0 ||
+			family.limits.Memory < 0 || family.limits.MemoryPerPeer < 0 {
+			return nil, fmt.Errorf("%s limits must not be negative", family.service)
+		}
+		all := rcmgr.ResourceLimits{
+			Streams: rcmgr.LimitVal(family.limits.Streams),
+			Memory:  rcmgr.LimitVal64(family.limits.Memory),
+		}
+		perPeer := rcmgr.ResourceLimits{
+			Streams: rcmgr.LimitVal(family.limits.StreamsPerPeer),
+			Memory:  rcmgr.LimitVal64(family.limits.MemoryPerPeer),
+		}
+		partial.Service[family.service] = all
+		partial.ServicePeer[family.service] = perPeer
+		for _, namespace := range append([]string{""}, namespaces...) {
+			for _, pid := range NamespaceProtocolIDs(ProtocolIDs(family.prefix, versions), namespace) {
+				partial.Protocol[pid] = all
+				partial.ProtocolPeer[pid] = perPeer
+			}
+		}
+	}
+
+	return rcmgr.NewResourceManager(rcmgr.NewFixedLimiter(partial.Build(scaling.AutoScale())))
+}
+
+// attachStream attaches an inbound stream to service and reserves memory for
+// the buffers its handler takes. The reservation is released with the stream.
+func attachStream(s network.Stream, service string, memory int) error {
+	if err := s.Scope().SetService(service); err != nil {
+		return fmt.Errorf("failed to attach stream to service %s: %w", service, err)
+	}
+	if memory > 0 {
+		if err := s.Scope().ReserveMemory(memory, network.ReservationPriorityMedium); err != nil {
+			return fmt.Errorf("failed to reserve %d bytes for service %s: %w", memory, service, err)
+		}
+	}
+	return nil
+}
+
+// rejectStream resets a stream refused by the resource manager, telling the
+// remote peer why so its client can report a resource limit error
+func rejectStream(s network.Stream) {
+	_ = s.ResetWithError(network.StreamResourceLimitExceeded)
+}
+
+// IsResourceLimitError reports whether err comes from a libp2p resource limit,
+// either of this host or of the remote peer that reset the stream
+func IsResourceLimitError(err error) bool {
+	if errors.Is(err, network.ErrResourceLimitExceeded) {
+		return true
+	}
+	var streamErr *network.StreamError
+	return errors.As(err, &streamErr) && streamErr.ErrorCode == network.StreamResourceLimitExceeded
+}
+
+// ResourceLimitsFromFile converts the resource limits of a config file
+func ResourceLimitsFromFile(l config.ResourceLimits) ResourceLimits {
+	return ResourceLimits{DRPC: StreamLimits(l.DRPC), WebStream: StreamLimits(l.WebStream)}
+}
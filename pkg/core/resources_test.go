@@ -0,0 +1,42 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	"github.com/omgolab/drpc/pkg/config"
+)
+
+func TestNewResourceManagerLimitsNamespaces(t *testing.T) {
+	rm, err := NewResourceManager(ResourceLimits{
+		DRPC:      StreamLimits{Streams: 3},
+		WebStream: StreamLimits{Streams: 5},
+	}, nil, "tenant-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rm.Close()
+
+	streamLimit := func(pid protocol.ID) int {
+		var limit int
+		_ = rm.ViewProtocol(pid, func(scope network.ProtocolScope) error {
+			limit = scope.(rcmgr.ResourceScopeLimiter).Limit().GetStreamTotalLimit()
+			return nil
+		})
+		return limit
+	}
+	for _, namespace := range []string{"", "tenant-a"} {
+		for _, pid := range NamespaceProtocolIDs(DRPCProtocolIDs(), namespace) {
+			if got := streamLimit(pid); got != 3 {
+				t.Errorf("Stream limit of %s = %d, want 3", pid, got)
+			}
+		}
+		for _, pid := range NamespaceProtocolIDs(ProtocolIDs(config.DRPC_WEB_STREAM_PROTOCOL_PREFIX, config.DRPC_PROTOCOL_VERSIONS), namespace) {
+			if got := streamLimit(pid); got != 5 {
+				t.Errorf("Stream limit of %s = %d, want 5", pid, got)
+			}
+		}
+	}
+}
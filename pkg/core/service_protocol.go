@@ -0,0 +1,27 @@
+package core
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/omgolab/drpc/pkg/config"
+)
+
+// ServiceProtocolID returns the marker protocol announcing that a host serves service
+func ServiceProtocolID(service string) protocol.ID {
+	return protocol.ID(config.DRPC_SERVICE_PROTOCOL_PREFIX + service)
+}
+
+// ServiceNames returns the services announced by marker protocols in protocols, sorted by name.
+// Use it with a host's own protocols or with the protocols identify recorded for a peer.
+func ServiceNames(protocols []protocol.ID) []string {
+	var names []string
+	for _, pid := range protocols {
+		if name, ok := strings.CutPrefix(string(pid), config.DRPC_SERVICE_PROTOCOL_PREFIX); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
@@ -0,0 +1,102 @@
+// Package tracing propagates W3C trace context across dRPC hops and starts
+// spans with the tracer provider attached to the request context.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of every dRPC span
+const TracerName = "github.com/omgolab/drpc"
+
+// Propagator reads and writes the W3C traceparent and tracestate headers
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+type tracerProviderKey struct{}
+
+// ContextWithTracerProvider returns a copy of ctx whose spans are created by tp
+func ContextWithTracerProvider(ctx context.Context, tp trace.TracerProvider) context.Context {
+	return context.WithValue(ctx, tracerProviderKey{}, tp)
+}
+
+// Start starts a span named name. It uses the tracer provider attached to ctx,
+// or else the one of the span in ctx; without either the span is a no-op that
+// still carries the parent's trace context.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tp, ok := ctx.Value(tracerProviderKey{}).(trace.TracerProvider)
+	if !ok {
+		tp = trace.SpanFromContext(ctx).TracerProvider()
+	}
+	return tp.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// End records err, if any, on span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract returns ctx with the remote trace context carried by header
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return Propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject writes the trace context of ctx to header, replacing any previous one
+func Inject(ctx context.Context, header http.Header) {
+	Propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// SplitPath separates the trace context a web client appended to a procedure
+// path as query parameters, e.g. "/pkg.Service/Method?traceparent=00-...".
+// Web stream envelopes have no headers, so this is how they carry it.
+func SplitPath(path string) (string, http.Header) {
+	procedure, query, found := strings.Cut(path, "?")
+	if !found {
+		return path, nil
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return procedure, nil
+	}
+
+	header := make(http.Header)
+	for _, key := range Propagator.Fields() {
+		if v := values.Get(key); v != "" {
+			header.Set(key, v)
+		}
+	}
+	return procedure, header
+}
+
+// Handler attaches tp to the context of every request served by next
+func Handler(tp trace.TracerProvider, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(ContextWithTracerProvider(r.Context(), tp)))
+	})
+}
+
+// Transport injects the trace context of each request's context into its headers
+func Transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if !trace.SpanContextFromContext(r.Context()).IsValid() {
+			return next.RoundTrip(r)
+		}
+		r = r.Clone(r.Context())
+		Inject(r.Context(), r.Header)
+		return next.RoundTrip(r)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
@@ -9,12 +9,17 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/omgolab/drpc/pkg/config"
 	"github.com/omgolab/drpc/pkg/core/pool"
+	"github.com/omgolab/drpc/pkg/core/tracing"
 	glog "github.com/omgolab/go-commons/pkg/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/http2"
 )
 
@@ -143,9 +148,10 @@ func parseWebStreamEnvelope(stream network.Stream, logger glog.Logger) (procedur
 		return "", "", err
 	}
 	procedurePath = string(pathBuf)
+	cacheKey, _, _ := strings.Cut(procedurePath, "?") // without any trace context query
 
 	// Check cache for this path's content type
-	if cachedContentType, found := getCachedContentType(procedurePath); found {
+	if cachedContentType, found := getCachedContentType(cacheKey); found {
 		// Fast path: use cached content type, but we still need to read it from stream for consistency
 		logger.Debug(fmt.Sprintf("parseWebStreamEnvelope: Using cached content type for path %s: %s", procedurePath, cachedContentType))
 	}
@@ -174,7 +180,7 @@ func parseWebStreamEnvelope(stream network.Stream, logger glog.Logger) (procedur
 	contentType = string(contentTypeBuf)
 
 	// Cache the content type for this path for future use
-	setCachedContentType(procedurePath, contentType)
+	setCachedContentType(cacheKey, contentType)
 
 	return procedurePath, contentType, nil
 }
@@ -241,6 +247,7 @@ func performHTTP2Bridging(
 	httpRequest.Header.Set("Content-Type", contentType)
 	httpRequest.Header.Set("Accept", contentType)
 	httpRequest.Header.Set("Connect-Protocol-Version", "1")
+	tracing.Inject(ctx, httpRequest.Header)
 
 	httpResponse, err := httpClient.Do(httpRequest)
 	if err != nil {
@@ -368,6 +375,8 @@ func min(a, b int) int {
 // (procedure path, content type) and bridging the stream to an HTTP handler
 // using an in-memory HTTP/2 connection.
 // Enhanced with streaming optimizations and zero-copy buffers.
+// The envelope has no headers, so web clients may append the W3C trace context
+// to the procedure path as traceparent and tracestate query parameters.
 func ServeWebStreamBridge(
 	ctx context.Context, // Parent context for operations
 	baseLogger glog.Logger, // Logger instance; if nil, a no-op logger will be used
@@ -379,6 +388,13 @@ func ServeWebStreamBridge(
 		logger, _ = glog.New() // Fallback to a no-op logger
 	}
 
+	// Account the stream and its two copy buffers to the web stream service
+	if err := attachStream(stream, config.DRPC_WEB_STREAM_RESOURCE_SERVICE, 2*pool.LargeBufferPool.Size()); err != nil {
+		logger.Debug(fmt.Sprintf("ServeWebStreamBridge: Refusing stream - remotePeer: %s, error: %s", stream.Conn().RemotePeer().String(), err.Error()))
+		rejectStream(stream)
+		return
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
 			logger.Error(fmt.Sprintf("Panic recovered in ServeWebStreamBridge - remotePeer: %s", stream.Conn().RemotePeer().String()), fmt.Errorf("panic: %v", r))
@@ -405,6 +421,20 @@ func ServeWebStreamBridge(
 		return
 	}
 
+	// Make the caller identity available to the handler
+	ctx = ContextWithPeerInfo(ctx, PeerInfoFromStream(stream, EntryWebStream))
+
+	// Continue the caller's trace, if any, with a span covering the bridge
+	procedurePath, traceHeader := tracing.SplitPath(procedurePath)
+	ctx, span := tracing.Start(tracing.Extract(ctx, traceHeader), "drpc.webstream.bridge",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.procedure", procedurePath),
+			attribute.String("drpc.peer.id", stream.Conn().RemotePeer().String()),
+		),
+	)
+	defer span.End()
+
 	// logger.Info(fmt.Sprintf("ServeWebStreamBridge: Handling stream - procedure: %s, contentType: %s, remotePeer: %s", procedurePath, contentType, stream.Conn().RemotePeer().String()))
 
 	performHTTP2Bridging(ctx, logger, httpHandler, stream, procedurePath, contentType)
@@ -3,22 +3,21 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strings"
 
-	"crypto/tls"
-
 	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/omgolab/drpc/pkg/config"
 	"github.com/omgolab/drpc/pkg/core"
-	"github.com/omgolab/drpc/pkg/core/host"
+	dhost "github.com/omgolab/drpc/pkg/core/host"
 	"github.com/omgolab/drpc/pkg/core/pool"
 	"github.com/omgolab/drpc/pkg/gateway"
-	glog "github.com/omgolab/go-commons/pkg/log"
 	"golang.org/x/net/http2"
 )
 
@@ -30,12 +29,36 @@ import (
 // 2. **Path 2:** dRPC Client → Listener(if serverAddr is an http address with gateway indication) → Gateway Handler → Relay libp2p Peer → Host libp2p Peer → dRPC Handler
 // 3. **Path 3:** dRPC Client → Host libp2p Peer (if serverAddr is a libp2p multiaddress) → dRPC Handler
 // 4. **Path 4:** dRPC Client → Relay libp2p Peer(if serverAddr is a libp2p multiaddress) → Host libp2p Peer → dRPC Handler
+// 5. **Path 5:** dRPC Client → DHT provider lookup (if serverAddr is "service:<name>") → Host libp2p Peer → dRPC Handler
+//
+// The libp2p paths run a libp2p host for the client alone, which lives as
+// long as ctx: cancel ctx once done with the client to close it. Use
+// NewWithCloser when ctx should only bound the connection, or a Session to
+// share one host between the clients of several services.
 func New[T any](
 	ctx context.Context,
 	serverAddr string,
 	newServiceClient func(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) T,
 	clientOpts ...Option,
 ) (T, error) {
+	c, closer, err := NewWithCloser(ctx, serverAddr, newServiceClient, clientOpts...)
+	if err != nil {
+		return c, err
+	}
+	// The client's host closes with ctx
+	context.AfterFunc(ctx, func() { _ = closer.Close() })
+	return c, nil
+}
+
+// NewWithCloser is like New, but ctx only bounds the connection to the
+// server: the client, with its libp2p host, runs until the returned Closer
+// is closed.
+func NewWithCloser[T any](
+	ctx context.Context,
+	serverAddr string,
+	newServiceClient func(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) T,
+	clientOpts ...Option,
+) (T, io.Closer, error) {
 	var zeroValue T
 
 	// Initialize client with default settings
@@ -43,11 +66,9 @@ func New[T any](
 
 	// Apply options
 	if err := client.applyOptions(clientOpts...); err != nil {
-		return zeroValue, fmt.Errorf("failed to apply client options: %w", err)
+		return zeroValue, nil, fmt.Errorf("failed to apply client options: %w", err)
 	}
 
-	logger := client.logger
-
 	// Handle HTTP paths (Path 1 and 2)
 	if strings.HasPrefix(serverAddr, "http://") || strings.HasPrefix(serverAddr, "https://") {
 		// For HTTP paths, we can directly use the ConnectRPC client with the http address
@@ -55,87 +76,108 @@ func New[T any](
 
 		// Always use HTTP/2 transport for both HTTP and HTTPS
 		// This provides better multiplexing and performance
-		httpClient := &http.Client{
-			Transport: optimizedHTTP2Transport(),
-		}
+		useTLS := strings.HasPrefix(serverAddr, "https://")
+		transport := optimizedHTTP2Transport(useTLS, client.tlsConfig)
+		httpClient := newHTTPClient(transport)
 
 		// Create the ConnectRPC client
 		return newServiceClient(
 			httpClient,
-			serverAddr,            // Use the provided HTTP URL directly
-			client.connectOpts..., // Pass collected connect options
-		), nil
+			namespaceURL(serverAddr, client.namespace), // Use the provided HTTP URL directly
+			client.connectOpts...,                      // Pass collected connect options
+		), idleConnsCloser{transport}, nil
 	}
 
-	// Handle libp2p paths (Path 3 and 4) and gateway format with the unified parser
-	peerAddrs, err := gateway.ParseCommaSeparatedMultiAddresses(serverAddr) // We don't need servicePath for direct connections
+	// Handle libp2p paths (Path 3 and 4) and gateway format with the unified parser;
+	// service targets are resolved once the host's DHT is up
+	t, err := parseTarget(serverAddr)
 	if err != nil {
-		logger.Error("Failed to parse addresses", err)
-		return zeroValue, fmt.Errorf("failed to parse addresses: %w", err)
+		client.logger.Error("Failed to parse addresses", err)
+		return zeroValue, nil, err
 	}
 
-	// Creating a new libp2p host for the client.
-	clientHost, err := host.CreateLibp2pHost(
-		ctx,
-		host.WithHostLogger(logger),
-		host.WithHostLibp2pOptions(client.libp2pOptions...),
-		host.WithHostDHTOptions(client.dhtOptions...),
-		host.WithHostAsClientMode(),
-	)
+	// Creating a new libp2p host for the client, used by this client alone
+	session, err := newSession(ctx, client)
 	if err != nil {
-		logger.Error("Failed to create libp2p host", err)
-		return zeroValue, fmt.Errorf("failed to create libp2p host: %w", err)
+		return zeroValue, nil, err
+	}
+	if _, err := session.connect(ctx, t); err != nil {
+		_ = session.Close()
+		return zeroValue, nil, err
 	}
 
-	// Get connection pool from manager
-	connPool := pool.GetPool(clientHost, logger) // Pass logger
+	// Create the ConnectRPC client
+	return newServiceClient(
+		session.libp2pClient(t),
+		"http://localhost",    // Placeholder URL, as we're using a custom dialer
+		client.connectOpts..., // Pass collected connect options
+	), session, nil
+}
+
+// idleConnsCloser closes the idle connections of the transport of an HTTP client
+type idleConnsCloser struct {
+	transport *http2.Transport
+}
 
-	// Convert the peer addresses map to the format expected by connection logic
-	addrInfoMap := gateway.ConvertToAddrInfoMap(peerAddrs)
+func (c idleConnsCloser) Close() error {
+	c.transport.CloseIdleConnections()
+	return nil
+}
 
-	// Try connecting to peers in parallel
-	connectedPeerID, err := pool.ConnectToFirstAvailablePeer(
-		ctx,
-		clientHost,
-		addrInfoMap,
-		logger,
-	)
+// NewForService creates a ConnectRPC client for any peer providing serviceName,
+// e.g. "greeter.v1.GreeterService". Providers are looked up on the DHT and the
+// first one that accepts a connection is used. It is equivalent to New with a
+// "service:<name>" address.
+func NewForService[T any](
+	ctx context.Context,
+	serviceName string,
+	newServiceClient func(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) T,
+	clientOpts ...Option,
+) (T, error) {
+	return New(ctx, gateway.ServiceAddrPrefix+serviceName, newServiceClient, clientOpts...)
+}
+
+// findServiceProviders looks up the providers of serviceName on the DHT of h
+func findServiceProviders(ctx context.Context, h host.Host, serviceName string) (map[peer.ID]peer.AddrInfo, error) {
+	sd := dhost.GetServiceDiscovery(h)
+	if sd == nil {
+		return nil, fmt.Errorf("service discovery is not available for %q", serviceName)
+	}
 
+	lookupCtx, cancel := context.WithTimeout(ctx, config.CONNECTION_TIMEOUT)
+	defer cancel()
+	providers, err := sd.FindProviders(lookupCtx, serviceName)
 	if err != nil {
-		return zeroValue, fmt.Errorf("failed to connect to any peer: %w", err)
+		return nil, fmt.Errorf("failed to find service providers: %w", err)
 	}
 
-	logger.Info("Successfully connected to peer", glog.LogFields{"peerID": connectedPeerID.String()})
+	addrInfoMap := make(map[peer.ID]peer.AddrInfo, len(providers))
+	for _, p := range providers {
+		addrInfoMap[p.ID] = p
+	}
+	return addrInfoMap, nil
+}
 
-	// Custom transport that uses the libp2p dialer with connection pool
-	// Keep track of the current stream for reuse? Maybe not needed if transport handles it. Let's keep for now.
-	var currentStream network.Stream
+// resourceLimitTransport reports streams refused by a libp2p resource limit,
+// local or remote, as Connect ResourceExhausted instead of Unavailable, and
+// streams refused by a busy server as Unavailable, which can be retried
+type resourceLimitTransport struct {
+	next http.RoundTripper
+}
 
-	// Use standard http.Transport for libp2p connections.
-	// Both http.Transport and http2.Transport can be used and works/tested, but http2 is preferred for HTTP/2.
-	transport := &http2.Transport{
-		AllowHTTP: true,
-		DialTLSContext: func(ctx context.Context, network, addr string, tlsCfg *tls.Config) (net.Conn, error) {
-			// Ignore TLS, use libp2p dialer for h2c
-			return dialWithPool(ctx, connPool, config.DRPC_PROTOCOL_ID, connectedPeerID, &currentStream)
-		},
+func (t resourceLimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(r)
+	if err != nil && core.IsResourceLimitError(err) {
+		return nil, connect.NewError(connect.CodeResourceExhausted, err)
 	}
-
-	// Create a custom HTTP client with the libp2p transport
-	httpClient := &http.Client{
-		Transport: transport,
+	if err != nil && core.IsBusyError(err) {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("server busy: %w", err))
 	}
-
-	// Create the ConnectRPC client
-	return newServiceClient(
-		httpClient,
-		"http://localhost",    // Placeholder URL, as we're using a custom dialer
-		client.connectOpts..., // Pass collected connect options
-	), nil
+	return resp, err
 }
 
 // dialWithPool uses a libp2p host and connection pool as dialer.
-func dialWithPool(ctx context.Context, connPool *pool.ConnectionPool, pid protocol.ID, peerID peer.ID, currentStream *network.Stream) (net.Conn, error) {
+func dialWithPool(ctx context.Context, connPool *pool.ConnectionPool, pids []protocol.ID, peerID peer.ID, currentStream *network.Stream) (net.Conn, error) {
 	// If we already have a stream, check if it's still valid and reuse it
 	if *currentStream != nil {
 		// Only check direction and basic connection state
@@ -153,11 +195,11 @@ func dialWithPool(ctx context.Context, connPool *pool.ConnectionPool, pid protoc
 		*currentStream = nil
 	}
 
-	// Get a new stream from the pool using the application protocol ID (pid)
+	// Get a new stream from the pool using the application protocol IDs (pids)
 	// Libp2p handles the underlying relay mechanism transparently.
-	stream, err := connPool.GetStream(ctx, peerID, pid) // Always use the provided application protocol ID
+	stream, err := connPool.GetStream(ctx, peerID, pids...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stream from pool for peer %s with protocol %s: %w", peerID, pid, err)
+		return nil, fmt.Errorf("failed to get stream from pool for peer %s with protocols %v: %w", peerID, pids, err)
 	}
 	*currentStream = stream
 
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "OK")
+	})
+}
+
+func TestOptimizedHTTP2TransportTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(okHandler())
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	httpClient := &http.Client{Transport: optimizedHTTP2Transport(true, &tls.Config{RootCAs: roots})}
+	resp, err := httpClient.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2, got %s", resp.Proto)
+	}
+	if resp.TLS == nil || resp.TLS.NegotiatedProtocol != http2.NextProtoTLS {
+		t.Errorf("Expected ALPN %q, got %+v", http2.NextProtoTLS, resp.TLS)
+	}
+}
+
+func TestOptimizedHTTP2TransportH2C(t *testing.T) {
+	srv := httptest.NewServer(h2c.NewHandler(okHandler(), &http2.Server{}))
+	defer srv.Close()
+
+	httpClient := &http.Client{Transport: optimizedHTTP2Transport(false, nil)}
+	resp, err := httpClient.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("h2c request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2, got %s", resp.Proto)
+	}
+	if resp.TLS != nil {
+		t.Error("Expected a cleartext connection")
+	}
+}
+
+func TestOptimizedHTTP2TransportRejectsUntrustedCert(t *testing.T) {
+	srv := httptest.NewUnstartedServer(okHandler())
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	httpClient := &http.Client{Transport: optimizedHTTP2Transport(true, nil)}
+	if resp, err := httpClient.Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected certificate verification error")
+	}
+}
@@ -3,22 +3,27 @@ package client
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net"
 
 	"connectrpc.com/connect"
 	"github.com/libp2p/go-libp2p"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/identity"
 	glog "github.com/omgolab/go-commons/pkg/log"
 	"golang.org/x/net/http2"
 )
 
-// optimizedHTTP2Transport returns an HTTP/2 transport optimized for production
-func optimizedHTTP2Transport() *http2.Transport {
+// optimizedHTTP2Transport returns an HTTP/2 transport optimized for production.
+// With useTLS the connection is established over TLS with h2 negotiated via ALPN;
+// otherwise cleartext HTTP/2 (h2c) is used.
+func optimizedHTTP2Transport(useTLS bool, baseTLSConfig *tls.Config) *http2.Transport {
 	// Create optimized TLS config with session resumption for HTTPS
 	tlsConfig := &tls.Config{
-		// Enable TLS session resumption for faster handshakes
-		ClientSessionCache: tls.NewLRUClientSessionCache(256),
-
 		// Use modern TLS settings
 		MinVersion: tls.VersionTLS12,
 
@@ -30,17 +35,15 @@ func optimizedHTTP2Transport() *http2.Transport {
 			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
 		},
 	}
+	if baseTLSConfig != nil {
+		tlsConfig = baseTLSConfig.Clone()
+	}
+	// Enable TLS session resumption for faster handshakes
+	if tlsConfig.ClientSessionCache == nil {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(256)
+	}
 
-	return &http2.Transport{
-		// Allow non-TLS HTTP/2 for internal communication (h2c)
-		AllowHTTP: true,
-
-		// Custom dialer that skips TLS for http:// addresses
-		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
-			var d net.Dialer
-			return d.DialContext(ctx, network, addr)
-		},
-
+	transport := &http2.Transport{
 		// Connection settings
 		MaxHeaderListSize: 32 << 10, // 32KB
 
@@ -50,6 +53,19 @@ func optimizedHTTP2Transport() *http2.Transport {
 		// Connection pooling
 		DisableCompression: false,
 	}
+
+	if !useTLS {
+		// Allow non-TLS HTTP/2 for internal communication (h2c)
+		transport.AllowHTTP = true
+
+		// Custom dialer that skips TLS for http:// addresses
+		transport.DialTLSContext = func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		}
+	}
+
+	return transport
 }
 
 // Config holds the client configuration
@@ -58,6 +74,15 @@ type Config struct {
 	connectOpts   []connect.ClientOption
 	libp2pOptions []libp2p.Option
 	dhtOptions    []dht.Option
+	tlsConfig     *tls.Config
+	// protocolVersions restricts the dRPC wire versions offered to servers
+	protocolVersions []string
+	resourceLimits   *core.ResourceLimits
+	configFile       *config.File // host level sections of WithConfigFile
+	identity         crypto.PrivKey
+	privateNetwork   pnet.PSK
+	eventHandler     func(evt any)
+	namespace        string // empty for the server's default services
 }
 
 // Option configures a Client.
@@ -104,6 +129,140 @@ func WithDHTOptions(opts ...dht.Option) Option {
 	}
 }
 
+// WithTLSConfig sets the TLS configuration used for https:// server addresses,
+// e.g. to trust a private CA. The config is cloned before use.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Config) error {
+		c.tlsConfig = cfg
+		return nil
+	}
+}
+
+// WithProtocolVersions sets the dRPC wire versions the client offers over libp2p.
+// The highest version supported by both sides is negotiated.
+func WithProtocolVersions(versions ...string) Option {
+	return func(c *Config) error {
+		if len(versions) == 0 {
+			return fmt.Errorf("at least one protocol version is required")
+		}
+		for _, v := range versions {
+			if _, err := core.ParseProtocolVersion(v); err != nil {
+				return err
+			}
+		}
+		c.protocolVersions = versions
+		return nil
+	}
+}
+
+// WithNamespace calls the services of a server namespace, see
+// server.WithNamespace: over libp2p on the namespace's protocol IDs, over
+// HTTP under its path prefix on the server's HTTP listener. Gateways do not
+// forward calls into namespaces.
+func WithNamespace(name string) Option {
+	return func(c *Config) error {
+		if err := core.ValidateNamespace(name); err != nil {
+			return err
+		}
+		c.namespace = name
+		return nil
+	}
+}
+
+// WithResourceLimits sets the libp2p resource manager limits of the dRPC
+// protocols on the client's host. Calls over a stream refused by a local or
+// remote limit fail with a Connect ResourceExhausted error. It cannot be
+// combined with a libp2p.ResourceManager passed through WithLibp2pOptions.
+func WithResourceLimits(limits core.ResourceLimits) Option {
+	return func(c *Config) error {
+		c.resourceLimits = &limits
+		return nil
+	}
+}
+
+// WithIdentity sets the private key, and so the peer ID, of the client's
+// libp2p host, e.g. so servers can allow it by peer ID.
+func WithIdentity(key crypto.PrivKey) Option {
+	return func(c *Config) error {
+		if key == nil {
+			return fmt.Errorf("identity key cannot be nil")
+		}
+		c.identity = key
+		return nil
+	}
+}
+
+// WithPrivateNetwork restricts the client's libp2p host to the private network
+// of the servers it calls, identified by their 32 byte pre-shared key.
+// Connecting to a server outside of it fails with host.ErrPrivateNetwork.
+func WithPrivateNetwork(psk pnet.PSK) Option {
+	return func(c *Config) error {
+		if len(psk) != 32 {
+			return fmt.Errorf("private network key must be 32 bytes, got %d", len(psk))
+		}
+		c.privateNetwork = psk
+		return nil
+	}
+}
+
+// WithEventHandler calls handler with the lifecycle events of the client's
+// libp2p host: peers discovered, connected and disconnected, relay
+// reservations and reachability, see package events. It is called on a
+// single goroutine in the order of the events; events are dropped while a
+// slow handler lags behind. Clients
+// of http:// and https:// addresses run no host and emit no events.
+func WithEventHandler(handler func(evt any)) Option {
+	return func(c *Config) error {
+		if handler == nil {
+			return fmt.Errorf("event handler cannot be nil")
+		}
+		c.eventHandler = handler
+		return nil
+	}
+}
+
+// WithIdentityFile loads the client's key from path, or generates and stores
+// one there on first use. See identity.LoadOrGenerate for the options.
+func WithIdentityFile(path string, opts ...identity.Option) Option {
+	return func(c *Config) error {
+		key, err := identity.LoadOrGenerate(path, opts...)
+		if err != nil {
+			return err
+		}
+		c.identity = key
+		return nil
+	}
+}
+
+// WithConfigFile loads a YAML or JSON config file, with DRPC_* environment
+// overrides, and applies its libp2p, identity, discovery, relay, connections,
+// resource limits and pool sections. The http, cors and rate limit sections
+// only apply to servers. Options after it override the file.
+func WithConfigFile(path string) Option {
+	return func(c *Config) error {
+		f, err := config.LoadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := WithProtocolVersions(f.Libp2p.ProtocolVersions...)(c); err != nil {
+			return fmt.Errorf("invalid config file: %w", err)
+		}
+		if f.Limits.Resources.Enabled() {
+			limits := core.ResourceLimitsFromFile(f.Limits.Resources)
+			c.resourceLimits = &limits
+		}
+		key, err := identity.FromConfig(f.Identity)
+		if err != nil {
+			return fmt.Errorf("invalid config file identity: %w", err)
+		}
+		if key != nil {
+			c.identity = key
+		}
+		c.configFile = &f
+		return nil
+	}
+}
+
 func (c *Config) applyOptions(opts ...Option) error {
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core"
+	dhost "github.com/omgolab/drpc/pkg/core/host"
+	"github.com/omgolab/drpc/pkg/core/pool"
+	"github.com/omgolab/drpc/pkg/core/tracing"
+	"github.com/omgolab/drpc/pkg/gateway"
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"golang.org/x/net/http2"
+)
+
+// ErrSessionClosed is returned by the calls of the clients of a closed Session
+var ErrSessionClosed = errors.New("client session is closed")
+
+// Session owns one libp2p host, with its discovery services and connection
+// pool, shared by every service client created with NewServiceClient. The
+// clients reuse the connections and streams of the session instead of each
+// running a host.
+type Session struct {
+	cfg            *Config
+	host           *dhost.ManagedHost
+	pool           *pool.ConnectionPool
+	connectTimeout time.Duration
+	protocolIDs    []protocol.ID
+	h2cTransport   *http2.Transport // for http:// addresses
+	tlsTransport   *http2.Transport // for https:// addresses
+	closed         atomic.Bool
+
+	mu    sync.Mutex
+	peers map[string]peer.ID // connected peer by server address
+}
+
+// NewSession creates the libp2p host of a session from opts, which apply to
+// every client of the session. ctx only bounds the creation of the host: the
+// session, with its discovery services, runs until Close.
+func NewSession(ctx context.Context, opts ...Option) (*Session, error) {
+	cfg := &Config{}
+	if err := cfg.applyOptions(opts...); err != nil {
+		return nil, fmt.Errorf("failed to apply client options: %w", err)
+	}
+	return newSession(ctx, cfg)
+}
+
+func newSession(ctx context.Context, cfg *Config) (*Session, error) {
+	logger := cfg.logger
+
+	libp2pOptions := cfg.libp2pOptions
+	if cfg.resourceLimits != nil {
+		rm, err := core.NewResourceManager(*cfg.resourceLimits, cfg.protocolVersions, cfg.namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create resource manager: %w", err)
+		}
+		libp2pOptions = append(slices.Clone(libp2pOptions), libp2p.ResourceManager(rm))
+	}
+
+	hostOptions := []dhost.HostOption{dhost.WithHostLogger(logger)}
+	connectTimeout := config.CONNECTION_TIMEOUT
+	var poolSettings pool.Settings
+	if f := cfg.configFile; f != nil {
+		hostOptions = append(hostOptions, dhost.WithHostConfig(*f))
+		connectTimeout = f.Connections.ConnectTimeout.Std()
+		poolSettings = pool.Settings{MaxIdleTime: f.Pool.MaxIdleTime.Std(), MaxStreams: f.Pool.MaxStreams}
+	}
+	if cfg.identity != nil {
+		hostOptions = append(hostOptions, dhost.WithHostIdentity(cfg.identity))
+	}
+	if cfg.privateNetwork != nil {
+		hostOptions = append(hostOptions, dhost.WithHostPrivateNetwork(cfg.privateNetwork))
+	}
+	if cfg.eventHandler != nil {
+		hostOptions = append(hostOptions, dhost.WithHostEventHandler(cfg.eventHandler))
+	}
+	clientHost, err := dhost.CreateLibp2pHost(
+		// The host outlives ctx, which may only be meant for dialing
+		context.WithoutCancel(ctx),
+		append(hostOptions,
+			dhost.WithHostLibp2pOptions(libp2pOptions...),
+			dhost.WithHostDHTOptions(cfg.dhtOptions...),
+			dhost.WithHostAsClientMode(),
+		)...,
+	)
+	if err != nil {
+		logger.Error("Failed to create libp2p host", err)
+		return nil, fmt.Errorf("failed to create libp2p host: %w", err)
+	}
+
+	// Offer every wire version we speak, highest first; the server picks through multistream
+	protocolIDs := core.DRPCProtocolIDs()
+	if len(cfg.protocolVersions) > 0 {
+		protocolIDs = core.ProtocolIDs(config.DRPC_PROTOCOL_PREFIX, cfg.protocolVersions)
+	}
+
+	s := &Session{
+		cfg:            cfg,
+		host:           clientHost,
+		pool:           pool.GetPoolWithSettings(clientHost, logger, poolSettings),
+		connectTimeout: connectTimeout,
+		protocolIDs:    core.NamespaceProtocolIDs(protocolIDs, cfg.namespace),
+		h2cTransport:   optimizedHTTP2Transport(false, cfg.tlsConfig),
+		tlsTransport:   optimizedHTTP2Transport(true, cfg.tlsConfig),
+		peers:          make(map[string]peer.ID),
+	}
+	return s, nil
+}
+
+// Host returns the libp2p host of the session
+func (s *Session) Host() host.Host {
+	return s.host
+}
+
+// Close closes the host of the session, its discovery services and
+// connection pool, failing the calls of its clients
+func (s *Session) Close() error {
+	s.closed.Store(true)
+	s.h2cTransport.CloseIdleConnections()
+	s.tlsTransport.CloseIdleConnections()
+	return s.host.Close()
+}
+
+// NewServiceClient creates a ConnectRPC client for serverAddr on the session.
+// serverAddr takes the forms of New; libp2p peers are connected on the first
+// call and the connection is shared with the other clients of the session.
+// opts are added to the Connect options of the session.
+func NewServiceClient[T any](
+	s *Session,
+	serverAddr string,
+	newServiceClient func(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) T,
+	opts ...connect.ClientOption,
+) (T, error) {
+	var zeroValue T
+	connectOpts := append(slices.Clone(s.cfg.connectOpts), opts...)
+
+	if strings.HasPrefix(serverAddr, "http://") || strings.HasPrefix(serverAddr, "https://") {
+		transport := s.h2cTransport
+		if strings.HasPrefix(serverAddr, "https://") {
+			transport = s.tlsTransport
+		}
+		return newServiceClient(newHTTPClient(transport), namespaceURL(serverAddr, s.cfg.namespace), connectOpts...), nil
+	}
+
+	t, err := parseTarget(serverAddr)
+	if err != nil {
+		s.cfg.logger.Error("Failed to parse addresses", err)
+		return zeroValue, err
+	}
+	return newServiceClient(s.libp2pClient(t), "http://localhost", connectOpts...), nil
+}
+
+// target is a parsed libp2p server address
+type target struct {
+	addr        string
+	serviceName string // set for "service:<name>" addresses
+	peers       map[peer.ID]peer.AddrInfo
+}
+
+// parseTarget parses the libp2p and service forms of a server address
+func parseTarget(serverAddr string) (*target, error) {
+	if serviceName, ok := gateway.ParseServiceAddress(serverAddr); ok {
+		return &target{addr: serverAddr, serviceName: serviceName}, nil
+	}
+	peerAddrs, err := gateway.ParseCommaSeparatedMultiAddresses(serverAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse addresses: %w", err)
+	}
+	return &target{addr: serverAddr, peers: gateway.ConvertToAddrInfoMap(peerAddrs)}, nil
+}
+
+// connect returns the peer the session is connected to for t, connecting to
+// the first available one, looked up on the DHT for services, if there is
+// none yet
+func (s *Session) connect(ctx context.Context, t *target) (peer.ID, error) {
+	if s.closed.Load() {
+		return "", ErrSessionClosed
+	}
+	s.mu.Lock()
+	id, ok := s.peers[t.addr]
+	s.mu.Unlock()
+	if ok && s.host.Network().Connectedness(id) == network.Connected {
+		return id, nil
+	}
+
+	addrInfoMap := t.peers
+	if t.serviceName != "" {
+		var err error
+		addrInfoMap, err = findServiceProviders(ctx, s.host, t.serviceName)
+		if err != nil {
+			s.cfg.logger.Error("Failed to find service providers", err)
+			return "", err
+		}
+	}
+
+	// Try connecting to peers in parallel
+	connectCtx, cancel := context.WithTimeout(ctx, s.connectTimeout)
+	id, err := pool.ConnectToFirstAvailablePeer(connectCtx, s.host, addrInfoMap, s.cfg.logger)
+	cancel()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to any peer: %w", dhost.PrivateNetworkError(s.host, err))
+	}
+	s.cfg.logger.Info("Successfully connected to peer", glog.LogFields{"peerID": id.String()})
+
+	s.mu.Lock()
+	s.peers[t.addr] = id
+	s.mu.Unlock()
+	return id, nil
+}
+
+// libp2pClient returns an HTTP client calling the peer of t over streams of
+// the session's pool
+func (s *Session) libp2pClient(t *target) *http.Client {
+	// Keep track of the current stream for reuse
+	var currentStream network.Stream
+
+	// Both http.Transport and http2.Transport can be used and works/tested, but http2 is preferred for HTTP/2.
+	transport := &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, tlsCfg *tls.Config) (net.Conn, error) {
+			// Ignore TLS, use libp2p dialer for h2c
+			peerID, err := s.connect(ctx, t)
+			if err != nil {
+				return nil, err
+			}
+			return dialWithPool(ctx, s.pool, s.protocolIDs, peerID, &currentStream)
+		},
+	}
+	return &http.Client{
+		Transport: tracing.Transport(resourceLimitTransport{transport}),
+	}
+}
+
+// newHTTPClient returns a client for http:// and https:// addresses over transport
+func newHTTPClient(transport *http2.Transport) *http.Client {
+	return &http.Client{
+		// Pass the caller's trace context on to the server
+		Transport: tracing.Transport(transport),
+	}
+}
+
+// namespaceURL appends the path prefix of namespace, if any, to serverAddr
+func namespaceURL(serverAddr, namespace string) string {
+	if namespace == "" {
+		return serverAddr
+	}
+	return strings.TrimSuffix(serverAddr, "/") + "/" + namespace
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	gv1 "github.com/omgolab/drpc/demo/gen/go/greeter/v1"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	"github.com/omgolab/drpc/pkg/core/leakcheck"
+	healthv1 "github.com/omgolab/drpc/pkg/drpc/proto/drpcext/grpc/health/v1"
+	"github.com/omgolab/drpc/pkg/drpc/server"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+type greeter struct {
+	gv1connect.UnimplementedGreeterServiceHandler
+}
+
+func (greeter) SayHello(_ context.Context, req *connect.Request[gv1.SayHelloRequest]) (*connect.Response[gv1.SayHelloResponse], error) {
+	return connect.NewResponse(&gv1.SayHelloResponse{Message: "Hello, " + req.Msg.Name}), nil
+}
+
+// newGreeterServer starts a greeter server on loopback libp2p and HTTP listeners
+func newGreeterServer(t *testing.T, ctx context.Context) *server.DRPCServer {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle(gv1connect.NewGreeterServiceHandler(greeter{}))
+	srv, err := server.New(ctx, mux,
+		server.WithLibP2POptions(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0")),
+		server.WithHTTPPort(0),
+		server.WithHealthService(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+func TestNewWithCloserOutlivesDialContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv := newGreeterServer(t, ctx)
+
+	logger, _ := glog.New()
+	dialCtx, cancelDial := context.WithTimeout(ctx, 5*time.Second)
+	c, closer, err := NewWithCloser(dialCtx, srv.P2PAddrs()[0], gv1connect.NewGreeterServiceClient,
+		WithLogger(logger), WithLibp2pOptions(libp2p.NoListenAddrs))
+	cancelDial()
+	if err != nil {
+		t.Fatalf("NewWithCloser failed: %v", err)
+	}
+	defer closer.Close()
+	if _, err := c.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: "later"})); err != nil {
+		t.Errorf("SayHello after the dial context ended failed: %v", err)
+	}
+}
+
+func TestDroppedClientsReleaseGoroutines(t *testing.T) {
+	leakcheck.Check(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	srv := newGreeterServer(t, ctx)
+	logger, _ := glog.New()
+	opts := []Option{WithLogger(logger), WithLibp2pOptions(libp2p.NoListenAddrs)}
+
+	for i := range 5 {
+		// Clients of New close with their context
+		clientCtx, cancelClient := context.WithCancel(ctx)
+		c, err := New(clientCtx, srv.P2PAddrs()[0], gv1connect.NewGreeterServiceClient, opts...)
+		if err != nil {
+			t.Fatalf("New #%d failed: %v", i, err)
+		}
+		if _, err := c.SayHello(clientCtx, connect.NewRequest(&gv1.SayHelloRequest{Name: "new"})); err != nil {
+			t.Fatalf("SayHello #%d failed: %v", i, err)
+		}
+		cancelClient()
+
+		// Clients of NewWithCloser close with their Closer
+		c, closer, err := NewWithCloser(ctx, srv.P2PAddrs()[0], gv1connect.NewGreeterServiceClient, opts...)
+		if err != nil {
+			t.Fatalf("NewWithCloser #%d failed: %v", i, err)
+		}
+		if _, err := c.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: "closer"})); err != nil {
+			t.Fatalf("SayHello #%d failed: %v", i, err)
+		}
+		if err := closer.Close(); err != nil {
+			t.Errorf("Close #%d failed: %v", i, err)
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv := newGreeterServer(t, ctx)
+
+	logger, _ := glog.New()
+	session, err := NewSession(ctx, WithLogger(logger), WithLibp2pOptions(libp2p.NoListenAddrs))
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	defer session.Close()
+
+	p2pAddr := srv.P2PAddrs()[0]
+	greeterClient, err := NewServiceClient(session, p2pAddr, gv1connect.NewGreeterServiceClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthClient, err := NewServiceClient(session, p2pAddr, server.NewHealthClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peers := session.Host().Network().Peers(); len(peers) != 0 {
+		t.Errorf("Session connected to %v before the first call", peers)
+	}
+
+	resp, err := greeterClient.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: "session"}))
+	if err != nil || resp.Msg.Message != "Hello, session" {
+		t.Fatalf("SayHello = %v, %v", resp, err)
+	}
+	if _, err := healthClient.Check(ctx, connect.NewRequest(&healthv1.HealthCheckRequest{})); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if conns := srv.P2PHost().Network().ConnsToPeer(session.Host().ID()); len(conns) != 1 {
+		t.Errorf("Server has %d connections to the session, want 1", len(conns))
+	}
+
+	httpClient, err := NewServiceClient(session, srv.HTTPAddr(), gv1connect.NewGreeterServiceClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := httpClient.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: "http"})); err != nil {
+		t.Errorf("SayHello over HTTP failed: %v", err)
+	}
+
+	if _, err := NewServiceClient(session, "not-an-address", gv1connect.NewGreeterServiceClient); err == nil {
+		t.Error("Expected an invalid address to be rejected")
+	}
+
+	if err := session.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if _, err := greeterClient.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: "closed"})); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("Call on a closed session returned %v, want ErrSessionClosed", err)
+	}
+}
@@ -0,0 +1,29 @@
+// Package drpc exposes request-scoped helpers for ConnectRPC handlers served by dRPC.
+package drpc
+
+import (
+	"context"
+
+	"github.com/omgolab/drpc/pkg/core"
+)
+
+// PeerInfo describes the caller of an RPC
+type PeerInfo = core.PeerInfo
+
+// EntryPath identifies the transport path that carried an RPC
+type EntryPath = core.EntryPath
+
+// Entry paths reported in PeerInfo.Entry
+const (
+	EntryLibp2p    = core.EntryLibp2p
+	EntryWebStream = core.EntryWebStream
+	EntryHTTP      = core.EntryHTTP
+	EntryGateway   = core.EntryGateway
+)
+
+// PeerFromContext returns the caller of the RPC being handled with ctx.
+// It reports false when ctx was not created by a dRPC server. EntryGateway
+// is claimed by the calling peer, so authorize callers on their ID instead.
+func PeerFromContext(ctx context.Context) (PeerInfo, bool) {
+	return core.PeerInfoFromContext(ctx)
+}
@@ -0,0 +1,1635 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: drpc/admin/v1/admin.proto
+
+package adminv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Direction int32
+
+const (
+	Direction_DIRECTION_UNSPECIFIED Direction = 0
+	Direction_DIRECTION_INBOUND     Direction = 1
+	Direction_DIRECTION_OUTBOUND    Direction = 2
+)
+
+// Enum value maps for Direction.
+var (
+	Direction_name = map[int32]string{
+		0: "DIRECTION_UNSPECIFIED",
+		1: "DIRECTION_INBOUND",
+		2: "DIRECTION_OUTBOUND",
+	}
+	Direction_value = map[string]int32{
+		"DIRECTION_UNSPECIFIED": 0,
+		"DIRECTION_INBOUND":     1,
+		"DIRECTION_OUTBOUND":    2,
+	}
+)
+
+func (x Direction) Enum() *Direction {
+	p := new(Direction)
+	*p = x
+	return p
+}
+
+func (x Direction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Direction) Descriptor() protoreflect.EnumDescriptor {
+	return file_drpc_admin_v1_admin_proto_enumTypes[0].Descriptor()
+}
+
+func (Direction) Type() protoreflect.EnumType {
+	return &file_drpc_admin_v1_admin_proto_enumTypes[0]
+}
+
+func (x Direction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Direction.Descriptor instead.
+func (Direction) EnumDescriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{0}
+}
+
+type ListConnectionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConnectionsRequest) Reset() {
+	*x = ListConnectionsRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConnectionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConnectionsRequest) ProtoMessage() {}
+
+func (x *ListConnectionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConnectionsRequest.ProtoReflect.Descriptor instead.
+func (*ListConnectionsRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListConnectionsRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+type ListConnectionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Peers         []*PeerConnections     `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConnectionsResponse) Reset() {
+	*x = ListConnectionsResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConnectionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConnectionsResponse) ProtoMessage() {}
+
+func (x *ListConnectionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConnectionsResponse.ProtoReflect.Descriptor instead.
+func (*ListConnectionsResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListConnectionsResponse) GetPeers() []*PeerConnections {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type PeerConnections struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Connections   []*Connection          `protobuf:"bytes,2,rep,name=connections,proto3" json:"connections,omitempty"`
+	Streams       []*ProtocolStreams     `protobuf:"bytes,3,rep,name=streams,proto3" json:"streams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeerConnections) Reset() {
+	*x = PeerConnections{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerConnections) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerConnections) ProtoMessage() {}
+
+func (x *PeerConnections) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerConnections.ProtoReflect.Descriptor instead.
+func (*PeerConnections) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PeerConnections) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *PeerConnections) GetConnections() []*Connection {
+	if x != nil {
+		return x.Connections
+	}
+	return nil
+}
+
+func (x *PeerConnections) GetStreams() []*ProtocolStreams {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+type Connection struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	LocalAddr     string                 `protobuf:"bytes,2,opt,name=local_addr,json=localAddr,proto3" json:"local_addr,omitempty"`
+	RemoteAddr    string                 `protobuf:"bytes,3,opt,name=remote_addr,json=remoteAddr,proto3" json:"remote_addr,omitempty"`
+	Direction     Direction              `protobuf:"varint,4,opt,name=direction,proto3,enum=drpc.admin.v1.Direction" json:"direction,omitempty"`
+	Limited       bool                   `protobuf:"varint,5,opt,name=limited,proto3" json:"limited,omitempty"`
+	Opened        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=opened,proto3" json:"opened,omitempty"`
+	Streams       int32                  `protobuf:"varint,7,opt,name=streams,proto3" json:"streams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Connection) Reset() {
+	*x = Connection{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Connection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Connection) ProtoMessage() {}
+
+func (x *Connection) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Connection.ProtoReflect.Descriptor instead.
+func (*Connection) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Connection) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Connection) GetLocalAddr() string {
+	if x != nil {
+		return x.LocalAddr
+	}
+	return ""
+}
+
+func (x *Connection) GetRemoteAddr() string {
+	if x != nil {
+		return x.RemoteAddr
+	}
+	return ""
+}
+
+func (x *Connection) GetDirection() Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+func (x *Connection) GetLimited() bool {
+	if x != nil {
+		return x.Limited
+	}
+	return false
+}
+
+func (x *Connection) GetOpened() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Opened
+	}
+	return nil
+}
+
+func (x *Connection) GetStreams() int32 {
+	if x != nil {
+		return x.Streams
+	}
+	return 0
+}
+
+type ProtocolStreams struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Protocol      string                 `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Inbound       int32                  `protobuf:"varint,2,opt,name=inbound,proto3" json:"inbound,omitempty"`
+	Outbound      int32                  `protobuf:"varint,3,opt,name=outbound,proto3" json:"outbound,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProtocolStreams) Reset() {
+	*x = ProtocolStreams{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProtocolStreams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtocolStreams) ProtoMessage() {}
+
+func (x *ProtocolStreams) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtocolStreams.ProtoReflect.Descriptor instead.
+func (*ProtocolStreams) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProtocolStreams) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ProtocolStreams) GetInbound() int32 {
+	if x != nil {
+		return x.Inbound
+	}
+	return 0
+}
+
+func (x *ProtocolStreams) GetOutbound() int32 {
+	if x != nil {
+		return x.Outbound
+	}
+	return 0
+}
+
+type GetPoolStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPoolStatsRequest) Reset() {
+	*x = GetPoolStatsRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPoolStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPoolStatsRequest) ProtoMessage() {}
+
+func (x *GetPoolStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPoolStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetPoolStatsRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{5}
+}
+
+type GetPoolStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Shards        []*PoolShard           `protobuf:"bytes,1,rep,name=shards,proto3" json:"shards,omitempty"`
+	Reused        int64                  `protobuf:"varint,2,opt,name=reused,proto3" json:"reused,omitempty"`
+	Created       int64                  `protobuf:"varint,3,opt,name=created,proto3" json:"created,omitempty"`
+	ReuseRatio    float64                `protobuf:"fixed64,4,opt,name=reuse_ratio,json=reuseRatio,proto3" json:"reuse_ratio,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPoolStatsResponse) Reset() {
+	*x = GetPoolStatsResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPoolStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPoolStatsResponse) ProtoMessage() {}
+
+func (x *GetPoolStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPoolStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetPoolStatsResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPoolStatsResponse) GetShards() []*PoolShard {
+	if x != nil {
+		return x.Shards
+	}
+	return nil
+}
+
+func (x *GetPoolStatsResponse) GetReused() int64 {
+	if x != nil {
+		return x.Reused
+	}
+	return 0
+}
+
+func (x *GetPoolStatsResponse) GetCreated() int64 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *GetPoolStatsResponse) GetReuseRatio() float64 {
+	if x != nil {
+		return x.ReuseRatio
+	}
+	return 0
+}
+
+type PoolShard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Idle          int32                  `protobuf:"varint,1,opt,name=idle,proto3" json:"idle,omitempty"`
+	Active        int32                  `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PoolShard) Reset() {
+	*x = PoolShard{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolShard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolShard) ProtoMessage() {}
+
+func (x *PoolShard) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolShard.ProtoReflect.Descriptor instead.
+func (*PoolShard) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PoolShard) GetIdle() int32 {
+	if x != nil {
+		return x.Idle
+	}
+	return 0
+}
+
+func (x *PoolShard) GetActive() int32 {
+	if x != nil {
+		return x.Active
+	}
+	return 0
+}
+
+type ListDiscoveredPeersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDiscoveredPeersRequest) Reset() {
+	*x = ListDiscoveredPeersRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDiscoveredPeersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDiscoveredPeersRequest) ProtoMessage() {}
+
+func (x *ListDiscoveredPeersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDiscoveredPeersRequest.ProtoReflect.Descriptor instead.
+func (*ListDiscoveredPeersRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{8}
+}
+
+type ListDiscoveredPeersResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Peers          []*DiscoveredPeer      `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	DiscoveryCache []*DiscoveryCacheEntry `protobuf:"bytes,2,rep,name=discovery_cache,json=discoveryCache,proto3" json:"discovery_cache,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListDiscoveredPeersResponse) Reset() {
+	*x = ListDiscoveredPeersResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDiscoveredPeersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDiscoveredPeersResponse) ProtoMessage() {}
+
+func (x *ListDiscoveredPeersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDiscoveredPeersResponse.ProtoReflect.Descriptor instead.
+func (*ListDiscoveredPeersResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListDiscoveredPeersResponse) GetPeers() []*DiscoveredPeer {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *ListDiscoveredPeersResponse) GetDiscoveryCache() []*DiscoveryCacheEntry {
+	if x != nil {
+		return x.DiscoveryCache
+	}
+	return nil
+}
+
+type DiscoveredPeer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Addrs         []string               `protobuf:"bytes,2,rep,name=addrs,proto3" json:"addrs,omitempty"`
+	Connected     bool                   `protobuf:"varint,3,opt,name=connected,proto3" json:"connected,omitempty"`
+	Limited       bool                   `protobuf:"varint,4,opt,name=limited,proto3" json:"limited,omitempty"`
+	Protocols     []string               `protobuf:"bytes,5,rep,name=protocols,proto3" json:"protocols,omitempty"`
+	AddressBook   *AddressBookEntry      `protobuf:"bytes,6,opt,name=address_book,json=addressBook,proto3" json:"address_book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiscoveredPeer) Reset() {
+	*x = DiscoveredPeer{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiscoveredPeer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiscoveredPeer) ProtoMessage() {}
+
+func (x *DiscoveredPeer) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiscoveredPeer.ProtoReflect.Descriptor instead.
+func (*DiscoveredPeer) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DiscoveredPeer) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *DiscoveredPeer) GetAddrs() []string {
+	if x != nil {
+		return x.Addrs
+	}
+	return nil
+}
+
+func (x *DiscoveredPeer) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *DiscoveredPeer) GetLimited() bool {
+	if x != nil {
+		return x.Limited
+	}
+	return false
+}
+
+func (x *DiscoveredPeer) GetProtocols() []string {
+	if x != nil {
+		return x.Protocols
+	}
+	return nil
+}
+
+func (x *DiscoveredPeer) GetAddressBook() *AddressBookEntry {
+	if x != nil {
+		return x.AddressBook
+	}
+	return nil
+}
+
+type AddressBookEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FirstSeen     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastConnected *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=last_connected,json=lastConnected,proto3" json:"last_connected,omitempty"`
+	Connections   int32                  `protobuf:"varint,3,opt,name=connections,proto3" json:"connections,omitempty"`
+	Uptime        *durationpb.Duration   `protobuf:"bytes,4,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Latency       *durationpb.Duration   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
+	Failures      int32                  `protobuf:"varint,6,opt,name=failures,proto3" json:"failures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddressBookEntry) Reset() {
+	*x = AddressBookEntry{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddressBookEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddressBookEntry) ProtoMessage() {}
+
+func (x *AddressBookEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddressBookEntry.ProtoReflect.Descriptor instead.
+func (*AddressBookEntry) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AddressBookEntry) GetFirstSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return nil
+}
+
+func (x *AddressBookEntry) GetLastConnected() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastConnected
+	}
+	return nil
+}
+
+func (x *AddressBookEntry) GetConnections() int32 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *AddressBookEntry) GetUptime() *durationpb.Duration {
+	if x != nil {
+		return x.Uptime
+	}
+	return nil
+}
+
+func (x *AddressBookEntry) GetLatency() *durationpb.Duration {
+	if x != nil {
+		return x.Latency
+	}
+	return nil
+}
+
+func (x *AddressBookEntry) GetFailures() int32 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+type DiscoveryCacheEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Addrs         []string               `protobuf:"bytes,2,rep,name=addrs,proto3" json:"addrs,omitempty"`
+	Discovered    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=discovered,proto3" json:"discovered,omitempty"`
+	Attempts      int32                  `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Expired       bool                   `protobuf:"varint,5,opt,name=expired,proto3" json:"expired,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiscoveryCacheEntry) Reset() {
+	*x = DiscoveryCacheEntry{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiscoveryCacheEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiscoveryCacheEntry) ProtoMessage() {}
+
+func (x *DiscoveryCacheEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiscoveryCacheEntry.ProtoReflect.Descriptor instead.
+func (*DiscoveryCacheEntry) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DiscoveryCacheEntry) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *DiscoveryCacheEntry) GetAddrs() []string {
+	if x != nil {
+		return x.Addrs
+	}
+	return nil
+}
+
+func (x *DiscoveryCacheEntry) GetDiscovered() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Discovered
+	}
+	return nil
+}
+
+func (x *DiscoveryCacheEntry) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *DiscoveryCacheEntry) GetExpired() bool {
+	if x != nil {
+		return x.Expired
+	}
+	return false
+}
+
+type DisconnectPeerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisconnectPeerRequest) Reset() {
+	*x = DisconnectPeerRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisconnectPeerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisconnectPeerRequest) ProtoMessage() {}
+
+func (x *DisconnectPeerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisconnectPeerRequest.ProtoReflect.Descriptor instead.
+func (*DisconnectPeerRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DisconnectPeerRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+type DisconnectPeerResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ClosedConnections int32                  `protobuf:"varint,1,opt,name=closed_connections,json=closedConnections,proto3" json:"closed_connections,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *DisconnectPeerResponse) Reset() {
+	*x = DisconnectPeerResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisconnectPeerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisconnectPeerResponse) ProtoMessage() {}
+
+func (x *DisconnectPeerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisconnectPeerResponse.ProtoReflect.Descriptor instead.
+func (*DisconnectPeerResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DisconnectPeerResponse) GetClosedConnections() int32 {
+	if x != nil {
+		return x.ClosedConnections
+	}
+	return 0
+}
+
+type BanPeerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BanPeerRequest) Reset() {
+	*x = BanPeerRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BanPeerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BanPeerRequest) ProtoMessage() {}
+
+func (x *BanPeerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BanPeerRequest.ProtoReflect.Descriptor instead.
+func (*BanPeerRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BanPeerRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *BanPeerRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type BanPeerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BanPeerResponse) Reset() {
+	*x = BanPeerResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BanPeerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BanPeerResponse) ProtoMessage() {}
+
+func (x *BanPeerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BanPeerResponse.ProtoReflect.Descriptor instead.
+func (*BanPeerResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{16}
+}
+
+type UnbanPeerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnbanPeerRequest) Reset() {
+	*x = UnbanPeerRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnbanPeerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnbanPeerRequest) ProtoMessage() {}
+
+func (x *UnbanPeerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnbanPeerRequest.ProtoReflect.Descriptor instead.
+func (*UnbanPeerRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UnbanPeerRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+type UnbanPeerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Banned        bool                   `protobuf:"varint,1,opt,name=banned,proto3" json:"banned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnbanPeerResponse) Reset() {
+	*x = UnbanPeerResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnbanPeerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnbanPeerResponse) ProtoMessage() {}
+
+func (x *UnbanPeerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnbanPeerResponse.ProtoReflect.Descriptor instead.
+func (*UnbanPeerResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UnbanPeerResponse) GetBanned() bool {
+	if x != nil {
+		return x.Banned
+	}
+	return false
+}
+
+type ListBannedPeersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBannedPeersRequest) Reset() {
+	*x = ListBannedPeersRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBannedPeersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBannedPeersRequest) ProtoMessage() {}
+
+func (x *ListBannedPeersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBannedPeersRequest.ProtoReflect.Descriptor instead.
+func (*ListBannedPeersRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{19}
+}
+
+type ListBannedPeersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Peers         []*BannedPeer          `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBannedPeersResponse) Reset() {
+	*x = ListBannedPeersResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBannedPeersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBannedPeersResponse) ProtoMessage() {}
+
+func (x *ListBannedPeersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBannedPeersResponse.ProtoReflect.Descriptor instead.
+func (*ListBannedPeersResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListBannedPeersResponse) GetPeers() []*BannedPeer {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type BannedPeer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Expires       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires,proto3" json:"expires,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BannedPeer) Reset() {
+	*x = BannedPeer{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BannedPeer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BannedPeer) ProtoMessage() {}
+
+func (x *BannedPeer) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BannedPeer.ProtoReflect.Descriptor instead.
+func (*BannedPeer) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BannedPeer) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *BannedPeer) GetExpires() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Expires
+	}
+	return nil
+}
+
+type TriggerDiscoveryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerDiscoveryRequest) Reset() {
+	*x = TriggerDiscoveryRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerDiscoveryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerDiscoveryRequest) ProtoMessage() {}
+
+func (x *TriggerDiscoveryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerDiscoveryRequest.ProtoReflect.Descriptor instead.
+func (*TriggerDiscoveryRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{22}
+}
+
+type TriggerDiscoveryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerDiscoveryResponse) Reset() {
+	*x = TriggerDiscoveryResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerDiscoveryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerDiscoveryResponse) ProtoMessage() {}
+
+func (x *TriggerDiscoveryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerDiscoveryResponse.ProtoReflect.Descriptor instead.
+func (*TriggerDiscoveryResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{23}
+}
+
+type GetEffectiveConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEffectiveConfigRequest) Reset() {
+	*x = GetEffectiveConfigRequest{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEffectiveConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEffectiveConfigRequest) ProtoMessage() {}
+
+func (x *GetEffectiveConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEffectiveConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetEffectiveConfigRequest) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetEffectiveConfigRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type GetEffectiveConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        string                 `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEffectiveConfigResponse) Reset() {
+	*x = GetEffectiveConfigResponse{}
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEffectiveConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEffectiveConfigResponse) ProtoMessage() {}
+
+func (x *GetEffectiveConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_drpc_admin_v1_admin_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEffectiveConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetEffectiveConfigResponse) Descriptor() ([]byte, []int) {
+	return file_drpc_admin_v1_admin_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetEffectiveConfigResponse) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+var File_drpc_admin_v1_admin_proto protoreflect.FileDescriptor
+
+const file_drpc_admin_v1_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x19drpc/admin/v1/admin.proto\x12\rdrpc.admin.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"1\n" +
+	"\x16ListConnectionsRequest\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\"O\n" +
+	"\x17ListConnectionsResponse\x124\n" +
+	"\x05peers\x18\x01 \x03(\v2\x1e.drpc.admin.v1.PeerConnectionsR\x05peers\"\xa1\x01\n" +
+	"\x0fPeerConnections\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x12;\n" +
+	"\vconnections\x18\x02 \x03(\v2\x19.drpc.admin.v1.ConnectionR\vconnections\x128\n" +
+	"\astreams\x18\x03 \x03(\v2\x1e.drpc.admin.v1.ProtocolStreamsR\astreams\"\xfc\x01\n" +
+	"\n" +
+	"Connection\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"local_addr\x18\x02 \x01(\tR\tlocalAddr\x12\x1f\n" +
+	"\vremote_addr\x18\x03 \x01(\tR\n" +
+	"remoteAddr\x126\n" +
+	"\tdirection\x18\x04 \x01(\x0e2\x18.drpc.admin.v1.DirectionR\tdirection\x12\x18\n" +
+	"\alimited\x18\x05 \x01(\bR\alimited\x122\n" +
+	"\x06opened\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x06opened\x12\x18\n" +
+	"\astreams\x18\a \x01(\x05R\astreams\"c\n" +
+	"\x0fProtocolStreams\x12\x1a\n" +
+	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12\x18\n" +
+	"\ainbound\x18\x02 \x01(\x05R\ainbound\x12\x1a\n" +
+	"\boutbound\x18\x03 \x01(\x05R\boutbound\"\x15\n" +
+	"\x13GetPoolStatsRequest\"\x9b\x01\n" +
+	"\x14GetPoolStatsResponse\x120\n" +
+	"\x06shards\x18\x01 \x03(\v2\x18.drpc.admin.v1.PoolShardR\x06shards\x12\x16\n" +
+	"\x06reused\x18\x02 \x01(\x03R\x06reused\x12\x18\n" +
+	"\acreated\x18\x03 \x01(\x03R\acreated\x12\x1f\n" +
+	"\vreuse_ratio\x18\x04 \x01(\x01R\n" +
+	"reuseRatio\"7\n" +
+	"\tPoolShard\x12\x12\n" +
+	"\x04idle\x18\x01 \x01(\x05R\x04idle\x12\x16\n" +
+	"\x06active\x18\x02 \x01(\x05R\x06active\"\x1c\n" +
+	"\x1aListDiscoveredPeersRequest\"\x9f\x01\n" +
+	"\x1bListDiscoveredPeersResponse\x123\n" +
+	"\x05peers\x18\x01 \x03(\v2\x1d.drpc.admin.v1.DiscoveredPeerR\x05peers\x12K\n" +
+	"\x0fdiscovery_cache\x18\x02 \x03(\v2\".drpc.admin.v1.DiscoveryCacheEntryR\x0ediscoveryCache\"\xd9\x01\n" +
+	"\x0eDiscoveredPeer\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x12\x14\n" +
+	"\x05addrs\x18\x02 \x03(\tR\x05addrs\x12\x1c\n" +
+	"\tconnected\x18\x03 \x01(\bR\tconnected\x12\x18\n" +
+	"\alimited\x18\x04 \x01(\bR\alimited\x12\x1c\n" +
+	"\tprotocols\x18\x05 \x03(\tR\tprotocols\x12B\n" +
+	"\faddress_book\x18\x06 \x01(\v2\x1f.drpc.admin.v1.AddressBookEntryR\vaddressBook\"\xb6\x02\n" +
+	"\x10AddressBookEntry\x129\n" +
+	"\n" +
+	"first_seen\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tfirstSeen\x12A\n" +
+	"\x0elast_connected\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\rlastConnected\x12 \n" +
+	"\vconnections\x18\x03 \x01(\x05R\vconnections\x121\n" +
+	"\x06uptime\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x06uptime\x123\n" +
+	"\alatency\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\alatency\x12\x1a\n" +
+	"\bfailures\x18\x06 \x01(\x05R\bfailures\"\xb6\x01\n" +
+	"\x13DiscoveryCacheEntry\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x12\x14\n" +
+	"\x05addrs\x18\x02 \x03(\tR\x05addrs\x12:\n" +
+	"\n" +
+	"discovered\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"discovered\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\x05R\battempts\x12\x18\n" +
+	"\aexpired\x18\x05 \x01(\bR\aexpired\"0\n" +
+	"\x15DisconnectPeerRequest\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\"G\n" +
+	"\x16DisconnectPeerResponse\x12-\n" +
+	"\x12closed_connections\x18\x01 \x01(\x05R\x11closedConnections\"`\n" +
+	"\x0eBanPeerRequest\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\"\x11\n" +
+	"\x0fBanPeerResponse\"+\n" +
+	"\x10UnbanPeerRequest\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\"+\n" +
+	"\x11UnbanPeerResponse\x12\x16\n" +
+	"\x06banned\x18\x01 \x01(\bR\x06banned\"\x18\n" +
+	"\x16ListBannedPeersRequest\"J\n" +
+	"\x17ListBannedPeersResponse\x12/\n" +
+	"\x05peers\x18\x01 \x03(\v2\x19.drpc.admin.v1.BannedPeerR\x05peers\"[\n" +
+	"\n" +
+	"BannedPeer\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x124\n" +
+	"\aexpires\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\aexpires\"\x19\n" +
+	"\x17TriggerDiscoveryRequest\"\x1a\n" +
+	"\x18TriggerDiscoveryResponse\"3\n" +
+	"\x19GetEffectiveConfigRequest\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\"4\n" +
+	"\x1aGetEffectiveConfigResponse\x12\x16\n" +
+	"\x06config\x18\x01 \x01(\tR\x06config*U\n" +
+	"\tDirection\x12\x19\n" +
+	"\x15DIRECTION_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11DIRECTION_INBOUND\x10\x01\x12\x16\n" +
+	"\x12DIRECTION_OUTBOUND\x10\x022\xe2\x06\n" +
+	"\fAdminService\x12`\n" +
+	"\x0fListConnections\x12%.drpc.admin.v1.ListConnectionsRequest\x1a&.drpc.admin.v1.ListConnectionsResponse\x12W\n" +
+	"\fGetPoolStats\x12\".drpc.admin.v1.GetPoolStatsRequest\x1a#.drpc.admin.v1.GetPoolStatsResponse\x12l\n" +
+	"\x13ListDiscoveredPeers\x12).drpc.admin.v1.ListDiscoveredPeersRequest\x1a*.drpc.admin.v1.ListDiscoveredPeersResponse\x12]\n" +
+	"\x0eDisconnectPeer\x12$.drpc.admin.v1.DisconnectPeerRequest\x1a%.drpc.admin.v1.DisconnectPeerResponse\x12H\n" +
+	"\aBanPeer\x12\x1d.drpc.admin.v1.BanPeerRequest\x1a\x1e.drpc.admin.v1.BanPeerResponse\x12N\n" +
+	"\tUnbanPeer\x12\x1f.drpc.admin.v1.UnbanPeerRequest\x1a .drpc.admin.v1.UnbanPeerResponse\x12`\n" +
+	"\x0fListBannedPeers\x12%.drpc.admin.v1.ListBannedPeersRequest\x1a&.drpc.admin.v1.ListBannedPeersResponse\x12c\n" +
+	"\x10TriggerDiscovery\x12&.drpc.admin.v1.TriggerDiscoveryRequest\x1a'.drpc.admin.v1.TriggerDiscoveryResponse\x12i\n" +
+	"\x12GetEffectiveConfig\x12(.drpc.admin.v1.GetEffectiveConfigRequest\x1a).drpc.admin.v1.GetEffectiveConfigResponseB>Z<github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1;adminv1b\x06proto3"
+
+var (
+	file_drpc_admin_v1_admin_proto_rawDescOnce sync.Once
+	file_drpc_admin_v1_admin_proto_rawDescData []byte
+)
+
+func file_drpc_admin_v1_admin_proto_rawDescGZIP() []byte {
+	file_drpc_admin_v1_admin_proto_rawDescOnce.Do(func() {
+		file_drpc_admin_v1_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_drpc_admin_v1_admin_proto_rawDesc), len(file_drpc_admin_v1_admin_proto_rawDesc)))
+	})
+	return file_drpc_admin_v1_admin_proto_rawDescData
+}
+
+var file_drpc_admin_v1_admin_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_drpc_admin_v1_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_drpc_admin_v1_admin_proto_goTypes = []any{
+	(Direction)(0),                      // 0: drpc.admin.v1.Direction
+	(*ListConnectionsRequest)(nil),      // 1: drpc.admin.v1.ListConnectionsRequest
+	(*ListConnectionsResponse)(nil),     // 2: drpc.admin.v1.ListConnectionsResponse
+	(*PeerConnections)(nil),             // 3: drpc.admin.v1.PeerConnections
+	(*Connection)(nil),                  // 4: drpc.admin.v1.Connection
+	(*ProtocolStreams)(nil),             // 5: drpc.admin.v1.ProtocolStreams
+	(*GetPoolStatsRequest)(nil),         // 6: drpc.admin.v1.GetPoolStatsRequest
+	(*GetPoolStatsResponse)(nil),        // 7: drpc.admin.v1.GetPoolStatsResponse
+	(*PoolShard)(nil),                   // 8: drpc.admin.v1.PoolShard
+	(*ListDiscoveredPeersRequest)(nil),  // 9: drpc.admin.v1.ListDiscoveredPeersRequest
+	(*ListDiscoveredPeersResponse)(nil), // 10: drpc.admin.v1.ListDiscoveredPeersResponse
+	(*DiscoveredPeer)(nil),              // 11: drpc.admin.v1.DiscoveredPeer
+	(*AddressBookEntry)(nil),            // 12: drpc.admin.v1.AddressBookEntry
+	(*DiscoveryCacheEntry)(nil),         // 13: drpc.admin.v1.DiscoveryCacheEntry
+	(*DisconnectPeerRequest)(nil),       // 14: drpc.admin.v1.DisconnectPeerRequest
+	(*DisconnectPeerResponse)(nil),      // 15: drpc.admin.v1.DisconnectPeerResponse
+	(*BanPeerRequest)(nil),              // 16: drpc.admin.v1.BanPeerRequest
+	(*BanPeerResponse)(nil),             // 17: drpc.admin.v1.BanPeerResponse
+	(*UnbanPeerRequest)(nil),            // 18: drpc.admin.v1.UnbanPeerRequest
+	(*UnbanPeerResponse)(nil),           // 19: drpc.admin.v1.UnbanPeerResponse
+	(*ListBannedPeersRequest)(nil),      // 20: drpc.admin.v1.ListBannedPeersRequest
+	(*ListBannedPeersResponse)(nil),     // 21: drpc.admin.v1.ListBannedPeersResponse
+	(*BannedPeer)(nil),                  // 22: drpc.admin.v1.BannedPeer
+	(*TriggerDiscoveryRequest)(nil),     // 23: drpc.admin.v1.TriggerDiscoveryRequest
+	(*TriggerDiscoveryResponse)(nil),    // 24: drpc.admin.v1.TriggerDiscoveryResponse
+	(*GetEffectiveConfigRequest)(nil),   // 25: drpc.admin.v1.GetEffectiveConfigRequest
+	(*GetEffectiveConfigResponse)(nil),  // 26: drpc.admin.v1.GetEffectiveConfigResponse
+	(*timestamppb.Timestamp)(nil),       // 27: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),         // 28: google.protobuf.Duration
+}
+var file_drpc_admin_v1_admin_proto_depIdxs = []int32{
+	3,  // 0: drpc.admin.v1.ListConnectionsResponse.peers:type_name -> drpc.admin.v1.PeerConnections
+	4,  // 1: drpc.admin.v1.PeerConnections.connections:type_name -> drpc.admin.v1.Connection
+	5,  // 2: drpc.admin.v1.PeerConnections.streams:type_name -> drpc.admin.v1.ProtocolStreams
+	0,  // 3: drpc.admin.v1.Connection.direction:type_name -> drpc.admin.v1.Direction
+	27, // 4: drpc.admin.v1.Connection.opened:type_name -> google.protobuf.Timestamp
+	8,  // 5: drpc.admin.v1.GetPoolStatsResponse.shards:type_name -> drpc.admin.v1.PoolShard
+	11, // 6: drpc.admin.v1.ListDiscoveredPeersResponse.peers:type_name -> drpc.admin.v1.DiscoveredPeer
+	13, // 7: drpc.admin.v1.ListDiscoveredPeersResponse.discovery_cache:type_name -> drpc.admin.v1.DiscoveryCacheEntry
+	12, // 8: drpc.admin.v1.DiscoveredPeer.address_book:type_name -> drpc.admin.v1.AddressBookEntry
+	27, // 9: drpc.admin.v1.AddressBookEntry.first_seen:type_name -> google.protobuf.Timestamp
+	27, // 10: drpc.admin.v1.AddressBookEntry.last_connected:type_name -> google.protobuf.Timestamp
+	28, // 11: drpc.admin.v1.AddressBookEntry.uptime:type_name -> google.protobuf.Duration
+	28, // 12: drpc.admin.v1.AddressBookEntry.latency:type_name -> google.protobuf.Duration
+	27, // 13: drpc.admin.v1.DiscoveryCacheEntry.discovered:type_name -> google.protobuf.Timestamp
+	28, // 14: drpc.admin.v1.BanPeerRequest.duration:type_name -> google.protobuf.Duration
+	22, // 15: drpc.admin.v1.ListBannedPeersResponse.peers:type_name -> drpc.admin.v1.BannedPeer
+	27, // 16: drpc.admin.v1.BannedPeer.expires:type_name -> google.protobuf.Timestamp
+	1,  // 17: drpc.admin.v1.AdminService.ListConnections:input_type -> drpc.admin.v1.ListConnectionsRequest
+	6,  // 18: drpc.admin.v1.AdminService.GetPoolStats:input_type -> drpc.admin.v1.GetPoolStatsRequest
+	9,  // 19: drpc.admin.v1.AdminService.ListDiscoveredPeers:input_type -> drpc.admin.v1.ListDiscoveredPeersRequest
+	14, // 20: drpc.admin.v1.AdminService.DisconnectPeer:input_type -> drpc.admin.v1.DisconnectPeerRequest
+	16, // 21: drpc.admin.v1.AdminService.BanPeer:input_type -> drpc.admin.v1.BanPeerRequest
+	18, // 22: drpc.admin.v1.AdminService.UnbanPeer:input_type -> drpc.admin.v1.UnbanPeerRequest
+	20, // 23: drpc.admin.v1.AdminService.ListBannedPeers:input_type -> drpc.admin.v1.ListBannedPeersRequest
+	23, // 24: drpc.admin.v1.AdminService.TriggerDiscovery:input_type -> drpc.admin.v1.TriggerDiscoveryRequest
+	25, // 25: drpc.admin.v1.AdminService.GetEffectiveConfig:input_type -> drpc.admin.v1.GetEffectiveConfigRequest
+	2,  // 26: drpc.admin.v1.AdminService.ListConnections:output_type -> drpc.admin.v1.ListConnectionsResponse
+	7,  // 27: drpc.admin.v1.AdminService.GetPoolStats:output_type -> drpc.admin.v1.GetPoolStatsResponse
+	10, // 28: drpc.admin.v1.AdminService.ListDiscoveredPeers:output_type -> drpc.admin.v1.ListDiscoveredPeersResponse
+	15, // 29: drpc.admin.v1.AdminService.DisconnectPeer:output_type -> drpc.admin.v1.DisconnectPeerResponse
+	17, // 30: drpc.admin.v1.AdminService.BanPeer:output_type -> drpc.admin.v1.BanPeerResponse
+	19, // 31: drpc.admin.v1.AdminService.UnbanPeer:output_type -> drpc.admin.v1.UnbanPeerResponse
+	21, // 32: drpc.admin.v1.AdminService.ListBannedPeers:output_type -> drpc.admin.v1.ListBannedPeersResponse
+	24, // 33: drpc.admin.v1.AdminService.TriggerDiscovery:output_type -> drpc.admin.v1.TriggerDiscoveryResponse
+	26, // 34: drpc.admin.v1.AdminService.GetEffectiveConfig:output_type -> drpc.admin.v1.GetEffectiveConfigResponse
+	26, // [26:35] is the sub-list for method output_type
+	17, // [17:26] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_drpc_admin_v1_admin_proto_init() }
+func file_drpc_admin_v1_admin_proto_init() {
+	if File_drpc_admin_v1_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_drpc_admin_v1_admin_proto_rawDesc), len(file_drpc_admin_v1_admin_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   26,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_drpc_admin_v1_admin_proto_goTypes,
+		DependencyIndexes: file_drpc_admin_v1_admin_proto_depIdxs,
+		EnumInfos:         file_drpc_admin_v1_admin_proto_enumTypes,
+		MessageInfos:      file_drpc_admin_v1_admin_proto_msgTypes,
+	}.Build()
+	File_drpc_admin_v1_admin_proto = out.File
+	file_drpc_admin_v1_admin_proto_goTypes = nil
+	file_drpc_admin_v1_admin_proto_depIdxs = nil
+}
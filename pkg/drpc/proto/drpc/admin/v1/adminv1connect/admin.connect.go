@@ -0,0 +1,339 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: drpc/admin/v1/admin.proto
+
+package adminv1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	v1 "github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// AdminServiceName is the fully-qualified name of the AdminService service.
+	AdminServiceName = "drpc.admin.v1.AdminService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// AdminServiceListConnectionsProcedure is the fully-qualified name of the AdminService's
+	// ListConnections RPC.
+	AdminServiceListConnectionsProcedure = "/drpc.admin.v1.AdminService/ListConnections"
+	// AdminServiceGetPoolStatsProcedure is the fully-qualified name of the AdminService's GetPoolStats
+	// RPC.
+	AdminServiceGetPoolStatsProcedure = "/drpc.admin.v1.AdminService/GetPoolStats"
+	// AdminServiceListDiscoveredPeersProcedure is the fully-qualified name of the AdminService's
+	// ListDiscoveredPeers RPC.
+	AdminServiceListDiscoveredPeersProcedure = "/drpc.admin.v1.AdminService/ListDiscoveredPeers"
+	// AdminServiceDisconnectPeerProcedure is the fully-qualified name of the AdminService's
+	// DisconnectPeer RPC.
+	AdminServiceDisconnectPeerProcedure = "/drpc.admin.v1.AdminService/DisconnectPeer"
+	// AdminServiceBanPeerProcedure is the fully-qualified name of the AdminService's BanPeer RPC.
+	AdminServiceBanPeerProcedure = "/drpc.admin.v1.AdminService/BanPeer"
+	// AdminServiceUnbanPeerProcedure is the fully-qualified name of the AdminService's UnbanPeer RPC.
+	AdminServiceUnbanPeerProcedure = "/drpc.admin.v1.AdminService/UnbanPeer"
+	// AdminServiceListBannedPeersProcedure is the fully-qualified name of the AdminService's
+	// ListBannedPeers RPC.
+	AdminServiceListBannedPeersProcedure = "/drpc.admin.v1.AdminService/ListBannedPeers"
+	// AdminServiceTriggerDiscoveryProcedure is the fully-qualified name of the AdminService's
+	// TriggerDiscovery RPC.
+	AdminServiceTriggerDiscoveryProcedure = "/drpc.admin.v1.AdminService/TriggerDiscovery"
+	// AdminServiceGetEffectiveConfigProcedure is the fully-qualified name of the AdminService's
+	// GetEffectiveConfig RPC.
+	AdminServiceGetEffectiveConfigProcedure = "/drpc.admin.v1.AdminService/GetEffectiveConfig"
+)
+
+// AdminServiceClient is a client for the drpc.admin.v1.AdminService service.
+type AdminServiceClient interface {
+	ListConnections(context.Context, *connect.Request[v1.ListConnectionsRequest]) (*connect.Response[v1.ListConnectionsResponse], error)
+	GetPoolStats(context.Context, *connect.Request[v1.GetPoolStatsRequest]) (*connect.Response[v1.GetPoolStatsResponse], error)
+	ListDiscoveredPeers(context.Context, *connect.Request[v1.ListDiscoveredPeersRequest]) (*connect.Response[v1.ListDiscoveredPeersResponse], error)
+	DisconnectPeer(context.Context, *connect.Request[v1.DisconnectPeerRequest]) (*connect.Response[v1.DisconnectPeerResponse], error)
+	BanPeer(context.Context, *connect.Request[v1.BanPeerRequest]) (*connect.Response[v1.BanPeerResponse], error)
+	UnbanPeer(context.Context, *connect.Request[v1.UnbanPeerRequest]) (*connect.Response[v1.UnbanPeerResponse], error)
+	ListBannedPeers(context.Context, *connect.Request[v1.ListBannedPeersRequest]) (*connect.Response[v1.ListBannedPeersResponse], error)
+	TriggerDiscovery(context.Context, *connect.Request[v1.TriggerDiscoveryRequest]) (*connect.Response[v1.TriggerDiscoveryResponse], error)
+	GetEffectiveConfig(context.Context, *connect.Request[v1.GetEffectiveConfigRequest]) (*connect.Response[v1.GetEffectiveConfigResponse], error)
+}
+
+// NewAdminServiceClient constructs a client for the drpc.admin.v1.AdminService service. By default,
+// it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and
+// sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC()
+// or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAdminServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) AdminServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	adminServiceMethods := v1.File_drpc_admin_v1_admin_proto.Services().ByName("AdminService").Methods()
+	return &adminServiceClient{
+		listConnections: connect.NewClient[v1.ListConnectionsRequest, v1.ListConnectionsResponse](
+			httpClient,
+			baseURL+AdminServiceListConnectionsProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("ListConnections")),
+			connect.WithClientOptions(opts...),
+		),
+		getPoolStats: connect.NewClient[v1.GetPoolStatsRequest, v1.GetPoolStatsResponse](
+			httpClient,
+			baseURL+AdminServiceGetPoolStatsProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("GetPoolStats")),
+			connect.WithClientOptions(opts...),
+		),
+		listDiscoveredPeers: connect.NewClient[v1.ListDiscoveredPeersRequest, v1.ListDiscoveredPeersResponse](
+			httpClient,
+			baseURL+AdminServiceListDiscoveredPeersProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("ListDiscoveredPeers")),
+			connect.WithClientOptions(opts...),
+		),
+		disconnectPeer: connect.NewClient[v1.DisconnectPeerRequest, v1.DisconnectPeerResponse](
+			httpClient,
+			baseURL+AdminServiceDisconnectPeerProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("DisconnectPeer")),
+			connect.WithClientOptions(opts...),
+		),
+		banPeer: connect.NewClient[v1.BanPeerRequest, v1.BanPeerResponse](
+			httpClient,
+			baseURL+AdminServiceBanPeerProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("BanPeer")),
+			connect.WithClientOptions(opts...),
+		),
+		unbanPeer: connect.NewClient[v1.UnbanPeerRequest, v1.UnbanPeerResponse](
+			httpClient,
+			baseURL+AdminServiceUnbanPeerProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("UnbanPeer")),
+			connect.WithClientOptions(opts...),
+		),
+		listBannedPeers: connect.NewClient[v1.ListBannedPeersRequest, v1.ListBannedPeersResponse](
+			httpClient,
+			baseURL+AdminServiceListBannedPeersProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("ListBannedPeers")),
+			connect.WithClientOptions(opts...),
+		),
+		triggerDiscovery: connect.NewClient[v1.TriggerDiscoveryRequest, v1.TriggerDiscoveryResponse](
+			httpClient,
+			baseURL+AdminServiceTriggerDiscoveryProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("TriggerDiscovery")),
+			connect.WithClientOptions(opts...),
+		),
+		getEffectiveConfig: connect.NewClient[v1.GetEffectiveConfigRequest, v1.GetEffectiveConfigResponse](
+			httpClient,
+			baseURL+AdminServiceGetEffectiveConfigProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("GetEffectiveConfig")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// adminServiceClient implements AdminServiceClient.
+type adminServiceClient struct {
+	listConnections     *connect.Client[v1.ListConnectionsRequest, v1.ListConnectionsResponse]
+	getPoolStats        *connect.Client[v1.GetPoolStatsRequest, v1.GetPoolStatsResponse]
+	listDiscoveredPeers *connect.Client[v1.ListDiscoveredPeersRequest, v1.ListDiscoveredPeersResponse]
+	disconnectPeer      *connect.Client[v1.DisconnectPeerRequest, v1.DisconnectPeerResponse]
+	banPeer             *connect.Client[v1.BanPeerRequest, v1.BanPeerResponse]
+	unbanPeer           *connect.Client[v1.UnbanPeerRequest, v1.UnbanPeerResponse]
+	listBannedPeers     *connect.Client[v1.ListBannedPeersRequest, v1.ListBannedPeersResponse]
+	triggerDiscovery    *connect.Client[v1.TriggerDiscoveryRequest, v1.TriggerDiscoveryResponse]
+	getEffectiveConfig  *connect.Client[v1.GetEffectiveConfigRequest, v1.GetEffectiveConfigResponse]
+}
+
+// ListConnections calls drpc.admin.v1.AdminService.ListConnections.
+func (c *adminServiceClient) ListConnections(ctx context.Context, req *connect.Request[v1.ListConnectionsRequest]) (*connect.Response[v1.ListConnectionsResponse], error) {
+	return c.listConnections.CallUnary(ctx, req)
+}
+
+// GetPoolStats calls drpc.admin.v1.AdminService.GetPoolStats.
+func (c *adminServiceClient) GetPoolStats(ctx context.Context, req *connect.Request[v1.GetPoolStatsRequest]) (*connect.Response[v1.GetPoolStatsResponse], error) {
+	return c.getPoolStats.CallUnary(ctx, req)
+}
+
+// ListDiscoveredPeers calls drpc.admin.v1.AdminService.ListDiscoveredPeers.
+func (c *adminServiceClient) ListDiscoveredPeers(ctx context.Context, req *connect.Request[v1.ListDiscoveredPeersRequest]) (*connect.Response[v1.ListDiscoveredPeersResponse], error) {
+	return c.listDiscoveredPeers.CallUnary(ctx, req)
+}
+
+// DisconnectPeer calls drpc.admin.v1.AdminService.DisconnectPeer.
+func (c *adminServiceClient) DisconnectPeer(ctx context.Context, req *connect.Request[v1.DisconnectPeerRequest]) (*connect.Response[v1.DisconnectPeerResponse], error) {
+	return c.disconnectPeer.CallUnary(ctx, req)
+}
+
+// BanPeer calls drpc.admin.v1.AdminService.BanPeer.
+func (c *adminServiceClient) BanPeer(ctx context.Context, req *connect.Request[v1.BanPeerRequest]) (*connect.Response[v1.BanPeerResponse], error) {
+	return c.banPeer.CallUnary(ctx, req)
+}
+
+// UnbanPeer calls drpc.admin.v1.AdminService.UnbanPeer.
+func (c *adminServiceClient) UnbanPeer(ctx context.Context, req *connect.Request[v1.UnbanPeerRequest]) (*connect.Response[v1.UnbanPeerResponse], error) {
+	return c.unbanPeer.CallUnary(ctx, req)
+}
+
+// ListBannedPeers calls drpc.admin.v1.AdminService.ListBannedPeers.
+func (c *adminServiceClient) ListBannedPeers(ctx context.Context, req *connect.Request[v1.ListBannedPeersRequest]) (*connect.Response[v1.ListBannedPeersResponse], error) {
+	return c.listBannedPeers.CallUnary(ctx, req)
+}
+
+// TriggerDiscovery calls drpc.admin.v1.AdminService.TriggerDiscovery.
+func (c *adminServiceClient) TriggerDiscovery(ctx context.Context, req *connect.Request[v1.TriggerDiscoveryRequest]) (*connect.Response[v1.TriggerDiscoveryResponse], error) {
+	return c.triggerDiscovery.CallUnary(ctx, req)
+}
+
+// GetEffectiveConfig calls drpc.admin.v1.AdminService.GetEffectiveConfig.
+func (c *adminServiceClient) GetEffectiveConfig(ctx context.Context, req *connect.Request[v1.GetEffectiveConfigRequest]) (*connect.Response[v1.GetEffectiveConfigResponse], error) {
+	return c.getEffectiveConfig.CallUnary(ctx, req)
+}
+
+// AdminServiceHandler is an implementation of the drpc.admin.v1.AdminService service.
+type AdminServiceHandler interface {
+	ListConnections(context.Context, *connect.Request[v1.ListConnectionsRequest]) (*connect.Response[v1.ListConnectionsResponse], error)
+	GetPoolStats(context.Context, *connect.Request[v1.GetPoolStatsRequest]) (*connect.Response[v1.GetPoolStatsResponse], error)
+	ListDiscoveredPeers(context.Context, *connect.Request[v1.ListDiscoveredPeersRequest]) (*connect.Response[v1.ListDiscoveredPeersResponse], error)
+	DisconnectPeer(context.Context, *connect.Request[v1.DisconnectPeerRequest]) (*connect.Response[v1.DisconnectPeerResponse], error)
+	BanPeer(context.Context, *connect.Request[v1.BanPeerRequest]) (*connect.Response[v1.BanPeerResponse], error)
+	UnbanPeer(context.Context, *connect.Request[v1.UnbanPeerRequest]) (*connect.Response[v1.UnbanPeerResponse], error)
+	ListBannedPeers(context.Context, *connect.Request[v1.ListBannedPeersRequest]) (*connect.Response[v1.ListBannedPeersResponse], error)
+	TriggerDiscovery(context.Context, *connect.Request[v1.TriggerDiscoveryRequest]) (*connect.Response[v1.TriggerDiscoveryResponse], error)
+	GetEffectiveConfig(context.Context, *connect.Request[v1.GetEffectiveConfigRequest]) (*connect.Response[v1.GetEffectiveConfigResponse], error)
+}
+
+// NewAdminServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAdminServiceHandler(svc AdminServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	adminServiceMethods := v1.File_drpc_admin_v1_admin_proto.Services().ByName("AdminService").Methods()
+	adminServiceListConnectionsHandler := connect.NewUnaryHandler(
+		AdminServiceListConnectionsProcedure,
+		svc.ListConnections,
+		connect.WithSchema(adminServiceMethods.ByName("ListConnections")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceGetPoolStatsHandler := connect.NewUnaryHandler(
+		AdminServiceGetPoolStatsProcedure,
+		svc.GetPoolStats,
+		connect.WithSchema(adminServiceMethods.ByName("GetPoolStats")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceListDiscoveredPeersHandler := connect.NewUnaryHandler(
+		AdminServiceListDiscoveredPeersProcedure,
+		svc.ListDiscoveredPeers,
+		connect.WithSchema(adminServiceMethods.ByName("ListDiscoveredPeers")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceDisconnectPeerHandler := connect.NewUnaryHandler(
+		AdminServiceDisconnectPeerProcedure,
+		svc.DisconnectPeer,
+		connect.WithSchema(adminServiceMethods.ByName("DisconnectPeer")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceBanPeerHandler := connect.NewUnaryHandler(
+		AdminServiceBanPeerProcedure,
+		svc.BanPeer,
+		connect.WithSchema(adminServiceMethods.ByName("BanPeer")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceUnbanPeerHandler := connect.NewUnaryHandler(
+		AdminServiceUnbanPeerProcedure,
+		svc.UnbanPeer,
+		connect.WithSchema(adminServiceMethods.ByName("UnbanPeer")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceListBannedPeersHandler := connect.NewUnaryHandler(
+		AdminServiceListBannedPeersProcedure,
+		svc.ListBannedPeers,
+		connect.WithSchema(adminServiceMethods.ByName("ListBannedPeers")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceTriggerDiscoveryHandler := connect.NewUnaryHandler(
+		AdminServiceTriggerDiscoveryProcedure,
+		svc.TriggerDiscovery,
+		connect.WithSchema(adminServiceMethods.ByName("TriggerDiscovery")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceGetEffectiveConfigHandler := connect.NewUnaryHandler(
+		AdminServiceGetEffectiveConfigProcedure,
+		svc.GetEffectiveConfig,
+		connect.WithSchema(adminServiceMethods.ByName("GetEffectiveConfig")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/drpc.admin.v1.AdminService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AdminServiceListConnectionsProcedure:
+			adminServiceListConnectionsHandler.ServeHTTP(w, r)
+		case AdminServiceGetPoolStatsProcedure:
+			adminServiceGetPoolStatsHandler.ServeHTTP(w, r)
+		case AdminServiceListDiscoveredPeersProcedure:
+			adminServiceListDiscoveredPeersHandler.ServeHTTP(w, r)
+		case AdminServiceDisconnectPeerProcedure:
+			adminServiceDisconnectPeerHandler.ServeHTTP(w, r)
+		case AdminServiceBanPeerProcedure:
+			adminServiceBanPeerHandler.ServeHTTP(w, r)
+		case AdminServiceUnbanPeerProcedure:
+			adminServiceUnbanPeerHandler.ServeHTTP(w, r)
+		case AdminServiceListBannedPeersProcedure:
+			adminServiceListBannedPeersHandler.ServeHTTP(w, r)
+		case AdminServiceTriggerDiscoveryProcedure:
+			adminServiceTriggerDiscoveryHandler.ServeHTTP(w, r)
+		case AdminServiceGetEffectiveConfigProcedure:
+			adminServiceGetEffectiveConfigHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAdminServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedAdminServiceHandler struct{}
+
+func (UnimplementedAdminServiceHandler) ListConnections(context.Context, *connect.Request[v1.ListConnectionsRequest]) (*connect.Response[v1.ListConnectionsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.ListConnections is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) GetPoolStats(context.Context, *connect.Request[v1.GetPoolStatsRequest]) (*connect.Response[v1.GetPoolStatsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.GetPoolStats is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) ListDiscoveredPeers(context.Context, *connect.Request[v1.ListDiscoveredPeersRequest]) (*connect.Response[v1.ListDiscoveredPeersResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.ListDiscoveredPeers is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) DisconnectPeer(context.Context, *connect.Request[v1.DisconnectPeerRequest]) (*connect.Response[v1.DisconnectPeerResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.DisconnectPeer is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) BanPeer(context.Context, *connect.Request[v1.BanPeerRequest]) (*connect.Response[v1.BanPeerResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.BanPeer is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) UnbanPeer(context.Context, *connect.Request[v1.UnbanPeerRequest]) (*connect.Response[v1.UnbanPeerResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.UnbanPeer is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) ListBannedPeers(context.Context, *connect.Request[v1.ListBannedPeersRequest]) (*connect.Response[v1.ListBannedPeersResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.ListBannedPeers is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) TriggerDiscovery(context.Context, *connect.Request[v1.TriggerDiscoveryRequest]) (*connect.Response[v1.TriggerDiscoveryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.TriggerDiscovery is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) GetEffectiveConfig(context.Context, *connect.Request[v1.GetEffectiveConfigRequest]) (*connect.Response[v1.GetEffectiveConfigResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("drpc.admin.v1.AdminService.GetEffectiveConfig is not implemented"))
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/omgolab/drpc/pkg/core"
+)
+
+func TestAcceptQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx, WithAcceptQueue(core.AcceptQueue{Size: 4, MaxPendingPerPeer: 2, Overflow: core.OverflowBusy}))
+	if _, err := callSayHello(ctx, server.P2PAddrs()[0]); err != nil {
+		t.Fatalf("Call over libp2p failed: %v", err)
+	}
+	stats, ok := server.AcceptStats()
+	if !ok || stats.Accepted == 0 || stats.Pending != 0 {
+		t.Errorf("AcceptStats = %+v, %v; want an accepted stream and none pending", stats, ok)
+	}
+	if q := server.EffectiveConfig().Limits.AcceptQueue; q.Size != 4 || q.MaxPendingPerPeer != 2 || q.Overflow != "busy" {
+		t.Errorf("Effective accept queue = %+v", q)
+	}
+}
+
+func TestWithAcceptQueueValidation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	for _, queue := range []core.AcceptQueue{{Size: -1}, {MaxPendingPerPeer: -1}, {Overflow: core.OverflowPolicy(7)}} {
+		if _, err := New(ctx, http.NewServeMux(), WithAcceptQueue(queue)); err == nil {
+			t.Errorf("Expected %+v to be rejected", queue)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/omgolab/drpc/pkg/core"
+)
+
+// AccessPolicy controls which callers may invoke which Connect procedures.
+// Rules are evaluated in order and the first rule whose Procedure matches decides.
+type AccessPolicy struct {
+	// Groups names sets of peers that rules can refer to
+	Groups map[string][]peer.ID
+	// Rules are evaluated in order; the first one matching the procedure decides
+	Rules []AccessRule
+	// DefaultDeny rejects procedures that no rule matches; by default they are allowed
+	DefaultDeny bool
+}
+
+// AccessRule allows (or, with Deny, rejects) the listed callers for a procedure.
+//
+// Procedure is either a full procedure ("/pkg.Service/Method"), a service
+// prefix ending in "/" ("/pkg.Service/") or "*" for every procedure.
+// A caller matches the rule if its peer ID is in Peers or in one of Groups,
+// or if HTTPOnly is set and the call arrived on the local HTTP listener.
+// An allow rule rejects every caller that does not match; a deny rule
+// rejects matching callers and allows everyone else.
+type AccessRule struct {
+	Procedure string
+	Peers     []peer.ID
+	Groups    []string
+	HTTPOnly  bool
+	Deny      bool
+}
+
+// accessRule is an AccessRule with its peers and groups resolved into a set
+type accessRule struct {
+	procedure string
+	peers     map[peer.ID]struct{}
+	httpOnly  bool
+	deny      bool
+}
+
+// accessPolicy is the compiled form of an AccessPolicy
+type accessPolicy struct {
+	rules       []accessRule
+	defaultDeny bool
+}
+
+// WithAccessPolicy restricts Connect procedures by remote peer ID, peer group
+// or entry path. Denied calls fail with a Connect PermissionDenied error on
+// every path (libp2p, web stream, gateway and local HTTP).
+func WithAccessPolicy(policy AccessPolicy) ServerOption {
+	return func(cfg *Config) error {
+		compiled, err := compileAccessPolicy(policy)
+		if err != nil {
+			return fmt.Errorf("invalid access policy: %w", err)
+		}
+		cfg.accessPolicy = compiled
+		return nil
+	}
+}
+
+// compileAccessPolicy validates the policy and resolves group references
+func compileAccessPolicy(policy AccessPolicy) (*accessPolicy, error) {
+	compiled := &accessPolicy{
+		rules:       make([]accessRule, 0, len(policy.Rules)),
+		defaultDeny: policy.DefaultDeny,
+	}
+
+	for i, rule := range policy.Rules {
+		if !validProcedurePattern(rule.Procedure) {
+			return nil, fmt.Errorf("rule %d: procedure %q must start with '/' or be '*'", i, rule.Procedure)
+		}
+
+		peers := make(map[peer.ID]struct{}, len(rule.Peers))
+		for _, id := range rule.Peers {
+			peers[id] = struct{}{}
+		}
+		for _, name := range rule.Groups {
+			members, ok := policy.Groups[name]
+			if !ok {
+				return nil, fmt.Errorf("rule %d: unknown peer group %q", i, name)
+			}
+			for _, id := range members {
+				peers[id] = struct{}{}
+			}
+		}
+		if len(peers) == 0 && !rule.HTTPOnly {
+			return nil, fmt.Errorf("rule %d: no peers, groups or HTTPOnly given for %q", i, rule.Procedure)
+		}
+
+		compiled.rules = append(compiled.rules, accessRule{
+			procedure: rule.Procedure,
+			peers:     peers,
+			httpOnly:  rule.HTTPOnly,
+			deny:      rule.Deny,
+		})
+	}
+
+	return compiled, nil
+}
+
+// matchesProcedure reports whether the rule applies to procedure
+func (r *accessRule) matchesProcedure(procedure string) bool {
+	return matchProcedure(r.procedure, procedure)
+}
+
+// validProcedurePattern reports whether pattern is "*" or starts with "/"
+func validProcedurePattern(pattern string) bool {
+	return pattern == "*" || strings.HasPrefix(pattern, "/")
+}
+
+// matchProcedure reports whether procedure matches pattern, which is a full
+// procedure, a service prefix ending in "/" or "*"
+func matchProcedure(pattern, procedure string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasSuffix(pattern, "/"):
+		return strings.HasPrefix(procedure, pattern)
+	default:
+		return procedure == pattern
+	}
+}
+
+// matchesCaller reports whether the caller is one of the rule's subjects
+func (r *accessRule) matchesCaller(info core.PeerInfo) bool {
+	if r.httpOnly && info.Entry == core.EntryHTTP {
+		return true
+	}
+	if info.ID == "" {
+		return false
+	}
+	_, ok := r.peers[info.ID]
+	return ok
+}
+
+// allowed reports whether the caller may invoke procedure
+func (p *accessPolicy) allowed(procedure string, info core.PeerInfo) bool {
+	for i := range p.rules {
+		rule := &p.rules[i]
+		if !rule.matchesProcedure(procedure) {
+			continue
+		}
+		return rule.matchesCaller(info) != rule.deny
+	}
+	return !p.defaultDeny
+}
+
+// wrap rejects calls the policy does not allow with a Connect PermissionDenied error.
+// next must run behind core.PeerInfoHandler.
+func (p *accessPolicy) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, _ := core.PeerInfoFromContext(r.Context())
+		if !p.allowed(r.URL.Path, info) {
+			caller := info.ID.String()
+			if info.ID == "" {
+				caller = string(info.Entry) + " caller"
+			}
+			writeConnectError(w, r, connect.NewError(connect.CodePermissionDenied,
+				fmt.Errorf("%s is not allowed to call %s", caller, r.URL.Path)))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
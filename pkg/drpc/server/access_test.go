@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core"
+)
+
+const sayHelloProcedure = "/greeter.v1.GreeterService/SayHello"
+
+// newTestIdentity returns a libp2p identity option and the matching peer ID
+func newTestIdentity(t *testing.T) (libp2p.Option, peer.ID) {
+	t.Helper()
+
+	priv, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	id, err := peer.IDFromPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("Failed to derive peer ID: %v", err)
+	}
+	return libp2p.Identity(priv), id
+}
+
+// callWebStream sends one Connect streaming message over the web stream
+// envelope protocol and returns the raw response bytes
+func callWebStream(t *testing.T, ctx context.Context, server *DRPCServer, procedure, payload string) string {
+	t.Helper()
+
+	resp, err := tryWebStream(ctx, server, procedure, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// tryWebStream is callWebStream returning the error instead of failing the test
+func tryWebStream(ctx context.Context, server *DRPCServer, procedure, payload string) (string, error) {
+	h, err := libp2p.New(libp2p.NoListenAddrs)
+	if err != nil {
+		return "", fmt.Errorf("failed to create host: %w", err)
+	}
+	defer h.Close()
+
+	target := peer.AddrInfo{ID: server.P2PHost().ID(), Addrs: server.P2PHost().Addrs()}
+	if err := h.Connect(ctx, target); err != nil {
+		return "", fmt.Errorf("failed to connect: %w", err)
+	}
+	stream, err := h.NewStream(ctx, target.ID, config.DRPC_WEB_STREAM_PROTOCOL_ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to open web stream: %w", err)
+	}
+	defer stream.Close()
+
+	contentType := "application/connect+json"
+	var msg []byte
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(procedure)))
+	msg = append(msg, procedure...)
+	msg = append(msg, byte(len(contentType)))
+	msg = append(msg, contentType...)
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(payload)))
+	msg = append(msg, payload...)
+	if _, err := stream.Write(msg); err != nil {
+		return "", fmt.Errorf("failed to write envelope: %w", err)
+	}
+	if err := stream.CloseWrite(); err != nil {
+		return "", fmt.Errorf("failed to close write side: %w", err)
+	}
+
+	resp, err := io.ReadAll(stream)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	return string(resp), nil
+}
+
+func TestCompileAccessPolicyErrors(t *testing.T) {
+	_, id := newTestIdentity(t)
+	tests := []struct {
+		name   string
+		policy AccessPolicy
+	}{
+		{"unknown_group", AccessPolicy{Rules: []AccessRule{{Procedure: "*", Groups: []string{"missing"}}}}},
+		{"relative_procedure", AccessPolicy{Rules: []AccessRule{{Procedure: "greeter.v1.GreeterService/", Peers: []peer.ID{id}}}}},
+		{"no_subjects", AccessPolicy{Rules: []AccessRule{{Procedure: "*"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := compileAccessPolicy(tt.policy); err == nil {
+				t.Error("Expected invalid policy to be rejected")
+			}
+		})
+	}
+}
+
+func TestAccessPolicyAllowed(t *testing.T) {
+	_, alice := newTestIdentity(t)
+	_, bob := newTestIdentity(t)
+	_, mallory := newTestIdentity(t)
+
+	policy, err := compileAccessPolicy(AccessPolicy{
+		Groups: map[string][]peer.ID{"ops": {bob}},
+		Rules: []AccessRule{
+			{Procedure: "/admin.v1.AdminService/", HTTPOnly: true, Groups: []string{"ops"}},
+			{Procedure: sayHelloProcedure, Peers: []peer.ID{mallory}, Deny: true},
+			{Procedure: "/greeter.v1.GreeterService/StreamingEcho", Peers: []peer.ID{alice}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to compile policy: %v", err)
+	}
+
+	local := core.PeerInfo{Entry: core.EntryHTTP}
+	p2p := func(id peer.ID) core.PeerInfo { return core.PeerInfo{ID: id, Entry: core.EntryLibp2p} }
+
+	tests := []struct {
+		name      string
+		procedure string
+		caller    core.PeerInfo
+		want      bool
+	}{
+		{"admin_local_http", "/admin.v1.AdminService/ListPeers", local, true},
+		{"admin_group_member", "/admin.v1.AdminService/ListPeers", p2p(bob), true},
+		{"admin_remote_peer", "/admin.v1.AdminService/ListPeers", p2p(alice), false},
+		{"deny_listed_peer", sayHelloProcedure, p2p(mallory), false},
+		{"deny_rule_allows_others", sayHelloProcedure, p2p(alice), true},
+		{"allow_listed_peer", "/greeter.v1.GreeterService/StreamingEcho", p2p(alice), true},
+		{"allow_rule_rejects_others", "/greeter.v1.GreeterService/StreamingEcho", p2p(bob), false},
+		{"unmatched_procedure", "/other.v1.Service/Call", p2p(mallory), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.allowed(tt.procedure, tt.caller); got != tt.want {
+				t.Errorf("allowed(%s) = %v, want %v", tt.procedure, got, tt.want)
+			}
+		})
+	}
+
+	policy.defaultDeny = true
+	if policy.allowed("/other.v1.Service/Call", p2p(alice)) {
+		t.Error("DefaultDeny should reject unmatched procedures")
+	}
+}
+
+func TestAccessPolicyEnforced(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	identity, allowed := newTestIdentity(t)
+	server := newPeerEchoServer(t, ctx, WithAccessPolicy(AccessPolicy{
+		Rules: []AccessRule{
+			{Procedure: sayHelloProcedure, HTTPOnly: true, Peers: []peer.ID{allowed}},
+			{Procedure: "/greeter.v1.GreeterService/", HTTPOnly: true},
+		},
+	}))
+	p2pAddr := server.P2PAddrs()[0]
+
+	t.Run("http_allowed", func(t *testing.T) {
+		if _, err := callSayHello(ctx, server.HTTPAddr()); err != nil {
+			t.Fatalf("Local HTTP call should be allowed: %v", err)
+		}
+	})
+
+	t.Run("listed_peer_allowed", func(t *testing.T) {
+		if _, err := callSayHello(ctx, p2pAddr, identity); err != nil {
+			t.Fatalf("Listed peer should be allowed: %v", err)
+		}
+	})
+
+	t.Run("unlisted_peer_denied", func(t *testing.T) {
+		_, err := callSayHello(ctx, p2pAddr)
+		if connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Fatalf("Expected PermissionDenied, got %v", err)
+		}
+	})
+
+	t.Run("web_stream_denied", func(t *testing.T) {
+		resp := callWebStream(t, ctx, server, "/greeter.v1.GreeterService/StreamingEcho", `{"message":"hi"}`)
+		if !strings.Contains(resp, "permission_denied") {
+			t.Fatalf("Expected permission_denied end-stream message, got %q", resp)
+		}
+	})
+}
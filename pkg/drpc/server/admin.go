@@ -0,0 +1,391 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/omgolab/drpc/pkg/core"
+	h "github.com/omgolab/drpc/pkg/core/host"
+	"github.com/omgolab/drpc/pkg/core/pool"
+	adminv1 "github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1"
+	"github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1/adminv1connect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AdminAccess lists the callers allowed to use the admin service
+type AdminAccess struct {
+	// Peers may call the admin service over libp2p, relayed connections
+	// included, and the web stream bridge
+	Peers []peer.ID
+	// HTTP allows every caller on the local HTTP listener, which should then
+	// be bound to a loopback address
+	HTTP bool
+}
+
+// adminAccess is the compiled form of an AdminAccess
+type adminAccess struct {
+	peers map[peer.ID]struct{}
+	http  bool
+}
+
+// WithAdminService mounts the drpc.admin.v1.AdminService, which lists
+// connections, streams, pool and discovery state, disconnects and bans
+// peers, triggers discovery rounds and dumps the effective config. Only the
+// callers in access may use it; calls forwarded by gateways are rejected,
+// whatever the gateway's peer ID. It is served over libp2p like any other
+// service, so nodes behind NATs can be managed through relays.
+func WithAdminService(access AdminAccess) ServerOption {
+	return func(cfg *Config) error {
+		if len(access.Peers) == 0 && !access.HTTP {
+			return errors.New("admin service needs admin peers or HTTP access")
+		}
+		compiled := &adminAccess{peers: make(map[peer.ID]struct{}, len(access.Peers)), http: access.HTTP}
+		for _, id := range access.Peers {
+			if err := id.Validate(); err != nil {
+				return fmt.Errorf("invalid admin peer %q: %w", id, err)
+			}
+			compiled.peers[id] = struct{}{}
+		}
+		cfg.admin = compiled
+		return nil
+	}
+}
+
+// allowed reports whether the caller may use the admin service. Access is
+// granted on the authenticated peer ID only; the advisory gateway entry can
+// merely withhold it, so that admin peers running a gateway do not lend their
+// identity to the HTTP callers they forward, and claiming it gains nothing.
+func (a *adminAccess) allowed(info core.PeerInfo) bool {
+	switch info.Entry {
+	case core.EntryHTTP:
+		return a.http
+	case core.EntryGateway:
+		return false
+	}
+	_, ok := a.peers[info.ID]
+	return ok && info.ID != ""
+}
+
+// wrap rejects the calls of non-admin callers with a Connect PermissionDenied error.
+// next must run behind core.PeerInfoHandler.
+func (a *adminAccess) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, _ := core.PeerInfoFromContext(r.Context())
+		if !a.allowed(info) {
+			caller := info.ID.String()
+			if info.ID == "" || info.Entry == core.EntryGateway {
+				caller = string(info.Entry) + " caller"
+			}
+			writeConnectError(w, r, connect.NewError(connect.CodePermissionDenied,
+				fmt.Errorf("%s is not allowed to use the admin service", caller)))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// access returns the admin settings in AdminAccess form
+func (a *adminAccess) access() AdminAccess {
+	access := AdminAccess{HTTP: a.http}
+	for id := range a.peers {
+		access.Peers = append(access.Peers, id)
+	}
+	slices.Sort(access.Peers)
+	return access
+}
+
+var _ adminv1connect.AdminServiceHandler = (*adminService)(nil)
+
+// adminService implements drpc.admin.v1.AdminService for a DRPCServer
+type adminService struct {
+	server *DRPCServer
+}
+
+// host returns the server's libp2p host
+func (a *adminService) host() (*h.ManagedHost, error) {
+	managed, ok := a.server.P2PHost().(*h.ManagedHost)
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnavailable, errors.New("libp2p host is not running"))
+	}
+	return managed, nil
+}
+
+// parsePeerID decodes a peer ID of a request
+func parsePeerID(value string) (peer.ID, error) {
+	id, err := peer.Decode(value)
+	if err != nil {
+		return "", connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid peer ID %q: %w", value, err))
+	}
+	return id, nil
+}
+
+func (a *adminService) ListConnections(_ context.Context, req *connect.Request[adminv1.ListConnectionsRequest]) (*connect.Response[adminv1.ListConnectionsResponse], error) {
+	managed, err := a.host()
+	if err != nil {
+		return nil, err
+	}
+	conns := managed.Network().Conns()
+	if req.Msg.PeerId != "" {
+		id, err := parsePeerID(req.Msg.PeerId)
+		if err != nil {
+			return nil, err
+		}
+		conns = managed.Network().ConnsToPeer(id)
+	}
+
+	byPeer := make(map[peer.ID]*adminv1.PeerConnections)
+	streams := make(map[peer.ID]map[string]*adminv1.ProtocolStreams)
+	for _, c := range conns {
+		id := c.RemotePeer()
+		pc, ok := byPeer[id]
+		if !ok {
+			pc = &adminv1.PeerConnections{PeerId: id.String()}
+			byPeer[id] = pc
+			streams[id] = make(map[string]*adminv1.ProtocolStreams)
+		}
+		stat := c.Stat()
+		open := c.GetStreams()
+		pc.Connections = append(pc.Connections, &adminv1.Connection{
+			Id:         c.ID(),
+			LocalAddr:  c.LocalMultiaddr().String(),
+			RemoteAddr: c.RemoteMultiaddr().String(),
+			Direction:  adminDirection(stat.Direction),
+			Limited:    stat.Limited,
+			Opened:     timestamppb.New(stat.Opened),
+			Streams:    int32(len(open)),
+		})
+		for _, s := range open {
+			protocol := string(s.Protocol())
+			ps, ok := streams[id][protocol]
+			if !ok {
+				ps = &adminv1.ProtocolStreams{Protocol: protocol}
+				streams[id][protocol] = ps
+			}
+			if s.Stat().Direction == network.DirInbound {
+				ps.Inbound++
+			} else {
+				ps.Outbound++
+			}
+		}
+	}
+
+	resp := &adminv1.ListConnectionsResponse{}
+	for _, id := range slices.Sorted(maps.Keys(byPeer)) {
+		pc := byPeer[id]
+		for _, protocol := range slices.Sorted(maps.Keys(streams[id])) {
+			pc.Streams = append(pc.Streams, streams[id][protocol])
+		}
+		resp.Peers = append(resp.Peers, pc)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func (a *adminService) GetPoolStats(_ context.Context, _ *connect.Request[adminv1.GetPoolStatsRequest]) (*connect.Response[adminv1.GetPoolStatsResponse], error) {
+	managed, err := a.host()
+	if err != nil {
+		return nil, err
+	}
+	stats := pool.GetPool(managed, a.server.logger).Stats()
+	resp := &adminv1.GetPoolStatsResponse{
+		Reused:     stats.Reused,
+		Created:    stats.Created,
+		ReuseRatio: stats.ReuseRatio,
+	}
+	for _, shard := range stats.Shards {
+		resp.Shards = append(resp.Shards, &adminv1.PoolShard{Idle: int32(shard.Idle), Active: int32(shard.Active)})
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func (a *adminService) ListDiscoveredPeers(_ context.Context, _ *connect.Request[adminv1.ListDiscoveredPeersRequest]) (*connect.Response[adminv1.ListDiscoveredPeersResponse], error) {
+	managed, err := a.host()
+	if err != nil {
+		return nil, err
+	}
+	book := make(map[peer.ID]h.AddressBookEntry)
+	if b := h.GetAddressBook(managed); b != nil {
+		for _, entry := range b.Peers() {
+			book[entry.ID] = entry
+		}
+	}
+
+	peers := make(map[peer.ID]struct{})
+	for _, id := range managed.Peerstore().PeersWithAddrs() {
+		peers[id] = struct{}{}
+	}
+	for _, id := range managed.Network().Peers() {
+		peers[id] = struct{}{}
+	}
+	for id := range book {
+		peers[id] = struct{}{}
+	}
+	delete(peers, managed.ID())
+
+	resp := &adminv1.ListDiscoveredPeersResponse{}
+	for _, id := range slices.Sorted(maps.Keys(peers)) {
+		resp.Peers = append(resp.Peers, discoveredPeer(managed, id, book))
+	}
+	for _, entry := range h.DiscoveryCache() {
+		resp.DiscoveryCache = append(resp.DiscoveryCache, &adminv1.DiscoveryCacheEntry{
+			PeerId:     entry.Peer.ID.String(),
+			Addrs:      multiaddrStrings(entry.Peer.Addrs),
+			Discovered: timestamppb.New(entry.Discovered),
+			Attempts:   int32(entry.Attempts),
+			Expired:    entry.Expired,
+		})
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// discoveredPeer describes a known peer from the peerstore and the address book
+func discoveredPeer(hst host.Host, id peer.ID, book map[peer.ID]h.AddressBookEntry) *adminv1.DiscoveredPeer {
+	connectedness := hst.Network().Connectedness(id)
+	dp := &adminv1.DiscoveredPeer{
+		PeerId:    id.String(),
+		Addrs:     multiaddrStrings(hst.Peerstore().Addrs(id)),
+		Connected: connectedness == network.Connected || connectedness == network.Limited,
+		Limited:   connectedness == network.Limited,
+	}
+	if protocols, err := hst.Peerstore().GetProtocols(id); err == nil {
+		for _, p := range protocols {
+			dp.Protocols = append(dp.Protocols, string(p))
+		}
+		slices.Sort(dp.Protocols)
+	}
+	if entry, ok := book[id]; ok {
+		if len(dp.Addrs) == 0 {
+			dp.Addrs = entry.Addrs
+		}
+		dp.AddressBook = &adminv1.AddressBookEntry{
+			FirstSeen:   timestamppb.New(entry.FirstSeen),
+			Connections: int32(entry.Connections),
+			Uptime:      durationpb.New(entry.Uptime.Std()),
+			Latency:     durationpb.New(entry.Latency.Std()),
+			Failures:    int32(entry.Failures),
+		}
+		if !entry.LastConnected.IsZero() {
+			dp.AddressBook.LastConnected = timestamppb.New(entry.LastConnected)
+		}
+	}
+	return dp
+}
+
+func (a *adminService) DisconnectPeer(_ context.Context, req *connect.Request[adminv1.DisconnectPeerRequest]) (*connect.Response[adminv1.DisconnectPeerResponse], error) {
+	managed, err := a.host()
+	if err != nil {
+		return nil, err
+	}
+	id, err := parsePeerID(req.Msg.PeerId)
+	if err != nil {
+		return nil, err
+	}
+	closed := len(managed.Network().ConnsToPeer(id))
+	if err := managed.Network().ClosePeer(id); err != nil {
+		return nil, fmt.Errorf("failed to disconnect %s: %w", id, err)
+	}
+	return connect.NewResponse(&adminv1.DisconnectPeerResponse{ClosedConnections: int32(closed)}), nil
+}
+
+func (a *adminService) BanPeer(ctx context.Context, req *connect.Request[adminv1.BanPeerRequest]) (*connect.Response[adminv1.BanPeerResponse], error) {
+	managed, err := a.host()
+	if err != nil {
+		return nil, err
+	}
+	id, err := parsePeerID(req.Msg.PeerId)
+	if err != nil {
+		return nil, err
+	}
+	if caller, _ := core.PeerInfoFromContext(ctx); caller.ID == id {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("cannot ban the calling peer"))
+	}
+	duration := req.Msg.Duration.AsDuration()
+	if req.Msg.Duration != nil && (req.Msg.Duration.CheckValid() != nil || duration <= 0) {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("ban duration must be positive"))
+	}
+	if err := managed.BanPeer(id, duration); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return connect.NewResponse(&adminv1.BanPeerResponse{}), nil
+}
+
+func (a *adminService) UnbanPeer(_ context.Context, req *connect.Request[adminv1.UnbanPeerRequest]) (*connect.Response[adminv1.UnbanPeerResponse], error) {
+	managed, err := a.host()
+	if err != nil {
+		return nil, err
+	}
+	id, err := parsePeerID(req.Msg.PeerId)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&adminv1.UnbanPeerResponse{Banned: managed.UnbanPeer(id)}), nil
+}
+
+func (a *adminService) ListBannedPeers(_ context.Context, _ *connect.Request[adminv1.ListBannedPeersRequest]) (*connect.Response[adminv1.ListBannedPeersResponse], error) {
+	managed, err := a.host()
+	if err != nil {
+		return nil, err
+	}
+	resp := &adminv1.ListBannedPeersResponse{}
+	for _, banned := range managed.BannedPeers() {
+		bp := &adminv1.BannedPeer{PeerId: banned.ID.String()}
+		if !banned.Expires.IsZero() {
+			bp.Expires = timestamppb.New(banned.Expires)
+		}
+		resp.Peers = append(resp.Peers, bp)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func (a *adminService) TriggerDiscovery(_ context.Context, _ *connect.Request[adminv1.TriggerDiscoveryRequest]) (*connect.Response[adminv1.TriggerDiscoveryResponse], error) {
+	managed, err := a.host()
+	if err != nil {
+		return nil, err
+	}
+	if err := managed.DiscoverPeers(); err != nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+	return connect.NewResponse(&adminv1.TriggerDiscoveryResponse{}), nil
+}
+
+func (a *adminService) GetEffectiveConfig(_ context.Context, req *connect.Request[adminv1.GetEffectiveConfigRequest]) (*connect.Response[adminv1.GetEffectiveConfigResponse], error) {
+	format := req.Msg.Format
+	if format == "" {
+		format = "yaml"
+	}
+	dump, err := a.server.EffectiveConfig().Dump(format)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return connect.NewResponse(&adminv1.GetEffectiveConfigResponse{Config: string(dump)}), nil
+}
+
+// adminDirection converts a libp2p connection direction
+func adminDirection(dir network.Direction) adminv1.Direction {
+	switch dir {
+	case network.DirInbound:
+		return adminv1.Direction_DIRECTION_INBOUND
+	case network.DirOutbound:
+		return adminv1.Direction_DIRECTION_OUTBOUND
+	default:
+		return adminv1.Direction_DIRECTION_UNSPECIFIED
+	}
+}
+
+// multiaddrStrings formats addrs
+func multiaddrStrings(addrs []ma.Multiaddr) []string {
+	out := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		out = append(out, addr.String())
+	}
+	return out
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/pool"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	adminv1 "github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1"
+	"github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1/adminv1connect"
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// newAdminClient creates an admin client for addr with a listen-less libp2p host
+func newAdminClient(t *testing.T, ctx context.Context, addr string, libp2pOpts ...libp2p.Option) adminv1connect.AdminServiceClient {
+	t.Helper()
+	logger, _ := glog.New()
+	c, err := client.New(ctx, addr, adminv1connect.NewAdminServiceClient,
+		client.WithLogger(logger),
+		client.WithLibp2pOptions(append([]libp2p.Option{libp2p.NoListenAddrs}, libp2pOpts...)...),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create admin client: %v", err)
+	}
+	return c
+}
+
+func TestAdminService(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	identity, admin := newTestIdentity(t)
+	server := newPeerEchoServer(t, ctx, WithAdminService(AdminAccess{Peers: []peer.ID{admin}}))
+	p2pAddr := server.P2PAddrs()[0]
+	c := newAdminClient(t, ctx, p2pAddr, identity)
+
+	conns, err := c.ListConnections(ctx, connect.NewRequest(&adminv1.ListConnectionsRequest{PeerId: admin.String()}))
+	if err != nil {
+		t.Fatalf("ListConnections over libp2p failed: %v", err)
+	}
+	if len(conns.Msg.Peers) != 1 || len(conns.Msg.Peers[0].Connections) == 0 || len(conns.Msg.Peers[0].Streams) == 0 {
+		t.Fatalf("Expected the admin's connection and streams, got %v", conns.Msg.Peers)
+	}
+	if got := conns.Msg.Peers[0].Connections[0].Direction; got != adminv1.Direction_DIRECTION_INBOUND {
+		t.Errorf("Admin connection direction = %v, want inbound", got)
+	}
+
+	stats, err := c.GetPoolStats(ctx, connect.NewRequest(&adminv1.GetPoolStatsRequest{}))
+	if err != nil {
+		t.Fatalf("GetPoolStats failed: %v", err)
+	}
+	if len(stats.Msg.Shards) != pool.ShardCount {
+		t.Errorf("Got %d pool shards, want %d", len(stats.Msg.Shards), pool.ShardCount)
+	}
+
+	discovered, err := c.ListDiscoveredPeers(ctx, connect.NewRequest(&adminv1.ListDiscoveredPeersRequest{}))
+	if err != nil {
+		t.Fatalf("ListDiscoveredPeers failed: %v", err)
+	}
+	found := false
+	for _, p := range discovered.Msg.Peers {
+		found = found || p.PeerId == admin.String() && p.Connected
+	}
+	if !found {
+		t.Errorf("Connected admin missing from discovered peers %v", discovered.Msg.Peers)
+	}
+
+	if _, err := c.TriggerDiscovery(ctx, connect.NewRequest(&adminv1.TriggerDiscoveryRequest{})); err != nil {
+		t.Errorf("TriggerDiscovery failed: %v", err)
+	}
+
+	dump, err := c.GetEffectiveConfig(ctx, connect.NewRequest(&adminv1.GetEffectiveConfigRequest{Format: "json"}))
+	if err != nil {
+		t.Fatalf("GetEffectiveConfig failed: %v", err)
+	}
+	if !strings.Contains(dump.Msg.Config, admin.String()) {
+		t.Errorf("Effective config does not list the admin peer:\n%s", dump.Msg.Config)
+	}
+
+	t.Run("ban", func(t *testing.T) {
+		other, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer other.Close()
+		serverInfo := peer.AddrInfo{ID: server.P2PHost().ID(), Addrs: server.P2PHost().Addrs()}
+		if err := other.Connect(ctx, serverInfo); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+
+		if _, err := c.BanPeer(ctx, connect.NewRequest(&adminv1.BanPeerRequest{PeerId: admin.String()})); connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Banning the caller returned %v, want InvalidArgument", err)
+		}
+		if _, err := c.BanPeer(ctx, connect.NewRequest(&adminv1.BanPeerRequest{PeerId: other.ID().String(), Duration: durationpb.New(time.Hour)})); err != nil {
+			t.Fatalf("BanPeer failed: %v", err)
+		}
+		banned, err := c.ListBannedPeers(ctx, connect.NewRequest(&adminv1.ListBannedPeersRequest{}))
+		if err != nil || len(banned.Msg.Peers) != 1 || banned.Msg.Peers[0].PeerId != other.ID().String() || banned.Msg.Peers[0].Expires == nil {
+			t.Fatalf("Unexpected banned peers %v: %v", banned.Msg.GetPeers(), err)
+		}
+
+		// The banned peer can connect, but the server closes its connections
+		other.Peerstore().AddAddrs(serverInfo.ID, serverInfo.Addrs, time.Hour)
+		_ = other.Connect(ctx, serverInfo)
+		deadline := time.Now().Add(5 * time.Second)
+		for len(server.P2PHost().Network().ConnsToPeer(other.ID())) > 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("Connection of the banned peer was not closed")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		unbanned, err := c.UnbanPeer(ctx, connect.NewRequest(&adminv1.UnbanPeerRequest{PeerId: other.ID().String()}))
+		if err != nil || !unbanned.Msg.Banned {
+			t.Fatalf("UnbanPeer = %v, %v; want banned", unbanned, err)
+		}
+		if err := other.Connect(ctx, serverInfo); err != nil {
+			t.Fatalf("Connect after the ban was lifted failed: %v", err)
+		}
+		disconnected, err := c.DisconnectPeer(ctx, connect.NewRequest(&adminv1.DisconnectPeerRequest{PeerId: other.ID().String()}))
+		if err != nil || disconnected.Msg.ClosedConnections == 0 {
+			t.Fatalf("DisconnectPeer = %v, %v; want closed connections", disconnected, err)
+		}
+	})
+
+	t.Run("access", func(t *testing.T) {
+		_, err := newAdminClient(t, ctx, p2pAddr).GetPoolStats(ctx, connect.NewRequest(&adminv1.GetPoolStatsRequest{}))
+		if connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Errorf("Non-admin peer got %v, want PermissionDenied", err)
+		}
+		_, err = newAdminClient(t, ctx, server.HTTPAddr()).GetPoolStats(ctx, connect.NewRequest(&adminv1.GetPoolStatsRequest{}))
+		if connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Errorf("HTTP caller got %v without HTTP access, want PermissionDenied", err)
+		}
+		// Claiming the gateway entry can only withhold access from an admin
+		req := connect.NewRequest(&adminv1.GetPoolStatsRequest{})
+		req.Header().Set(core.GatewayForwardedHeader, "1")
+		if _, err := c.GetPoolStats(ctx, req); connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Errorf("Admin claiming to be a gateway got %v, want PermissionDenied", err)
+		}
+		if _, err := c.ListConnections(ctx, connect.NewRequest(&adminv1.ListConnectionsRequest{PeerId: "not-a-peer"})); connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Invalid peer ID returned %v, want InvalidArgument", err)
+		}
+	})
+
+	t.Run("http", func(t *testing.T) {
+		server := newPeerEchoServer(t, ctx, WithAdminService(AdminAccess{HTTP: true}))
+		if _, err := newAdminClient(t, ctx, server.HTTPAddr()).GetEffectiveConfig(ctx, connect.NewRequest(&adminv1.GetEffectiveConfigRequest{})); err != nil {
+			t.Errorf("GetEffectiveConfig over the local HTTP listener failed: %v", err)
+		}
+		if err := WithAdminService(AdminAccess{})(&Config{}); err == nil {
+			t.Error("Expected an admin service without admins to be rejected")
+		}
+	})
+}
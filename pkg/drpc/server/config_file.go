@@ -0,0 +1,164 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/identity"
+)
+
+// WithConfigFile loads a YAML or JSON config file, with DRPC_* environment
+// overrides, and applies it as if the equivalent options were passed at its
+// position; options after it override the file. See config.File for the schema.
+func WithConfigFile(path string) ServerOption {
+	return func(cfg *Config) error {
+		f, err := config.LoadFile(path)
+		if err != nil {
+			return err
+		}
+		return cfg.applyConfigFile(f)
+	}
+}
+
+// applyConfigFile applies the sections of f that have server options and
+// keeps the host level ones for Setup
+func (cfg *Config) applyConfigFile(f config.File) error {
+	opts := []ServerOption{
+		WithHTTPHost(f.HTTP.Host),
+		WithHTTPPort(f.HTTP.Port),
+		WithProtocolVersions(f.Libp2p.ProtocolVersions...),
+	}
+	if f.HTTP.TLS.CertFile != "" {
+		opts = append(opts, WithTLS(f.HTTP.TLS.CertFile, f.HTTP.TLS.KeyFile))
+	}
+	if f.HTTP.H2C != nil {
+		opts = append(opts, WithH2C(*f.HTTP.H2C))
+	}
+	if f.CORS.Enabled {
+		opts = append(opts, WithCORSHeaders(f.CORS.AllowedOrigins, f.CORS.AllowedMethods, f.CORS.AllowedHeaders, f.CORS.ExposedHeaders))
+	}
+	if f.Limits.Rate.Enabled() {
+		opts = append(opts, WithRateLimits(rateLimitsFromFile(f.Limits.Rate)))
+	}
+	if f.Limits.Size.Enabled() {
+		opts = append(opts, WithSizeLimits(SizeLimits(f.Limits.Size)))
+	}
+	if f.Limits.Resources.Enabled() {
+		opts = append(opts, WithResourceLimits(core.ResourceLimitsFromFile(f.Limits.Resources)))
+	}
+	if f.Limits.AcceptQueue.Enabled() {
+		opts = append(opts, WithAcceptQueue(core.AcceptQueueFromFile(f.Limits.AcceptQueue)))
+	}
+	if f.Admin.Enabled() {
+		access := AdminAccess{HTTP: f.Admin.HTTP}
+		for _, value := range f.Admin.Peers {
+			id, err := peer.Decode(value)
+			if err != nil {
+				return fmt.Errorf("invalid config file admin peer: %w", err)
+			}
+			access.Peers = append(access.Peers, id)
+		}
+		opts = append(opts, WithAdminService(access))
+	}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return fmt.Errorf("invalid config file: %w", err)
+		}
+	}
+	key, err := identity.FromConfig(f.Identity)
+	if err != nil {
+		return fmt.Errorf("invalid config file identity: %w", err)
+	}
+	if key != nil {
+		cfg.identity = key
+		cfg.identitySource = f.Identity
+	}
+	cfg.configFile = &f
+	return nil
+}
+
+// rateLimitsFromFile converts the rate limits of a config file
+func rateLimitsFromFile(l config.RateLimits) RateLimits {
+	limits := RateLimits{PerCaller: RateLimit(l.PerCaller)}
+	for _, p := range l.Procedures {
+		limits.Procedures = append(limits.Procedures, ProcedureRateLimit{Procedure: p.Procedure, RateLimit: RateLimit(p.RateLimit)})
+	}
+	return limits
+}
+
+// effectiveConfig returns the settings cfg runs with in config file form.
+// Sections without server options come from the config file, or the defaults.
+func (cfg *Config) effectiveConfig() config.File {
+	f := config.DefaultFile()
+	if cfg.configFile != nil {
+		f = *cfg.configFile
+	}
+
+	f.Identity = cfg.identitySource
+	if cfg.addressBookPath != "" {
+		f.Discovery.AddressBook = cfg.addressBookPath
+	}
+	f.HTTP.Host = cfg.httpHost
+	f.HTTP.Port = cfg.httpPort
+	f.HTTP.TLS = config.TLS{}
+	if cfg.tls != nil {
+		f.HTTP.TLS = config.TLS{CertFile: cfg.tls.certFile, KeyFile: cfg.tls.keyFile}
+	}
+	f.HTTP.H2C = cfg.h2c
+	if len(cfg.protocolVersions) > 0 {
+		f.Libp2p.ProtocolVersions = cfg.protocolVersions
+	}
+
+	f.CORS = config.CORS{}
+	if c := cfg.corsConfig; c != nil {
+		f.CORS = config.CORS{
+			Enabled:        true,
+			AllowedOrigins: c.AllowedOrigins,
+			AllowedMethods: c.AllowedMethods,
+			AllowedHeaders: c.AllowedHeaders,
+			ExposedHeaders: c.ExposedHeaders,
+		}
+	}
+
+	f.Limits.Rate = config.RateLimits{}
+	if cfg.rateLimiter != nil {
+		f.Limits.Rate.PerCaller = config.RateLimit(cfg.rateLimiter.limits.PerCaller)
+		for _, p := range cfg.rateLimiter.limits.Procedures {
+			f.Limits.Rate.Procedures = append(f.Limits.Rate.Procedures,
+				config.ProcedureRateLimit{Procedure: p.Procedure, RateLimit: config.RateLimit(p.RateLimit)})
+		}
+	}
+	f.Limits.Size = config.SizeLimits{}
+	if cfg.sizeLimits != nil {
+		f.Limits.Size = config.SizeLimits(*cfg.sizeLimits)
+	}
+	f.Limits.Resources = config.ResourceLimits{}
+	if l := cfg.resourceLimits; l != nil {
+		f.Limits.Resources = config.ResourceLimits{
+			DRPC:      config.StreamLimits(l.DRPC),
+			WebStream: config.StreamLimits(l.WebStream),
+		}
+	}
+	f.Limits.AcceptQueue = config.AcceptQueue{}
+	if q := cfg.acceptQueue; q != nil {
+		f.Limits.AcceptQueue = config.AcceptQueue{Size: q.Size, MaxPendingPerPeer: q.MaxPendingPerPeer, Overflow: q.Overflow.String()}
+	}
+	f.Admin = config.Admin{}
+	if cfg.admin != nil {
+		access := cfg.admin.access()
+		f.Admin.HTTP = access.HTTP
+		for _, id := range access.Peers {
+			f.Admin.Peers = append(f.Admin.Peers, id.String())
+		}
+	}
+	return f
+}
+
+// EffectiveConfig returns the configuration the server was started with in
+// config file form, e.g. to Dump it. Settings passed as libp2p or DHT options
+// are not reflected.
+func (s *DRPCServer) EffectiveConfig() config.File {
+	return s.effectiveConfig
+}
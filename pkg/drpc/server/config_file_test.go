@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestWithConfigFile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	key, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, _ := crypto.MarshalPrivateKey(key)
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	content := fmt.Sprintf(`
+http:
+  host: 127.0.0.1
+libp2p:
+  listen_addrs: [/ip4/127.0.0.1/tcp/0]
+identity:
+  private_key: %s
+discovery:
+  mdns: false
+  pubsub: false
+cors:
+  enabled: true
+  allowed_origins: [https://app.example]
+limits:
+  rate:
+    per_caller: {rate: 100, burst: 100}
+pool:
+  max_streams: 2
+`, base64.StdEncoding.EncodeToString(keyBytes))
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Options after the file override it
+	server := newPeerEchoServer(t, ctx, WithConfigFile(path), WithHTTPHost("localhost"))
+
+	wantID, _ := peer.IDFromPrivateKey(key)
+	if got := server.P2PHost().ID(); got != wantID {
+		t.Errorf("Peer ID = %s, want the configured identity %s", got, wantID)
+	}
+	sayHello(t, ctx, server.P2PAddrs()[0])
+	if _, ok := server.RateLimitStats(); !ok {
+		t.Error("Expected the configured rate limiter")
+	}
+
+	effective := server.EffectiveConfig()
+	if effective.HTTP.Host != "localhost" {
+		t.Errorf("Effective http.host = %q, want the overriding option", effective.HTTP.Host)
+	}
+	if !effective.CORS.Enabled || effective.CORS.AllowedOrigins[0] != "https://app.example" || len(effective.CORS.AllowedMethods) == 0 {
+		t.Errorf("Effective cors = %+v, want the configured origin and default methods", effective.CORS)
+	}
+	if effective.Pool.MaxStreams != 2 || effective.Discovery.MDNS {
+		t.Error("Effective config lost host level sections of the file")
+	}
+	dump, err := effective.Dump("yaml")
+	if err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	if strings.Contains(string(dump), base64.StdEncoding.EncodeToString(keyBytes)) {
+		t.Error("Dump leaks the private key")
+	}
+}
+
+func TestWithConfigFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.json")
+	if err := os.WriteFile(path, []byte(`{"http": {"port": -2}, "limits": {"rate": {"procedures": [{"procedure": "pkg.Service/", "rate": 1, "burst": 1}]}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := GetDefaultConfig()
+	err := WithConfigFile(path)(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "http.port") || !strings.Contains(err.Error(), "limits.rate.procedures[0]") {
+		t.Errorf("Expected errors for http.port and the procedure, got %v", err)
+	}
+}
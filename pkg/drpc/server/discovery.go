@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// muxServiceNames returns the services routed by mux, sorted by name. ServeMux
+// cannot list its patterns, so every service known to the global protobuf
+// registry is probed for a "/<service>/" route.
+func muxServiceNames(mux *http.ServeMux) []string {
+	if mux == nil {
+		return nil
+	}
+
+	var names []string
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := range services.Len() {
+			name := string(services.Get(i).FullName())
+			if muxRoutes(mux, "/"+name+"/") {
+				names = append(names, name)
+			}
+		}
+		return true
+	})
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/peer"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	dhost "github.com/omgolab/drpc/pkg/core/host"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestMuxServiceNames(t *testing.T) {
+	if names := muxServiceNames(http.NewServeMux()); len(names) != 0 {
+		t.Errorf("Expected no services on an empty mux, got %v", names)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(gv1connect.NewGreeterServiceHandler(peerEchoServer{}))
+	mux.Handle("/", http.NotFoundHandler())
+	if names := muxServiceNames(mux); !slices.Equal(names, []string{greeterServiceName}) {
+		t.Errorf("Expected [%s], got %v", greeterServiceName, names)
+	}
+}
+
+// dhtServerInfo returns the address info used to bootstrap other DHT nodes from server
+func dhtServerInfo(server *DRPCServer) peer.AddrInfo {
+	h := server.P2PHost()
+	return peer.AddrInfo{ID: h.ID(), Addrs: h.Addrs()}
+}
+
+func TestFindServerByServiceName(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// A loopback-only DHT: the provider answers queries and bootstraps the others
+	provider := newPeerEchoServer(t, ctx, WithDHTOptions(dht.Mode(dht.ModeServer), dht.BootstrapPeers()))
+	bootstrap := dht.BootstrapPeers(dhtServerInfo(provider))
+	logger, _ := glog.New()
+
+	if services := dhost.GetServiceDiscovery(provider.P2PHost()).Services(); !slices.Equal(services, []string{greeterServiceName}) {
+		t.Fatalf("Expected provider to advertise [%s], got %v", greeterServiceName, services)
+	}
+
+	t.Run("client", func(t *testing.T) {
+		c, err := client.NewForService(ctx, greeterServiceName, gv1connect.NewGreeterServiceClient,
+			client.WithLogger(logger),
+			client.WithLibp2pOptions(libp2p.NoListenAddrs),
+			client.WithDHTOptions(bootstrap),
+		)
+		if err != nil {
+			t.Fatalf("NewForService failed: %v", err)
+		}
+		resp, err := c.SayHello(ctx, newSayHelloRequest())
+		if err != nil {
+			t.Fatalf("SayHello failed: %v", err)
+		}
+		if !strings.HasPrefix(resp.Msg.Message, "libp2p|") {
+			t.Errorf("Expected a libp2p call, got %q", resp.Msg.Message)
+		}
+	})
+
+	t.Run("gateway", func(t *testing.T) {
+		gatewayServer := newPeerEchoServer(t, ctx, WithDHTOptions(bootstrap))
+		gatewayID := gatewayServer.P2PHost().ID().String()
+
+		msg := sayHello(t, ctx, gatewayServer.HTTPAddr()+"/@/service:"+greeterServiceName+"/@")
+		if msg != "gateway|"+gatewayID {
+			t.Errorf("Expected the gateway to forward to the provider, got %q", msg)
+		}
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		lookupCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+		if _, err := client.NewForService(lookupCtx, "unknown.v1.UnknownService", gv1connect.NewGreeterServiceClient,
+			client.WithLogger(logger),
+			client.WithLibp2pOptions(libp2p.NoListenAddrs),
+			client.WithDHTOptions(bootstrap),
+		); err == nil {
+			t.Error("Expected lookup of an unknown service to fail")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"connectrpc.com/connect"
+)
+
+// errServerDraining is returned to RPCs that arrive while the server is shutting down
+var errServerDraining = errors.New("server is shutting down")
+
+// connectErrorWriter writes Connect, gRPC and gRPC-Web errors from plain HTTP middleware
+var connectErrorWriter = connect.NewErrorWriter()
+
+// writeConnectError writes err in the wire format of the request's RPC protocol
+func writeConnectError(w http.ResponseWriter, r *http.Request, err error) {
+	_ = connectErrorWriter.Write(w, r, err)
+}
+
+// rpcTracker counts in-flight work and refuses new work once draining starts
+type rpcTracker struct {
+	mu       sync.Mutex
+	active   int
+	draining bool
+	idle     chan struct{} // closed once draining and no work is in flight
+}
+
+// newRPCTracker creates a tracker in the accepting state
+func newRPCTracker() *rpcTracker {
+	return &rpcTracker{idle: make(chan struct{})}
+}
+
+// begin registers new work; it returns false if the tracker is draining
+func (t *rpcTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return false
+	}
+	t.active++
+	return true
+}
+
+// end marks work registered with begin as finished
+func (t *rpcTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.active--
+	if t.draining && t.active == 0 {
+		close(t.idle)
+	}
+}
+
+// drain stops accepting new work and returns a channel that is closed
+// once all in-flight work has finished. It is safe to call more than once.
+func (t *rpcTracker) drain() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.draining {
+		t.draining = true
+		if t.active == 0 {
+			close(t.idle)
+		}
+	}
+	return t.idle
+}
+
+// isDraining reports whether drain has been called
+func (t *rpcTracker) isDraining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// Active returns the number of in-flight units of work
+func (t *rpcTracker) Active() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.active
+}
+
+// wrap tracks every request served by next and answers requests arriving
+// during drain with a Connect Unavailable error
+func (t *rpcTracker) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !t.begin() {
+			writeConnectError(w, r, connect.NewError(connect.CodeUnavailable, errServerDraining))
+			return
+		}
+		defer t.end()
+		next.ServeHTTP(w, r)
+	})
+}
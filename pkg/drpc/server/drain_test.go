@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+)
+
+func TestRPCTrackerRejectsWhileDraining(t *testing.T) {
+	tracker := newRPCTracker()
+	handler := tracker.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "OK")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/greeter.v1.GreeterService/SayHello", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 before drain, got %d", w.Code)
+	}
+
+	select {
+	case <-tracker.drain():
+	default:
+		t.Fatal("Drain of an idle tracker should complete immediately")
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 while draining, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"unavailable"`) {
+		t.Errorf("Expected Connect unavailable error, got %s", w.Body.String())
+	}
+}
+
+// newBlockingServer starts a server whose handler signals started and blocks until release is closed
+func newBlockingServer(t *testing.T, started chan<- struct{}, release <-chan struct{}) *DRPCServer {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		fmt.Fprint(w, "done")
+	})
+
+	server, err := New(context.Background(), mux, WithLibP2POptions(libp2p.NoListenAddrs), WithHTTPPort(0))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return server
+}
+
+func TestShutdownWaitsForInFlightRPCs(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := newBlockingServer(t, started, release)
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(server.HTTPAddr() + "/")
+		if err != nil {
+			respCh <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(testTimeout):
+		t.Fatal("Request never reached the handler")
+	}
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+		defer cancel()
+		shutdownErr <- server.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown returned before the in-flight RPC finished: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case err := <-shutdownErr:
+		if err != nil {
+			t.Fatalf("Shutdown failed: %v", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("Shutdown did not return after the RPC finished")
+	}
+
+	if body := <-respCh; body != "done" {
+		t.Errorf("In-flight RPC was not completed: %q", body)
+	}
+}
+
+func TestShutdownDeadlineExceeded(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := newBlockingServer(t, started, release)
+
+	go func() {
+		resp, err := http.Get(server.HTTPAddr() + "/")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	// Let the handler finish shortly after the drain deadline so Close can complete
+	time.AfterFunc(time.Second, func() { close(release) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	err := server.Shutdown(ctx)
+	if err == nil || !strings.Contains(err.Error(), "in flight") {
+		t.Fatalf("Expected in-flight deadline error, got %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/events"
+)
+
+const (
+	// maxStatusBytes bounds the end of a response kept to read the RPC status from
+	maxStatusBytes = 4 << 10
+
+	envelopeFlagCompressed = 0b00000001
+	connectFlagEndStream   = 0b00000010
+	grpcWebFlagTrailer     = 0b10000000
+)
+
+// WithEventHandler calls handler with every lifecycle event of the server,
+// see package events. It is called on a single goroutine in the order of the
+// events, from before the server starts listening until it closes. Handlers
+// never hold up RPCs, but events emitted while a slow handler lags too far
+// behind are dropped.
+func WithEventHandler(handler func(evt any)) ServerOption {
+	return func(cfg *Config) error {
+		if handler == nil {
+			return errors.New("event handler cannot be nil")
+		}
+		cfg.eventHandler = handler
+		return nil
+	}
+}
+
+// Events subscribes to the lifecycle events of the server, see package
+// events. The subscription should be drained promptly and closed once done:
+// events are dropped, and counted in the drpc_events_dropped_total metric,
+// while the server's event queue is full. Events emitted before the call,
+// like the HTTP listener becoming ready, are only delivered to WithEventHandler.
+func (s *DRPCServer) Events(opts ...event.SubscriptionOpt) (event.Subscription, error) {
+	h := s.P2PHost()
+	if h == nil {
+		return nil, errors.New("libp2p host is not running")
+	}
+	return events.Subscribe(h.EventBus(), opts...)
+}
+
+// emitter returns the emitter of the server's events, nil before its host exists
+func (s *DRPCServer) emitter() *events.Emitter {
+	if s.p2pManager == nil {
+		return nil
+	}
+	return s.p2pManager.events
+}
+
+// emitRPCEvents emits the start and end of every RPC served by next
+func (s *DRPCServer) emitRPCEvents(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		emitter := s.emitter()
+		if emitter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		caller, _ := core.PeerInfoFromContext(r.Context())
+		emitter.Emit(events.EvtRPCStarted{Procedure: r.URL.Path, Caller: caller})
+
+		start := time.Now()
+		body := &countingBody{ReadCloser: r.Body}
+		r.Body = body
+		rec := &rpcRecorder{ResponseWriter: w, enveloped: isEnveloped(r)}
+		next.ServeHTTP(rec, r)
+
+		emitter.Emit(events.EvtRPCFinished{
+			Procedure:     r.URL.Path,
+			Caller:        caller,
+			Code:          rec.code(),
+			RequestBytes:  body.read,
+			ResponseBytes: rec.written,
+			Duration:      time.Since(start),
+		})
+	})
+}
+
+// countingBody counts the bytes read from a request body
+type countingBody struct {
+	io.ReadCloser
+	read int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+// rpcRecorder counts the bytes of a response and keeps its end, which
+// carries the status of failed unary Connect calls, Connect streams and
+// gRPC-Web calls. gRPC sends it in headers or trailers.
+type rpcRecorder struct {
+	http.ResponseWriter
+	enveloped bool
+	status    int
+	written   int64
+	tail      []byte // last bytes written, up to maxStatusBytes
+	envelopes envelopeScanner
+	lastFlags byte  // flags of the last enveloped message
+	lastStart int64 // offset of the last enveloped message
+	lastSize  int64
+}
+
+func (w *rpcRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *rpcRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	data := p[:n]
+	if w.enveloped {
+		_ = w.envelopes.scan(data, func(end int, size int64) error {
+			w.lastFlags = w.envelopes.flags()
+			w.lastStart = w.written + int64(end)
+			w.lastSize = size
+			return nil
+		})
+	}
+	w.written += int64(n)
+
+	w.tail = append(w.tail, data...)
+	if over := len(w.tail) - maxStatusBytes; over > 0 {
+		w.tail = append(w.tail[:0], w.tail[over:]...)
+	}
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (w *rpcRecorder) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *rpcRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// code returns the status the RPC ended with, 0 if it succeeded
+func (w *rpcRecorder) code() connect.Code {
+	header := w.Header()
+	for _, key := range []string{"Grpc-Status", http.TrailerPrefix + "Grpc-Status"} {
+		if value := header.Get(key); value != "" {
+			return grpcCode(value)
+		}
+	}
+
+	if !w.enveloped {
+		if w.status == 0 || w.status == http.StatusOK {
+			return 0
+		}
+		// The body of a failed unary call is the error
+		var body struct {
+			Code connect.Code `json:"code"`
+		}
+		if w.written > int64(len(w.tail)) || json.Unmarshal(w.tail, &body) != nil || body.Code == 0 {
+			return connect.CodeUnknown
+		}
+		return body.Code
+	}
+
+	ended := w.lastFlags&(connectFlagEndStream|grpcWebFlagTrailer) != 0
+	if !ended || w.lastStart+w.lastSize != w.written || w.lastSize > int64(len(w.tail)) {
+		if w.status != 0 && w.status != http.StatusOK {
+			return connect.CodeUnknown
+		}
+		return 0
+	}
+	end := w.tail[int64(len(w.tail))-w.lastSize:]
+	if w.lastFlags&envelopeFlagCompressed != 0 {
+		encoding := header.Get("Connect-Content-Encoding")
+		if encoding == "" {
+			encoding = header.Get("Grpc-Encoding")
+		}
+		var err error
+		if end, err = gunzip(encoding, end); err != nil {
+			return connect.CodeUnknown
+		}
+	}
+
+	if w.lastFlags&grpcWebFlagTrailer != 0 {
+		trailer, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(append(end, '\r', '\n')))).ReadMIMEHeader()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return connect.CodeUnknown
+		}
+		return grpcCode(trailer.Get("Grpc-Status"))
+	}
+	var endStream struct {
+		Error *struct {
+			Code connect.Code `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(end, &endStream); err != nil {
+		return connect.CodeUnknown
+	}
+	if endStream.Error == nil {
+		return 0
+	}
+	if endStream.Error.Code == 0 {
+		return connect.CodeUnknown
+	}
+	return endStream.Error.Code
+}
+
+// grpcCode parses a grpc-status value
+func grpcCode(value string) connect.Code {
+	n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+	if err != nil {
+		return connect.CodeUnknown
+	}
+	return connect.Code(n)
+}
+
+// gunzip decompresses data compressed with encoding, the only one servers use by default
+func gunzip(encoding string, data []byte) ([]byte, error) {
+	if encoding != "gzip" {
+		return nil, errors.New("unsupported encoding " + encoding)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(io.LimitReader(r, maxStatusBytes))
+}
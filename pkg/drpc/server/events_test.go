@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	gv1 "github.com/omgolab/drpc/demo/gen/go/greeter/v1"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/events"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// statusServer fails SayHello for the name "missing" and streams end with PermissionDenied
+type statusServer struct {
+	gv1connect.UnimplementedGreeterServiceHandler
+}
+
+func (statusServer) SayHello(_ context.Context, req *connect.Request[gv1.SayHelloRequest]) (*connect.Response[gv1.SayHelloResponse], error) {
+	if req.Msg.Name == "missing" {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("no such name"))
+	}
+	return connect.NewResponse(&gv1.SayHelloResponse{Message: "hello " + req.Msg.Name}), nil
+}
+
+func (statusServer) StreamingEcho(_ context.Context, req *connect.Request[gv1.StreamingEchoRequest], stream *connect.ServerStream[gv1.StreamingEchoResponse]) error {
+	if err := stream.Send(&gv1.StreamingEchoResponse{Message: req.Msg.Message}); err != nil {
+		return err
+	}
+	return connect.NewError(connect.CodePermissionDenied, errors.New("no more"))
+}
+
+// eventRecorder collects events without blocking the emitter
+type eventRecorder chan any
+
+func (r eventRecorder) handle(evt any) {
+	select {
+	case r <- evt:
+	default:
+	}
+}
+
+// waitForEvent returns the first event of type T matching match
+func waitForEvent[T any](t *testing.T, ch <-chan any, match func(T) bool) T {
+	t.Helper()
+	timeout := time.After(testTimeout)
+	for {
+		select {
+		case evt := <-ch:
+			if e, ok := evt.(T); ok && match(e) {
+				return e
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("Timed out waiting for %T", zero)
+			return zero
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	recorded := make(eventRecorder, 1024)
+	mux := http.NewServeMux()
+	mux.Handle(gv1connect.NewGreeterServiceHandler(statusServer{}))
+	server, err := New(ctx, mux,
+		WithLibP2POptions(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0")),
+		WithHTTPPort(0),
+		WithEventHandler(recorded.handle),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	ready := waitForEvent(t, recorded, func(events.EvtHTTPListenerReady) bool { return true })
+	if ready.Addr != server.HTTPAddr() {
+		t.Errorf("Ready event address = %q, want %q", ready.Addr, server.HTTPAddr())
+	}
+
+	const sayHelloProcedure = "/greeter.v1.GreeterService/SayHello"
+	isSayHello := func(e events.EvtRPCFinished) bool { return e.Procedure == sayHelloProcedure }
+	for _, protocol := range []struct {
+		name string
+		opts []connect.ClientOption
+	}{
+		{"connect", nil},
+		{"connect gzip", []connect.ClientOption{connect.WithSendGzip()}},
+		{"grpc", []connect.ClientOption{connect.WithGRPC()}},
+		{"grpc-web", []connect.ClientOption{connect.WithGRPCWeb()}},
+	} {
+		c, err := client.New(ctx, server.HTTPAddr(), gv1connect.NewGreeterServiceClient, client.WithConnectOptions(protocol.opts...))
+		if err != nil {
+			t.Fatalf("%s: failed to create client: %v", protocol.name, err)
+		}
+
+		if _, err := c.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: "events"})); err != nil {
+			t.Fatalf("%s: SayHello failed: %v", protocol.name, err)
+		}
+		finished := waitForEvent(t, recorded, isSayHello)
+		if finished.Code != 0 || finished.Caller.Entry != core.EntryHTTP || finished.RequestBytes == 0 || finished.ResponseBytes == 0 {
+			t.Errorf("%s: unexpected finished event for a successful call: %+v", protocol.name, finished)
+		}
+
+		if _, err := c.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: "missing"})); connect.CodeOf(err) != connect.CodeNotFound {
+			t.Fatalf("%s: SayHello returned %v, want NotFound", protocol.name, err)
+		}
+		if finished := waitForEvent(t, recorded, isSayHello); finished.Code != connect.CodeNotFound {
+			t.Errorf("%s: failed call finished with %v, want NotFound", protocol.name, finished.Code)
+		}
+
+		stream, err := c.StreamingEcho(ctx, connect.NewRequest(&gv1.StreamingEchoRequest{Message: "events"}))
+		if err != nil {
+			t.Fatalf("%s: StreamingEcho failed: %v", protocol.name, err)
+		}
+		for stream.Receive() {
+		}
+		_ = stream.Close()
+		finished = waitForEvent(t, recorded, func(e events.EvtRPCFinished) bool { return e.Procedure != sayHelloProcedure })
+		if finished.Code != connect.CodePermissionDenied {
+			t.Errorf("%s: stream finished with %v, want PermissionDenied", protocol.name, finished.Code)
+		}
+	}
+
+	// A libp2p client sees the server connect and the server sees the client's RPCs
+	sub, err := server.Events()
+	if err != nil {
+		t.Fatalf("Events failed: %v", err)
+	}
+	defer sub.Close()
+	clientEvents := make(eventRecorder, 1024)
+	logger, _ := glog.New()
+	c, err := client.New(ctx, server.P2PAddrs()[0], gv1connect.NewGreeterServiceClient,
+		client.WithLogger(logger),
+		client.WithLibp2pOptions(libp2p.NoListenAddrs),
+		client.WithEventHandler(clientEvents.handle),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create libp2p client: %v", err)
+	}
+	waitForEvent(t, clientEvents, func(e events.EvtPeerConnected) bool { return e.Peer == server.P2PHost().ID() })
+
+	if _, err := c.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: "libp2p"})); err != nil {
+		t.Fatalf("SayHello over libp2p failed: %v", err)
+	}
+	started := waitForEvent(t, sub.Out(), func(e events.EvtRPCStarted) bool { return e.Procedure == sayHelloProcedure })
+	if started.Caller.Entry != core.EntryLibp2p || started.Caller.ID == "" {
+		t.Errorf("Unexpected caller of a libp2p call: %+v", started.Caller)
+	}
+	if finished := waitForEvent(t, sub.Out(), isSayHello); finished.Caller.ID != started.Caller.ID || finished.Code != 0 {
+		t.Errorf("Unexpected finished event of a libp2p call: %+v", finished)
+	}
+}
+
+func TestSlowEventHandlerDoesNotStallRPCs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	release := make(chan struct{})
+	server := newPeerEchoServer(t, ctx, WithEventHandler(func(any) { <-release }))
+	t.Cleanup(func() { close(release) })
+
+	c, err := newGreeterClient(ctx, server.P2PAddrs()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 50 {
+		callCtx, callCancel := context.WithTimeout(ctx, 2*time.Second)
+		_, err := c.SayHello(callCtx, newSayHelloRequest())
+		callCancel()
+		if err != nil {
+			t.Fatalf("SayHello with a stuck event handler failed: %v", err)
+		}
+	}
+}
+
+func TestWithEventHandlerValidation(t *testing.T) {
+	cfg := GetDefaultConfig()
+	if err := WithEventHandler(nil)(&cfg); err == nil {
+		t.Error("Expected a nil event handler to be rejected")
+	}
+}
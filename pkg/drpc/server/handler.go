@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+
+	"connectrpc.com/grpcreflect"
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1/adminv1connect"
+)
+
+// buildRPCHandler wraps the ConnectRPC mux with the per-RPC pipeline shared
+// by the libp2p, web stream, gateway and local HTTP paths. known reports the
+// procedures mux routes, which metrics and traces are labelled with.
+func (s *DRPCServer) buildRPCHandler(cfg *Config, mux http.Handler, known func(procedure string) bool) http.Handler {
+	handler := mux
+	if len(cfg.interceptors) > 0 {
+		handler = interceptorHandler(cfg.interceptors, cfg.logger, handler)
+	}
+	for i := len(cfg.httpMiddleware) - 1; i >= 0; i-- {
+		handler = cfg.httpMiddleware[i](handler)
+	}
+	if cfg.sizeLimits != nil {
+		// Inside the policy and rate limiter, which reject calls without reading them
+		handler = cfg.sizeLimits.wrap(handler)
+	}
+	if cfg.accessPolicy != nil {
+		handler = cfg.accessPolicy.wrap(handler)
+	}
+	if s.rateLimiter != nil {
+		// Ahead of the policy so floods of denied calls are throttled as well
+		handler = s.rateLimiter.wrap(handler)
+	}
+	if s.metrics != nil {
+		// Record rejected calls too, labelled with the caller's entry path
+		handler = s.metrics.wrap(handler, known)
+	}
+	if s.tracing != nil {
+		handler = s.tracing.wrap(handler, known)
+	}
+	// Report rejected calls too, with the status they were rejected with
+	handler = s.emitRPCEvents(handler)
+
+	// Outermost so every stage sees the caller identity
+	return core.PeerInfoHandler(handler)
+}
+
+// mountBuiltinServices routes the built-in services enabled in cfg next to the user's mux
+func (s *DRPCServer) mountBuiltinServices(cfg *Config, mux http.Handler) http.Handler {
+	if s.health == nil && !cfg.reflection && cfg.admin == nil {
+		return mux
+	}
+
+	routes := http.NewServeMux()
+	if s.health != nil {
+		routes.Handle(s.health.handler())
+	}
+	if cfg.admin != nil {
+		path, handler := adminv1connect.NewAdminServiceHandler(&adminService{server: s})
+		routes.Handle(path, cfg.admin.wrap(handler))
+	}
+	if cfg.reflection {
+		reflector := s.newReflector(cfg)
+		routes.Handle(grpcreflect.NewHandlerV1(reflector))
+		routes.Handle(grpcreflect.NewHandlerV1Alpha(reflector))
+	}
+	s.builtinRoutes = routes
+	routes.Handle("/", mux)
+	return routes
+}
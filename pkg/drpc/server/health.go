@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"connectrpc.com/connect"
+	healthv1 "github.com/omgolab/drpc/pkg/drpc/proto/drpcext/grpc/health/v1"
+)
+
+// ServingStatus is the status reported by the health service
+type ServingStatus = healthv1.HealthCheckResponse_ServingStatus
+
+// Serving statuses reported by the health service
+const (
+	StatusUnknown        = healthv1.HealthCheckResponse_UNKNOWN
+	StatusServing        = healthv1.HealthCheckResponse_SERVING
+	StatusNotServing     = healthv1.HealthCheckResponse_NOT_SERVING
+	StatusServiceUnknown = healthv1.HealthCheckResponse_SERVICE_UNKNOWN
+)
+
+// The health service is served under the standard grpc.health.v1 names, so
+// that probes such as grpc_health_probe find it. Its messages come from a
+// private proto package that cannot clash with grpc-go's in the registry.
+const (
+	// HealthServiceName is the fully-qualified name of the health service
+	HealthServiceName = "grpc.health.v1.Health"
+	// HealthCheckProcedure is the path of the Health.Check RPC
+	HealthCheckProcedure = "/" + HealthServiceName + "/Check"
+	// HealthWatchProcedure is the path of the Health.Watch RPC
+	HealthWatchProcedure = "/" + HealthServiceName + "/Watch"
+)
+
+// HealthService implements grpc.health.v1.Health for a DRPCServer.
+// The empty service name reports the server itself, which is serving while
+// the libp2p and HTTP listeners are up and the server is not draining.
+// Named services report the status set with SetServingStatus, and are
+// NOT_SERVING whenever the server itself is not serving.
+type HealthService struct {
+	overall func() ServingStatus
+
+	mu       sync.Mutex
+	statuses map[string]ServingStatus
+	changed  chan struct{} // closed and replaced on every status change
+	done     chan struct{} // closed once the server stops
+	stopped  bool
+}
+
+// newHealthService creates a health service whose overall status is computed by overall
+func newHealthService(overall func() ServingStatus) *HealthService {
+	return &HealthService{
+		overall:  overall,
+		statuses: make(map[string]ServingStatus),
+		changed:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// SetServingStatus sets the status reported for a named service
+func (h *HealthService) SetServingStatus(service string, status ServingStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.statuses[service] = status
+	h.notifyLocked()
+}
+
+// ClearServingStatus forgets a named service; it is then reported as unknown
+func (h *HealthService) ClearServingStatus(service string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.statuses, service)
+	h.notifyLocked()
+}
+
+// Check implements grpc.health.v1.Health.Check
+func (h *HealthService) Check(
+	_ context.Context,
+	req *connect.Request[healthv1.HealthCheckRequest],
+) (*connect.Response[healthv1.HealthCheckResponse], error) {
+	status, _ := h.status(req.Msg.Service)
+	if status == StatusServiceUnknown {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("unknown service %q", req.Msg.Service))
+	}
+	return connect.NewResponse(&healthv1.HealthCheckResponse{Status: status}), nil
+}
+
+// Watch implements grpc.health.v1.Health.Watch. It sends the current status and
+// then every change, and ends after reporting NOT_SERVING once the server stops.
+func (h *HealthService) Watch(
+	ctx context.Context,
+	req *connect.Request[healthv1.HealthCheckRequest],
+	stream *connect.ServerStream[healthv1.HealthCheckResponse],
+) error {
+	last := ServingStatus(-1)
+	for {
+		status, changed := h.status(req.Msg.Service)
+		if status != last {
+			if err := stream.Send(&healthv1.HealthCheckResponse{Status: status}); err != nil {
+				return err
+			}
+			last = status
+		}
+
+		select {
+		case <-changed:
+		case <-h.done:
+			// Stopping servers must not hold on to watchers while draining
+			if last != StatusNotServing {
+				return stream.Send(&healthv1.HealthCheckResponse{Status: StatusNotServing})
+			}
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// handler returns the path and HTTP handler serving the health service
+func (h *HealthService) handler(opts ...connect.HandlerOption) (string, http.Handler) {
+	check := connect.NewUnaryHandler(HealthCheckProcedure, h.Check, opts...)
+	watch := connect.NewServerStreamHandler(HealthWatchProcedure, h.Watch, opts...)
+	return "/" + HealthServiceName + "/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case HealthCheckProcedure:
+			check.ServeHTTP(w, r)
+		case HealthWatchProcedure:
+			watch.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// HealthClient calls the grpc.health.v1.Health service of a server
+type HealthClient struct {
+	check *connect.Client[healthv1.HealthCheckRequest, healthv1.HealthCheckResponse]
+	watch *connect.Client[healthv1.HealthCheckRequest, healthv1.HealthCheckResponse]
+}
+
+// NewHealthClient creates a health client for the server at baseURL. It has
+// the signature of generated Connect client constructors, to be passed to
+// client.New.
+func NewHealthClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) *HealthClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &HealthClient{
+		check: connect.NewClient[healthv1.HealthCheckRequest, healthv1.HealthCheckResponse](httpClient, baseURL+HealthCheckProcedure, opts...),
+		watch: connect.NewClient[healthv1.HealthCheckRequest, healthv1.HealthCheckResponse](httpClient, baseURL+HealthWatchProcedure, opts...),
+	}
+}
+
+// Check calls grpc.health.v1.Health.Check
+func (c *HealthClient) Check(ctx context.Context, req *connect.Request[healthv1.HealthCheckRequest]) (*connect.Response[healthv1.HealthCheckResponse], error) {
+	return c.check.CallUnary(ctx, req)
+}
+
+// Watch calls grpc.health.v1.Health.Watch
+func (c *HealthClient) Watch(ctx context.Context, req *connect.Request[healthv1.HealthCheckRequest]) (*connect.ServerStreamForClient[healthv1.HealthCheckResponse], error) {
+	return c.watch.CallServerStream(ctx, req)
+}
+
+// status returns the status of service and a channel closed on the next change
+func (h *HealthService) status(service string) (ServingStatus, <-chan struct{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	overall := StatusNotServing
+	if !h.stopped {
+		overall = h.overall()
+	}
+	if service == "" {
+		return overall, h.changed
+	}
+
+	status, ok := h.statuses[service]
+	switch {
+	case !ok:
+		return StatusServiceUnknown, h.changed
+	case overall != StatusServing:
+		return StatusNotServing, h.changed
+	default:
+		return status, h.changed
+	}
+}
+
+// refresh wakes up watchers after the server state changed
+func (h *HealthService) refresh() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.notifyLocked()
+}
+
+// stop reports NOT_SERVING from now on and ends all watchers
+func (h *HealthService) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopped {
+		return
+	}
+	h.stopped = true
+	close(h.done)
+	h.notifyLocked()
+}
+
+func (h *HealthService) notifyLocked() {
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
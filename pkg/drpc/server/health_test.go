@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	healthv1 "github.com/omgolab/drpc/pkg/drpc/proto/drpcext/grpc/health/v1"
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const greeterServiceName = "greeter.v1.GreeterService"
+
+func newHealthClient(t *testing.T, ctx context.Context, addr string) *HealthClient {
+	t.Helper()
+
+	logger, _ := glog.New()
+	c, err := client.New(ctx, addr, NewHealthClient,
+		client.WithLogger(logger),
+		client.WithLibp2pOptions(libp2p.NoListenAddrs),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create health client for %s: %v", addr, err)
+	}
+	return c
+}
+
+func checkHealth(ctx context.Context, c *HealthClient, service string) (ServingStatus, error) {
+	resp, err := c.Check(ctx, connect.NewRequest(&healthv1.HealthCheckRequest{Service: service}))
+	if err != nil {
+		return StatusUnknown, err
+	}
+	return resp.Msg.Status, nil
+}
+
+func TestHealthCheck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx, WithHealthService())
+	server.Health().SetServingStatus(greeterServiceName, StatusServing)
+
+	for _, addr := range []string{server.HTTPAddr(), server.P2PAddrs()[0]} {
+		c := newHealthClient(t, ctx, addr)
+		if status, err := checkHealth(ctx, c, ""); err != nil || status != StatusServing {
+			t.Fatalf("Overall health via %s: status=%v err=%v", addr, status, err)
+		}
+		if status, err := checkHealth(ctx, c, greeterServiceName); err != nil || status != StatusServing {
+			t.Fatalf("Service health via %s: status=%v err=%v", addr, status, err)
+		}
+	}
+
+	c := newHealthClient(t, ctx, server.HTTPAddr())
+	server.Health().SetServingStatus(greeterServiceName, StatusNotServing)
+	if status, _ := checkHealth(ctx, c, greeterServiceName); status != StatusNotServing {
+		t.Errorf("Expected NOT_SERVING after update, got %v", status)
+	}
+
+	server.Health().ClearServingStatus(greeterServiceName)
+	if _, err := checkHealth(ctx, c, greeterServiceName); connect.CodeOf(err) != connect.CodeNotFound {
+		t.Errorf("Expected NotFound for unknown service, got %v", err)
+	}
+
+	// The application mux keeps serving next to the health service
+	if _, err := callSayHello(ctx, server.HTTPAddr()); err != nil {
+		t.Errorf("Application handler unreachable: %v", err)
+	}
+}
+
+func TestHealthWatchEndsOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx, WithHealthService())
+	c := newHealthClient(t, ctx, server.HTTPAddr())
+
+	stream, err := c.Watch(ctx, connect.NewRequest(&healthv1.HealthCheckRequest{Service: greeterServiceName}))
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer stream.Close()
+
+	expect := func(want ServingStatus) {
+		t.Helper()
+		if !stream.Receive() {
+			t.Fatalf("Watch ended early: %v", stream.Err())
+		}
+		if got := stream.Msg().Status; got != want {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+
+	expect(StatusServiceUnknown)
+	server.Health().SetServingStatus(greeterServiceName, StatusServing)
+	expect(StatusServing)
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer shutdownCancel()
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- server.Shutdown(shutdownCtx) }()
+
+	expect(StatusNotServing)
+	if stream.Receive() {
+		t.Fatalf("Expected watch to end after shutdown, got %v", stream.Msg().Status)
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown blocked by health watcher: %v", err)
+	}
+}
+
+func TestHealthSchemaDoesNotClashWithGRPC(t *testing.T) {
+	// grpc-go registers grpc.health.v1 under this path; linking both must not panic
+	if _, err := protoregistry.GlobalFiles.FindFileByPath("grpc/health/v1/health.proto"); err == nil {
+		t.Error("Health schema registered under the grpc-go file path")
+	}
+	if _, err := protoregistry.GlobalFiles.FindDescriptorByName("grpc.health.v1.Health"); err == nil {
+		t.Error("Health schema registered under the grpc-go package")
+	}
+
+	// Probes only know the standard procedure path
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	server := newPeerEchoServer(t, ctx, WithHealthService())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost,
+		server.HTTPAddr()+"/grpc.health.v1.Health/Check", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "SERVING") {
+		t.Errorf("Check on the standard path = %d %s, want SERVING", resp.StatusCode, body)
+	}
+}
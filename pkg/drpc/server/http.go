@@ -2,13 +2,18 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/omgolab/drpc/pkg/core/events"
+	"github.com/omgolab/drpc/pkg/core/tracing"
 	"github.com/omgolab/drpc/pkg/gateway"
 	"github.com/omgolab/drpc/pkg/proc"
 	glog "github.com/omgolab/go-commons/pkg/log"
@@ -18,22 +23,29 @@ import (
 
 // HTTPServerManager handles HTTP server functionality
 type HTTPServerManager struct {
-	server     *http.Server
-	listener   net.Listener
-	readyCh    chan struct{}
-	logger     glog.Logger
-	ctx        context.Context
-	handlerMux *http.ServeMux
+	server   *http.Server
+	listener net.Listener
+	readyCh  chan struct{}
+	logger   glog.Logger
+	ctx      context.Context
+	handler  http.Handler
+	tracker  *rpcTracker     // optional; tracks requests for graceful drain
+	metrics  *serverMetrics  // optional; served at the configured metrics path
+	events   *events.Emitter // optional; listener ready and failed events
+	health   *HealthService  // optional; refreshed when the listener is ready or fails
+	// namespaces are served under their path prefix
+	namespaces []*p2pNamespace
+	certs      *certReloader
 	mu         sync.RWMutex
 }
 
 // NewHTTPServerManager creates a new HTTP server manager
-func NewHTTPServerManager(ctx context.Context, handlerMux *http.ServeMux, logger glog.Logger) *HTTPServerManager {
+func NewHTTPServerManager(ctx context.Context, handler http.Handler, logger glog.Logger) *HTTPServerManager {
 	return &HTTPServerManager{
-		ctx:        ctx,
-		handlerMux: handlerMux,
-		logger:     logger,
-		readyCh:    make(chan struct{}),
+		ctx:     ctx,
+		handler: handler,
+		logger:  logger,
+		readyCh: make(chan struct{}),
 	}
 }
 
@@ -48,9 +60,36 @@ func (h *HTTPServerManager) Setup(cfg *Config, p2pHost host.Host) error {
 		}
 	}
 
+	// Load the TLS key pair up front so a bad certificate fails Setup
+	var tlsConfig *tls.Config
+	if cfg.tls != nil {
+		certs, err := newCertReloader(cfg.tls.certFile, cfg.tls.keyFile, cfg.logger)
+		if err != nil {
+			return err
+		}
+		h.certs = certs
+		tlsConfig = serverTLSConfig(certs)
+	}
+
 	// Create HTTP server with gateway handler
-	httpHandler := gateway.SetupHandler(h.handlerMux, cfg.logger, p2pHost, cfg.corsConfig)
-	httpServer, err := createHTTP2Server(httpHandler, httpAddr)
+	httpHandler := gateway.SetupHandler(h.handler, cfg.logger, p2pHost, cfg.corsConfig)
+	httpHandler = namespaceRoutes(cfg, h.namespaces, httpHandler)
+	if h.tracker != nil {
+		// Track gateway-forwarded calls as well as local ones
+		httpHandler = h.tracker.wrap(httpHandler)
+	}
+	if cfg.tracerProvider != nil {
+		// Gateway spans are created before the RPC pipeline is reached
+		httpHandler = tracing.Handler(cfg.tracerProvider, httpHandler)
+	}
+	if h.metrics != nil {
+		// Outside the tracker so nodes can still be scraped while draining
+		routes := http.NewServeMux()
+		routes.Handle(cfg.metricsPath, h.metrics.handler())
+		routes.Handle("/", httpHandler)
+		httpHandler = routes
+	}
+	httpServer, err := createHTTP2Server(httpHandler, httpAddr, tlsConfig, cfg.useH2C(), cfg.http2Server())
 	if err != nil {
 		return err
 	}
@@ -71,15 +110,23 @@ func (h *HTTPServerManager) serve(httpAddr string) {
 		return
 	}
 
+	if h.certs != nil {
+		l = tls.NewListener(l, h.server.TLSConfig)
+	}
+
 	h.mu.Lock()
 	h.listener = l
 	h.mu.Unlock()
 
-	h.logger.Info("HTTP server listening", glog.LogFields{"address": httpAddr})
+	h.logger.Info("HTTP server listening", glog.LogFields{"address": httpAddr, "tls": h.certs != nil})
 
 	if h.readyCh != nil {
 		close(h.readyCh) // Signal that listener is ready
 	}
+	h.events.Emit(events.EvtHTTPListenerReady{Addr: h.formatListenerAddr()})
+	if h.health != nil {
+		h.health.refresh()
+	}
 
 	if err := h.server.Serve(l); err != http.ErrServerClosed {
 		h.handleError("HTTP server error", err)
@@ -89,6 +136,7 @@ func (h *HTTPServerManager) serve(httpAddr string) {
 // handleError logs an HTTP error and updates server state
 func (h *HTTPServerManager) handleError(msg string, err error) {
 	h.logger.Error(msg, err)
+	h.events.Emit(events.EvtHTTPListenerFailed{Err: fmt.Errorf("%s: %w", strings.ToLower(msg), err)})
 
 	h.mu.Lock()
 	h.listener = nil
@@ -102,6 +150,9 @@ func (h *HTTPServerManager) handleError(msg string, err error) {
 		// Channel is open, close it
 		close(h.readyCh)
 	}
+	if h.health != nil {
+		h.health.refresh()
+	}
 }
 
 // Server returns the underlying HTTP server
@@ -146,6 +197,13 @@ func (h *HTTPServerManager) HTTPAddr() string {
 	return ""
 }
 
+// isListening reports whether the HTTP listener is up
+func (h *HTTPServerManager) isListening() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.listener != nil
+}
+
 // formatListenerAddr returns the formatted listener address with the appropriate scheme
 func (h *HTTPServerManager) formatListenerAddr() string {
 	h.mu.RLock()
@@ -156,13 +214,34 @@ func (h *HTTPServerManager) formatListenerAddr() string {
 	}
 
 	addr := h.listener.Addr().String()
-	// Check if TLS/SSL is configured
-	// if h.server.TLSConfig != nil {
-	// 	return "https://" + addr
-	// }
+	// http2.ConfigureServer always populates TLSConfig, so check for loaded certificates instead
+	if h.certs != nil {
+		return "https://" + addr
+	}
 	return "http://" + addr
 }
 
+// ReloadCertificates re-reads the TLS key pair from disk.
+// Certificates are also picked up automatically when the files change.
+func (h *HTTPServerManager) ReloadCertificates() error {
+	if h.certs == nil {
+		return errors.New("TLS is not configured")
+	}
+	return h.certs.Reload()
+}
+
+// Shutdown stops the listener, sends GOAWAY on HTTP/2 connections and waits
+// for active connections to become idle until ctx is done
+func (h *HTTPServerManager) Shutdown(ctx context.Context) error {
+	if h.server == nil {
+		return nil
+	}
+	if err := h.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("http server shutdown error: %w", err)
+	}
+	return nil
+}
+
 // Close gracefully shuts down the HTTP server
 func (h *HTTPServerManager) Close() error {
 	if h.server == nil {
@@ -183,12 +262,16 @@ func (h *HTTPServerManager) Close() error {
 	return nil
 }
 
-// createHTTP2Server creates an HTTP server with HTTP/2 support
-func createHTTP2Server(handler http.Handler, addr string) (*http.Server, error) {
-	h2s := &http2.Server{}
+// createHTTP2Server creates an HTTP server with HTTP/2 support using the settings of h2s.
+// With a TLS config h2 is negotiated via ALPN; allowH2C additionally accepts cleartext HTTP/2.
+func createHTTP2Server(handler http.Handler, addr string, tlsConfig *tls.Config, allowH2C bool, h2s *http2.Server) (*http.Server, error) {
+	if allowH2C {
+		handler = h2c.NewHandler(handler, h2s)
+	}
 	server := &http.Server{
-		Handler: h2c.NewHandler(handler, h2s),
-		Addr:    addr,
+		Handler:   handler,
+		Addr:      addr,
+		TLSConfig: tlsConfig,
 	}
 
 	// Configure the server for HTTP/2
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/omgolab/drpc/pkg/core/identity"
+)
+
+func TestWithIdentityFile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "server.key")
+	first := newPeerEchoServer(t, ctx, WithIdentityFile(path, identity.WithKeyType(identity.Secp256k1)))
+	id := first.P2PHost().ID()
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// A restarted server keeps its peer ID, and so its addresses
+	restarted := newPeerEchoServer(t, ctx, WithIdentityFile(path))
+	if got := restarted.P2PHost().ID(); got != id {
+		t.Errorf("Restarted server has peer ID %s, want %s", got, id)
+	}
+	if got := restarted.EffectiveConfig().Identity.KeyFile; got != path {
+		t.Errorf("Effective identity.key_file = %q, want %q", got, path)
+	}
+	sayHello(t, ctx, restarted.P2PAddrs()[0])
+}
+
+func TestWithIdentity(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	key, err := identity.FromSeed([]byte(t.Name()), identity.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newPeerEchoServer(t, ctx, WithIdentity(key))
+	if want, _ := peer.IDFromPrivateKey(key); server.P2PHost().ID() != want {
+		t.Errorf("Peer ID = %s, want the seeded %s", server.P2PHost().ID(), want)
+	}
+}
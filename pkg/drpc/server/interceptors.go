@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/omgolab/drpc/pkg/core"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// errPayloadUnavailable is returned by Receive and Send on the connection seen by server-level interceptors
+var errPayloadUnavailable = errors.New("message payloads are not available to server-level interceptors")
+
+// WithInterceptors runs connect interceptors once per RPC on every entry path
+// (libp2p, web stream, gateway-forwarded and local HTTP) before the request
+// reaches the mux.
+//
+// Interceptors are invoked through WrapStreamingHandler for every call type,
+// since messages are still encoded at this layer: the connection exposes the
+// procedure, peer, headers and context, but Receive and Send fail. The caller's
+// transport is available through drpc.PeerFromContext. Returning an error
+// before calling next rejects the RPC with that error; errors returned after
+// next cannot reach the caller anymore and are logged.
+//
+// Interceptors that wrap unary calls, such as those built with
+// connect.UnaryInterceptorFunc, are rejected, as WrapUnary only sees decoded
+// messages and could never run here: pass them, and any interceptor that
+// reads messages, to the service handlers with connect.WithInterceptors instead.
+func WithInterceptors(interceptors ...connect.Interceptor) ServerOption {
+	return func(cfg *Config) error {
+		for _, interceptor := range interceptors {
+			switch interceptor.(type) {
+			case nil:
+				return errors.New("interceptor must not be nil")
+			case connect.UnaryInterceptorFunc:
+				return errors.New("unary interceptors cannot run on encoded messages; pass them to the service handlers with connect.WithInterceptors")
+			}
+			if wrapsUnary(interceptor) {
+				return fmt.Errorf("interceptor %T wraps unary calls, which cannot run on encoded messages; pass it to the service handlers with connect.WithInterceptors", interceptor)
+			}
+		}
+		cfg.interceptors = append(cfg.interceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithHTTPMiddleware wraps the handler of every RPC with plain HTTP middleware.
+// Middleware runs once per RPC on every entry path, after the access policy and
+// before the interceptors; the first middleware given is the outermost.
+func WithHTTPMiddleware(middleware ...func(http.Handler) http.Handler) ServerOption {
+	return func(cfg *Config) error {
+		for _, m := range middleware {
+			if m == nil {
+				return errors.New("HTTP middleware must not be nil")
+			}
+		}
+		cfg.httpMiddleware = append(cfg.httpMiddleware, middleware...)
+		return nil
+	}
+}
+
+// passthroughUnary is handed to WrapUnary to find out whether an interceptor
+// wraps unary calls
+func passthroughUnary(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+	return nil, nil
+}
+
+// wrapsUnary reports whether WrapUnary of interceptor does more than return
+// the next function, in which case its unary behavior would be skipped
+func wrapsUnary(interceptor connect.Interceptor) bool {
+	wrapped := interceptor.WrapUnary(passthroughUnary)
+	return wrapped == nil || reflect.ValueOf(wrapped).Pointer() != reflect.ValueOf(connect.UnaryFunc(passthroughUnary)).Pointer()
+}
+
+// interceptorHandler runs the interceptor chain around next
+func interceptorHandler(interceptors []connect.Interceptor, logger glog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served := false
+		var call connect.StreamingHandlerFunc = func(ctx context.Context, _ connect.StreamingHandlerConn) error {
+			served = true
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return nil
+		}
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			call = interceptors[i].WrapStreamingHandler(call)
+		}
+
+		err := call(r.Context(), newTransportConn(w, r))
+		switch {
+		case err == nil:
+		case !served:
+			writeConnectError(w, r, err)
+		default:
+			// The handler already wrote the response
+			logger.Error("Interceptor error after the RPC was served", err, glog.LogFields{"procedure": r.URL.Path})
+		}
+	})
+}
+
+// transportConn is the connect.StreamingHandlerConn presented to server-level
+// interceptors. It exposes the transport but not the messages.
+type transportConn struct {
+	spec     connect.Spec
+	peer     connect.Peer
+	request  http.Header
+	response http.Header
+	trailer  http.Header
+}
+
+// newTransportConn describes an RPC request for interceptors
+func newTransportConn(w http.ResponseWriter, r *http.Request) *transportConn {
+	protocol, streamType := rpcProtocol(r)
+
+	addr := r.RemoteAddr
+	if info, ok := core.PeerInfoFromContext(r.Context()); ok && info.Addr != nil && info.Entry != core.EntryHTTP {
+		addr = info.Addr.String()
+	}
+
+	return &transportConn{
+		spec: connect.Spec{
+			StreamType: streamType,
+			Procedure:  r.URL.Path,
+		},
+		peer: connect.Peer{
+			Addr:     addr,
+			Protocol: protocol,
+			Query:    r.URL.Query(),
+		},
+		request:  r.Header,
+		response: w.Header(),
+		trailer:  make(http.Header),
+	}
+}
+
+// rpcProtocol infers the RPC protocol and stream type from the request.
+// Only Connect distinguishes unary calls on the wire; other calls are reported as bidi streams.
+func rpcProtocol(r *http.Request) (string, connect.StreamType) {
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/grpc-web"):
+		return connect.ProtocolGRPCWeb, connect.StreamTypeBidi
+	case strings.HasPrefix(contentType, "application/grpc"):
+		return connect.ProtocolGRPC, connect.StreamTypeBidi
+	case strings.HasPrefix(contentType, "application/connect+"):
+		return connect.ProtocolConnect, connect.StreamTypeBidi
+	default:
+		return connect.ProtocolConnect, connect.StreamTypeUnary
+	}
+}
+
+func (c *transportConn) Spec() connect.Spec          { return c.spec }
+func (c *transportConn) Peer() connect.Peer          { return c.peer }
+func (c *transportConn) RequestHeader() http.Header  { return c.request }
+func (c *transportConn) ResponseHeader() http.Header { return c.response }
+
+// ResponseTrailer returns a scratch header; trailers cannot be set at this layer
+func (c *transportConn) ResponseTrailer() http.Header { return c.trailer }
+
+func (c *transportConn) Receive(any) error {
+	return connect.NewError(connect.CodeUnimplemented, errPayloadUnavailable)
+}
+
+func (c *transportConn) Send(any) error {
+	return connect.NewError(connect.CodeUnimplemented, errPayloadUnavailable)
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/omgolab/drpc/pkg/drpc"
+)
+
+// recordingInterceptor records the calls it sees and rejects callers sending "Reject: yes"
+type recordingInterceptor struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (i *recordingInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc { return next }
+
+func (i *recordingInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *recordingInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		info, _ := drpc.PeerFromContext(ctx)
+		i.mu.Lock()
+		i.calls = append(i.calls, string(info.Entry)+" "+conn.Spec().Procedure+" "+conn.Peer().Protocol)
+		i.mu.Unlock()
+
+		if conn.RequestHeader().Get("Reject") == "yes" {
+			return connect.NewError(connect.CodeUnauthenticated, errors.New("rejected by interceptor"))
+		}
+		conn.ResponseHeader().Set("Intercepted", "true")
+		return next(ctx, conn)
+	}
+}
+
+func (i *recordingInterceptor) recorded() []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]string(nil), i.calls...)
+}
+
+func TestInterceptorsRunOncePerRPC(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	targetInterceptor := &recordingInterceptor{}
+	var middlewareCalls atomic.Int32
+	target := newPeerEchoServer(t, ctx,
+		WithInterceptors(targetInterceptor),
+		WithHTTPMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				middlewareCalls.Add(1)
+				next.ServeHTTP(w, r)
+			})
+		}),
+	)
+	gatewayInterceptor := &recordingInterceptor{}
+	gatewayServer := newPeerEchoServer(t, ctx, WithInterceptors(gatewayInterceptor))
+
+	addrs := []string{
+		target.HTTPAddr(),
+		target.P2PAddrs()[0],
+		gatewayServer.HTTPAddr() + "/@" + target.P2PAddrs()[0] + "/@",
+	}
+	for _, addr := range addrs {
+		if _, err := callSayHello(ctx, addr); err != nil {
+			t.Fatalf("SayHello via %s failed: %v", addr, err)
+		}
+	}
+	callWebStream(t, ctx, target, "/greeter.v1.GreeterService/StreamingEcho", `{"message":"hi"}`)
+
+	want := []string{
+		"http " + sayHelloProcedure + " connect",
+		"libp2p " + sayHelloProcedure + " connect",
+		"gateway " + sayHelloProcedure + " connect",
+		"webstream /greeter.v1.GreeterService/StreamingEcho connect",
+	}
+	got := targetInterceptor.recorded()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected interceptor calls:\n got: %q\nwant: %q", got, want)
+	}
+	if n := middlewareCalls.Load(); n != int32(len(want)) {
+		t.Errorf("Expected middleware to run %d times, ran %d", len(want), n)
+	}
+	if calls := gatewayInterceptor.recorded(); len(calls) != 0 {
+		t.Errorf("Gateway node should not intercept forwarded calls, got %q", calls)
+	}
+}
+
+func TestInterceptorRejectsRPC(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx, WithInterceptors(&recordingInterceptor{}))
+
+	c, err := newGreeterClient(ctx, server.HTTPAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := newSayHelloRequest()
+	resp, err := c.SayHello(ctx, req)
+	if err != nil {
+		t.Fatalf("SayHello failed: %v", err)
+	}
+	if resp.Header().Get("Intercepted") != "true" {
+		t.Error("Expected response header set by interceptor")
+	}
+
+	req = newSayHelloRequest()
+	req.Header().Set("Reject", "yes")
+	if _, err := c.SayHello(ctx, req); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("Expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestWithHTTPMiddlewareRejectsNil(t *testing.T) {
+	cfg := GetDefaultConfig()
+	if err := WithHTTPMiddleware(nil)(&cfg); err == nil {
+		t.Error("Expected nil middleware to be rejected")
+	}
+}
+
+// unaryAuthInterceptor checks credentials in WrapUnary only, like most auth interceptors
+type unaryAuthInterceptor struct{}
+
+func (unaryAuthInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Header().Get("Authorization") == "" {
+			return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("missing credentials"))
+		}
+		return next(ctx, req)
+	}
+}
+
+func (unaryAuthInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (unaryAuthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+func TestWithInterceptorsRejectsUnary(t *testing.T) {
+	cfg := GetDefaultConfig()
+	unary := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc { return next })
+	if err := WithInterceptors(unary)(&cfg); err == nil {
+		t.Error("Expected a unary interceptor to be rejected")
+	}
+	if err := WithInterceptors(&unaryAuthInterceptor{})(&cfg); err == nil {
+		t.Error("Expected an interceptor acting in WrapUnary to be rejected")
+	}
+	if err := WithInterceptors(nil)(&cfg); err == nil {
+		t.Error("Expected a nil interceptor to be rejected")
+	}
+	if len(cfg.interceptors) != 0 {
+		t.Errorf("Rejected interceptors were kept: %v", cfg.interceptors)
+	}
+	if err := WithInterceptors(&recordingInterceptor{})(&cfg); err != nil {
+		t.Errorf("Interceptor passing unary calls through was rejected: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/events"
+	"github.com/omgolab/drpc/pkg/core/pool"
+	"github.com/omgolab/drpc/pkg/gateway"
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	defaultMetricsPath = "/metrics"
+	metricsNamespace   = "drpc"
+	// unknownProcedure labels calls to paths no service is routed on, which keeps label cardinality bounded
+	unknownProcedure = "unknown"
+)
+
+// WithMetrics serves Prometheus/OpenMetrics metrics on the HTTP listener at
+// path ("/metrics" if empty). It reports RPC counts and latencies per
+// procedure and entry path, connection pool and buffer pool usage, peer
+// connection attempts, gateway address cache hits and dropped lifecycle
+// events. The endpoint bypasses
+// the RPC pipeline, so access policies and middleware do not apply to it.
+func WithMetrics(path string) ServerOption {
+	return func(cfg *Config) error {
+		if path == "" {
+			path = defaultMetricsPath
+		}
+		if !strings.HasPrefix(path, "/") {
+			return errors.New("metrics path must start with /")
+		}
+		cfg.metricsPath = path
+		return nil
+	}
+}
+
+// serverMetrics holds the metrics registry of one server
+type serverMetrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+// newServerMetrics creates the metrics of a server whose libp2p host and
+// accept queue statistics are returned by p2pHost and acceptStats
+func newServerMetrics(p2pHost func() host.Host, acceptStats func() (core.AcceptStats, bool), emitter func() *events.Emitter, logger glog.Logger) *serverMetrics {
+	m := &serverMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_requests_total",
+			Help:      "RPCs received, by procedure and entry path.",
+		}, []string{"procedure", "entry"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Time spent serving RPCs, by procedure and entry path.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"procedure", "entry"}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.latency,
+		&statsCollector{p2pHost: p2pHost, acceptStats: acceptStats, emitter: emitter, logger: logger},
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// wrap records every RPC served by next. known reports whether a service is
+// routed on a procedure; other paths are recorded as unknown.
+func (m *serverMetrics) wrap(next http.Handler, known func(procedure string) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+
+		procedure := r.URL.Path
+		if !known(procedure) {
+			procedure = unknownProcedure
+		}
+		entry := core.EntryHTTP
+		if info, ok := core.PeerInfoFromContext(r.Context()); ok {
+			entry = info.Entry
+		}
+		m.requests.WithLabelValues(procedure, string(entry)).Inc()
+		m.latency.WithLabelValues(procedure, string(entry)).Observe(time.Since(start).Seconds())
+	})
+}
+
+// registerRateLimiter reports the statistics of rl
+func (m *serverMetrics) registerRateLimiter(rl *rateLimiter) {
+	m.registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rate_limit_allowed_total",
+			Help:      "RPCs let through by the rate limiter.",
+		}, func() float64 { return float64(rl.stats().Allowed) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rate_limit_throttled_total",
+			Help:      "RPCs rejected with ResourceExhausted by the rate limiter.",
+		}, func() float64 { return float64(rl.stats().Throttled) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "rate_limit_callers",
+			Help:      "Callers the rate limiter keeps token buckets for.",
+		}, func() float64 { return float64(rl.stats().Callers) }),
+	)
+}
+
+// handler serves the registry, negotiating OpenMetrics when the scraper asks for it
+func (m *serverMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// knownProcedure reports whether a built-in, initial or registered service is routed on procedure
+func (s *DRPCServer) knownProcedure(procedure string) bool {
+	i := strings.LastIndex(procedure, "/")
+	if i <= 0 {
+		return false
+	}
+	servicePath := procedure[:i+1]
+	return muxRoutes(s.builtinRoutes, servicePath) || s.router.handles(servicePath)
+}
+
+var (
+	poolIdleDesc = prometheus.NewDesc(metricsNamespace+"_pool_idle_streams",
+		"Pooled libp2p streams waiting for reuse, by shard.", []string{"shard"}, nil)
+	poolActiveDesc = prometheus.NewDesc(metricsNamespace+"_pool_active_streams",
+		"libp2p streams handed out by the pool and not yet closed, by shard.", []string{"shard"}, nil)
+	poolReusedDesc = prometheus.NewDesc(metricsNamespace+"_pool_streams_reused_total",
+		"Streams served from the connection pool.", nil, nil)
+	poolCreatedDesc = prometheus.NewDesc(metricsNamespace+"_pool_streams_created_total",
+		"Streams opened because the connection pool had none to reuse.", nil, nil)
+	poolReuseRatioDesc = prometheus.NewDesc(metricsNamespace+"_pool_reuse_ratio",
+		"Share of streams served from the connection pool.", nil, nil)
+
+	bufferGetsDesc = prometheus.NewDesc(metricsNamespace+"_buffer_pool_gets_total",
+		"Buffers requested, by pool.", []string{"pool"}, nil)
+	bufferHitsDesc = prometheus.NewDesc(metricsNamespace+"_buffer_pool_hits_total",
+		"Buffers reused instead of allocated, by pool.", []string{"pool"}, nil)
+	bufferHitRatioDesc = prometheus.NewDesc(metricsNamespace+"_buffer_pool_hit_ratio",
+		"Share of buffers reused instead of allocated, by pool.", []string{"pool"}, nil)
+
+	connectDurationDesc = prometheus.NewDesc(metricsNamespace+"_peer_connect_duration_seconds",
+		"Time spent connecting to the first available peer; the count is the number of attempts.", nil, nil)
+	connectFailuresDesc = prometheus.NewDesc(metricsNamespace+"_peer_connect_failures_total",
+		"Attempts that could not connect to any peer.", nil, nil)
+
+	acceptLatencyDesc = prometheus.NewDesc(metricsNamespace+"_accept_queue_wait_seconds",
+		"Time libp2p streams waited for the server; the count is the number of accepted streams.", nil, nil)
+	acceptMaxLatencyDesc = prometheus.NewDesc(metricsNamespace+"_accept_queue_max_wait_seconds",
+		"Longest time a libp2p stream waited for the server.", nil, nil)
+	acceptRefusedDesc = prometheus.NewDesc(metricsNamespace+"_accept_queue_refused_total",
+		"libp2p streams refused by the accept queue, by reason.", []string{"reason"}, nil)
+	acceptPendingDesc = prometheus.NewDesc(metricsNamespace+"_accept_queue_pending_streams",
+		"libp2p streams waiting for the server.", nil, nil)
+
+	addressCacheHitsDesc = prometheus.NewDesc(metricsNamespace+"_gateway_address_cache_hits_total",
+		"Gateway target lookups served from the address cache.", nil, nil)
+	addressCacheMissesDesc = prometheus.NewDesc(metricsNamespace+"_gateway_address_cache_misses_total",
+		"Gateway target lookups that had to parse the address.", nil, nil)
+	addressCacheEntriesDesc = prometheus.NewDesc(metricsNamespace+"_gateway_address_cache_entries",
+		"Entries in the gateway address cache.", nil, nil)
+
+	eventsDroppedDesc = prometheus.NewDesc(metricsNamespace+"_events_dropped_total",
+		"Lifecycle events dropped because their subscribers lagged behind.", nil, nil)
+)
+
+// bufferPools are the shared buffer pools reported by statsCollector
+var bufferPools = map[string]*pool.BufferPool{
+	"medium":       pool.MediumBufferPool,
+	"large":        pool.LargeBufferPool,
+	"path":         pool.PathBufferPool,
+	"content_type": pool.ContentTypeBufferPool,
+}
+
+// statsCollector reports the statistics kept by the pool and gateway packages at scrape time
+type statsCollector struct {
+	p2pHost     func() host.Host
+	acceptStats func() (core.AcceptStats, bool)
+	emitter     func() *events.Emitter
+	logger      glog.Logger
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		poolIdleDesc, poolActiveDesc, poolReusedDesc, poolCreatedDesc, poolReuseRatioDesc,
+		bufferGetsDesc, bufferHitsDesc, bufferHitRatioDesc,
+		connectDurationDesc, connectFailuresDesc,
+		acceptLatencyDesc, acceptMaxLatencyDesc, acceptRefusedDesc, acceptPendingDesc,
+		addressCacheHitsDesc, addressCacheMissesDesc, addressCacheEntriesDesc,
+		eventsDroppedDesc,
+	} {
+		ch <- d
+	}
+}
+
+// lookupPool returns the connection pool of the libp2p host, if it has one
+func (c *statsCollector) lookupPool() *pool.ConnectionPool {
+	if h := c.p2pHost(); h != nil {
+		return pool.LookupPool(h)
+	}
+	return nil
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	// Scrapes only read the pool of the host, they never create it
+	if p := c.lookupPool(); p != nil {
+		stats := p.Stats()
+		for i, shard := range stats.Shards {
+			label := strconv.Itoa(i)
+			ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(shard.Idle), label)
+			ch <- prometheus.MustNewConstMetric(poolActiveDesc, prometheus.GaugeValue, float64(shard.Active), label)
+		}
+		ch <- prometheus.MustNewConstMetric(poolReusedDesc, prometheus.CounterValue, float64(stats.Reused))
+		ch <- prometheus.MustNewConstMetric(poolCreatedDesc, prometheus.CounterValue, float64(stats.Created))
+		ch <- prometheus.MustNewConstMetric(poolReuseRatioDesc, prometheus.GaugeValue, stats.ReuseRatio)
+	}
+
+	for name, bp := range bufferPools {
+		stats := bp.Stats()
+		ch <- prometheus.MustNewConstMetric(bufferGetsDesc, prometheus.CounterValue, float64(stats.Gets), name)
+		ch <- prometheus.MustNewConstMetric(bufferHitsDesc, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(bufferHitRatioDesc, prometheus.GaugeValue, stats.HitRatio, name)
+	}
+
+	connect := pool.GetConnectStats()
+	ch <- prometheus.MustNewConstSummary(connectDurationDesc, uint64(connect.Attempts), connect.Duration.Seconds(), nil)
+	ch <- prometheus.MustNewConstMetric(connectFailuresDesc, prometheus.CounterValue, float64(connect.Failures))
+
+	if accept, ok := c.acceptStats(); ok {
+		ch <- prometheus.MustNewConstSummary(acceptLatencyDesc, uint64(accept.Accepted), accept.Latency.Seconds(), nil)
+		ch <- prometheus.MustNewConstMetric(acceptMaxLatencyDesc, prometheus.GaugeValue, accept.MaxLatency.Seconds())
+		ch <- prometheus.MustNewConstMetric(acceptRefusedDesc, prometheus.CounterValue, float64(accept.Overflowed), "queue_full")
+		ch <- prometheus.MustNewConstMetric(acceptRefusedDesc, prometheus.CounterValue, float64(accept.PeerCapped), "peer_cap")
+		ch <- prometheus.MustNewConstMetric(acceptPendingDesc, prometheus.GaugeValue, float64(accept.Pending))
+	}
+
+	cache := gateway.GetAddressCacheStats()
+	ch <- prometheus.MustNewConstMetric(addressCacheHitsDesc, prometheus.CounterValue, float64(cache.Hits))
+	ch <- prometheus.MustNewConstMetric(addressCacheMissesDesc, prometheus.CounterValue, float64(cache.Misses))
+	ch <- prometheus.MustNewConstMetric(addressCacheEntriesDesc, prometheus.GaugeValue, float64(cache.Entries))
+
+	ch <- prometheus.MustNewConstMetric(eventsDroppedDesc, prometheus.CounterValue, float64(c.emitter().Dropped()))
+}
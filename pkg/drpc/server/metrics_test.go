@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+)
+
+// scrapeMetrics returns the text exposition of the metrics endpoint at path
+func scrapeMetrics(t *testing.T, server *DRPCServer, path string) string {
+	t.Helper()
+
+	resp, err := http.Get(server.HTTPAddr() + path)
+	if err != nil {
+		t.Fatalf("Failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Metrics endpoint returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	target := newPeerEchoServer(t, ctx, WithMetrics("/custom-metrics"))
+	gatewayServer := newPeerEchoServer(t, ctx, WithMetrics(""))
+
+	sayHello(t, ctx, target.HTTPAddr())
+	sayHello(t, ctx, target.P2PAddrs()[0])
+	sayHello(t, ctx, gatewayServer.HTTPAddr()+"/@"+target.P2PAddrs()[0]+"/@")
+	if _, err := callSayHello(ctx, target.HTTPAddr()+"/no.such.Service"); connect.CodeOf(err) != connect.CodeUnimplemented {
+		t.Fatalf("Expected Unimplemented for an unknown procedure, got %v", err)
+	}
+
+	metrics := scrapeMetrics(t, target, "/custom-metrics")
+	for _, want := range []string{
+		`drpc_rpc_requests_total{entry="http",procedure="/greeter.v1.GreeterService/SayHello"} 1`,
+		`drpc_rpc_requests_total{entry="libp2p",procedure="/greeter.v1.GreeterService/SayHello"} 1`,
+		`drpc_rpc_requests_total{entry="gateway",procedure="/greeter.v1.GreeterService/SayHello"} 1`,
+		`drpc_rpc_requests_total{entry="http",procedure="unknown"} 1`,
+		`drpc_rpc_duration_seconds_count{entry="libp2p",procedure="/greeter.v1.GreeterService/SayHello"} 1`,
+		`drpc_pool_active_streams{shard="0"}`,
+		`drpc_buffer_pool_hit_ratio{pool="medium"}`,
+		`drpc_peer_connect_duration_seconds_count`,
+		`drpc_gateway_address_cache_misses_total`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("Metrics do not contain %q", want)
+		}
+	}
+
+	// The gateway node forwarded through a pooled stream to the target
+	metrics = scrapeMetrics(t, gatewayServer, "/metrics")
+	if !strings.Contains(metrics, "drpc_pool_streams_created_total 1") {
+		t.Errorf("Expected the gateway pool to have opened one stream:\n%s", metrics)
+	}
+}
+
+func TestWithMetricsValidation(t *testing.T) {
+	cfg := GetDefaultConfig()
+	if err := WithMetrics("metrics")(&cfg); err == nil {
+		t.Error("Expected a relative metrics path to be rejected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if _, err := New(ctx, http.NewServeMux(), WithMetrics(""), WithDisableHTTP()); err == nil {
+		t.Error("Expected metrics without an HTTP listener to be rejected")
+	}
+}
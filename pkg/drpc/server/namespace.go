@@ -0,0 +1,121 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/gateway"
+)
+
+// NamespaceOption configures a namespace added with WithNamespace
+type NamespaceOption func(*namespaceConfig) error
+
+// namespaceConfig holds the mux and the per-namespace settings of a namespace
+type namespaceConfig struct {
+	name         string
+	mux          *http.ServeMux
+	corsConfig   *gateway.CORSConfig
+	accessPolicy *accessPolicy
+}
+
+// WithNamespace serves mux as an isolated namespace of the server's libp2p
+// host, so several teams can share one identity and connection set. Its
+// services are reached over libp2p on the dRPC and web stream protocols
+// suffixed with "/<name>", e.g. /drpc/1.0.0/tenant-a, and on the HTTP
+// listener under the "/<name>/" path prefix, which shadows default services
+// with the same path. Clients select it with client.WithNamespace.
+//
+// A namespace only routes its own mux: the default services, the built-in
+// ones and the other namespaces cannot be reached through its protocols or
+// prefix, and its services are not published for discovery. CORS and the
+// access policy are per namespace, set with opts; the other options, such as
+// rate and size limits, interceptors and middleware, apply to every namespace.
+func WithNamespace(name string, mux *http.ServeMux, opts ...NamespaceOption) ServerOption {
+	return func(cfg *Config) error {
+		if err := core.ValidateNamespace(name); err != nil {
+			return err
+		}
+		if mux == nil {
+			return fmt.Errorf("namespace %s: mux cannot be nil", name)
+		}
+		for _, ns := range cfg.namespaces {
+			if ns.name == name {
+				return fmt.Errorf("namespace %s is already set", name)
+			}
+		}
+		ns := &namespaceConfig{name: name, mux: mux}
+		for _, opt := range opts {
+			if err := opt(ns); err != nil {
+				return fmt.Errorf("namespace %s: %w", name, err)
+			}
+		}
+		cfg.namespaces = append(cfg.namespaces, ns)
+		return nil
+	}
+}
+
+// WithNamespaceCORSHeaders enables CORS headers on the HTTP prefix of the
+// namespace, with the defaults of WithCORSHeaders for empty lists
+func WithNamespaceCORSHeaders(origins, methods, headers, exposedHeaders []string) NamespaceOption {
+	return func(ns *namespaceConfig) error {
+		ns.corsConfig = newCORSConfig(origins, methods, headers, exposedHeaders)
+		return nil
+	}
+}
+
+// WithNamespaceAccessPolicy restricts the procedures of the namespace like
+// WithAccessPolicy does for the default services
+func WithNamespaceAccessPolicy(policy AccessPolicy) NamespaceOption {
+	return func(ns *namespaceConfig) error {
+		compiled, err := compileAccessPolicy(policy)
+		if err != nil {
+			return fmt.Errorf("invalid access policy: %w", err)
+		}
+		ns.accessPolicy = compiled
+		return nil
+	}
+}
+
+// namespaceHandlers builds the per-RPC pipeline of every namespace, with the
+// namespace's access policy in place of the server's
+func (s *DRPCServer) namespaceHandlers(cfg *Config) []*p2pNamespace {
+	namespaces := make([]*p2pNamespace, 0, len(cfg.namespaces))
+	for _, ns := range cfg.namespaces {
+		nsCfg := *cfg
+		nsCfg.accessPolicy = ns.accessPolicy
+		namespaces = append(namespaces, &p2pNamespace{
+			name:    ns.name,
+			handler: s.buildRPCHandler(&nsCfg, ns.mux, muxProcedures(ns.mux)),
+		})
+	}
+	return namespaces
+}
+
+// namespaceRoutes routes the HTTP prefix of every namespace to its handler
+// and every other path to next
+func namespaceRoutes(cfg *Config, handlers []*p2pNamespace, next http.Handler) http.Handler {
+	if len(handlers) == 0 {
+		return next
+	}
+	routes := http.NewServeMux()
+	for i, ns := range cfg.namespaces {
+		var handler http.Handler = handlers[i].handler
+		if ns.corsConfig != nil {
+			handler = gateway.CORSHandler(handler, ns.corsConfig)
+		}
+		prefix := "/" + ns.name
+		routes.Handle(prefix+"/", http.StripPrefix(prefix, handler))
+	}
+	routes.Handle("/", next)
+	return routes
+}
+
+// muxProcedures reports whether mux routes the service of a procedure
+func muxProcedures(mux *http.ServeMux) func(procedure string) bool {
+	return func(procedure string) bool {
+		i := strings.LastIndex(procedure, "/")
+		return i > 0 && muxRoutes(mux, procedure[:i+1])
+	}
+}
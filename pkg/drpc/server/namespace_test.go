@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	gv1 "github.com/omgolab/drpc/demo/gen/go/greeter/v1"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	healthv1 "github.com/omgolab/drpc/pkg/drpc/proto/drpcext/grpc/health/v1"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// namedGreeter replies with the name of the namespace serving it
+type namedGreeter struct {
+	gv1connect.UnimplementedGreeterServiceHandler
+	name string
+}
+
+func (g namedGreeter) SayHello(context.Context, *connect.Request[gv1.SayHelloRequest]) (*connect.Response[gv1.SayHelloResponse], error) {
+	return connect.NewResponse(&gv1.SayHelloResponse{Message: g.name}), nil
+}
+
+func newNamedGreeterMux(name string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle(gv1connect.NewGreeterServiceHandler(namedGreeter{name: name}))
+	return mux
+}
+
+// callNamespace calls SayHello on namespace of addr, the default one if empty
+func callNamespace(ctx context.Context, addr, namespace string) (string, error) {
+	logger, _ := glog.New()
+	opts := []client.Option{client.WithLogger(logger), client.WithLibp2pOptions(libp2p.NoListenAddrs)}
+	if namespace != "" {
+		opts = append(opts, client.WithNamespace(namespace))
+	}
+	c, err := client.New(ctx, addr, gv1connect.NewGreeterServiceClient, opts...)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.SayHello(ctx, newSayHelloRequest())
+	if err != nil {
+		return "", err
+	}
+	return resp.Msg.Message, nil
+}
+
+func TestNamespaces(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx,
+		WithHealthService(),
+		WithNamespace("tenant-a", newNamedGreeterMux("tenant-a"),
+			WithNamespaceCORSHeaders([]string{"https://a.example"}, nil, nil, nil)),
+		WithNamespace("tenant-b", newNamedGreeterMux("tenant-b"),
+			WithNamespaceAccessPolicy(AccessPolicy{Rules: []AccessRule{{Procedure: "*", HTTPOnly: true}}, DefaultDeny: true})),
+	)
+	p2pAddr, httpAddr := server.P2PAddrs()[0], server.HTTPAddr()
+
+	for _, addr := range []string{p2pAddr, httpAddr} {
+		if got, err := callNamespace(ctx, addr, "tenant-a"); err != nil || got != "tenant-a" {
+			t.Errorf("SayHello on tenant-a via %s = %q, %v; want tenant-a", addr, got, err)
+		}
+		if got, err := callNamespace(ctx, addr, ""); err != nil || got == "tenant-a" || got == "tenant-b" {
+			t.Errorf("SayHello on the default services via %s = %q, %v", addr, got, err)
+		}
+	}
+
+	t.Run("access", func(t *testing.T) {
+		if _, err := callNamespace(ctx, p2pAddr, "tenant-b"); connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Errorf("tenant-b over libp2p returned %v, want PermissionDenied", err)
+		}
+		if got, err := callNamespace(ctx, httpAddr, "tenant-b"); err != nil || got != "tenant-b" {
+			t.Errorf("tenant-b over HTTP = %q, %v; want tenant-b", got, err)
+		}
+	})
+
+	t.Run("isolation", func(t *testing.T) {
+		logger, _ := glog.New()
+		c, err := client.New(ctx, p2pAddr, NewHealthClient,
+			client.WithLogger(logger),
+			client.WithLibp2pOptions(libp2p.NoListenAddrs),
+			client.WithNamespace("tenant-a"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Check(ctx, connect.NewRequest(&healthv1.HealthCheckRequest{})); connect.CodeOf(err) != connect.CodeUnimplemented {
+			t.Errorf("Built-in health service in a namespace returned %v, want Unimplemented", err)
+		}
+		if _, err := callNamespace(ctx, p2pAddr, "tenant-c"); err == nil {
+			t.Error("Expected a call to an unknown namespace to fail")
+		}
+	})
+
+	t.Run("cors", func(t *testing.T) {
+		preflight := func(path string) string {
+			req, _ := http.NewRequestWithContext(ctx, http.MethodOptions, httpAddr+path, nil)
+			req.Header.Set("Origin", "https://a.example")
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Preflight for %s failed: %v", path, err)
+			}
+			resp.Body.Close()
+			return resp.Header.Get("Access-Control-Allow-Origin")
+		}
+		procedure := gv1connect.GreeterServiceSayHelloProcedure
+		if got := preflight("/tenant-a" + procedure); got != "https://a.example" {
+			t.Errorf("Allowed origin on tenant-a = %q, want https://a.example", got)
+		}
+		if got := preflight("/tenant-b" + procedure); got != "" {
+			t.Errorf("Allowed origin on tenant-b without CORS = %q", got)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		mux := http.NewServeMux()
+		for _, opt := range []ServerOption{
+			WithNamespace("Tenant", mux),
+			WithNamespace("", mux),
+			WithNamespace("tenant", nil),
+			WithNamespace("tenant", mux, WithNamespaceAccessPolicy(AccessPolicy{Rules: []AccessRule{{}}})),
+		} {
+			if err := opt(&Config{}); err == nil {
+				t.Error("Expected an invalid namespace to be rejected")
+			}
+		}
+		cfg := &Config{}
+		if err := WithNamespace("tenant", mux)(cfg); err != nil {
+			t.Fatal(err)
+		}
+		if err := WithNamespace("tenant", mux)(cfg); err == nil {
+			t.Error("Expected a duplicate namespace to be rejected")
+		}
+	})
+}
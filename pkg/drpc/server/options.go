@@ -1,14 +1,26 @@
 package server
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
+
+	"connectrpc.com/connect"
 
 	"github.com/libp2p/go-libp2p"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/identity"
 	"github.com/omgolab/drpc/pkg/detach"
 	"github.com/omgolab/drpc/pkg/gateway"
 	glog "github.com/omgolab/go-commons/pkg/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Config holds all server configuration options
@@ -22,6 +34,30 @@ type Config struct {
 	isDetachServer         bool
 	detachOptions          []detach.DetachOption
 	corsConfig             *gateway.CORSConfig
+	tls                    *tlsFiles
+	h2c                    *bool
+	accessPolicy           *accessPolicy
+	rateLimiter            *rateLimiter
+	resourceLimits         *core.ResourceLimits
+	interceptors           []connect.Interceptor
+	httpMiddleware         []func(http.Handler) http.Handler
+	protocolVersions       []string
+	healthService          bool
+	reflection             bool
+	reflectedServices      []string
+	metricsPath            string // empty unless WithMetrics is set
+	spanExporter           sdktrace.SpanExporter
+	tracerProvider         trace.TracerProvider
+	configFile             *config.File // host level sections of WithConfigFile
+	identity               crypto.PrivKey
+	identitySource         config.Identity // where identity came from, for EffectiveConfig
+	addressBookPath        string
+	privateNetwork         pnet.PSK
+	sizeLimits             *SizeLimits
+	acceptQueue            *core.AcceptQueue
+	eventHandler           func(evt any)
+	admin                  *adminAccess // nil unless WithAdminService is set
+	namespaces             []*namespaceConfig
 }
 
 // GetDefaultConfig returns a default server configuration
@@ -56,6 +92,65 @@ func WithDHTOptions(opts ...dht.Option) ServerOption {
 	}
 }
 
+// WithIdentity sets the private key, and so the peer ID, of the server's
+// libp2p host. Without it a random key is generated on every start.
+func WithIdentity(key crypto.PrivKey) ServerOption {
+	return func(cfg *Config) error {
+		if key == nil {
+			return errors.New("identity key cannot be nil")
+		}
+		data, err := crypto.MarshalPrivateKey(key)
+		if err != nil {
+			return fmt.Errorf("invalid identity key: %w", err)
+		}
+		cfg.identity = key
+		cfg.identitySource = config.Identity{PrivateKey: base64.StdEncoding.EncodeToString(data)}
+		return nil
+	}
+}
+
+// WithIdentityFile keeps the server's peer ID across restarts by loading its
+// key from path, or generating and storing one there on first start.
+// See identity.LoadOrGenerate for the key type and passphrase options.
+func WithIdentityFile(path string, opts ...identity.Option) ServerOption {
+	return func(cfg *Config) error {
+		key, err := identity.LoadOrGenerate(path, opts...)
+		if err != nil {
+			return err
+		}
+		cfg.identity = key
+		cfg.identitySource = config.Identity{KeyFile: path}
+		return nil
+	}
+}
+
+// WithPrivateNetwork restricts the server's libp2p host to peers holding the
+// same 32 byte pre-shared key, e.g. read with host.LoadSwarmKey. The public
+// DHT bootstrap peers are not used and discovery is isolated to the network.
+// Only TCP and WebSocket listen addresses are supported.
+func WithPrivateNetwork(psk pnet.PSK) ServerOption {
+	return func(cfg *Config) error {
+		if len(psk) != 32 {
+			return fmt.Errorf("private network key must be 32 bytes, got %d", len(psk))
+		}
+		cfg.privateNetwork = psk
+		return nil
+	}
+}
+
+// WithAddressBook persists the peers the server connects to in a file at
+// path and redials the most recently useful ones after a restart, before
+// discovery finds them again. See host.GetAddressBook to export or import it.
+func WithAddressBook(path string) ServerOption {
+	return func(cfg *Config) error {
+		if path == "" {
+			return errors.New("address book path cannot be empty")
+		}
+		cfg.addressBookPath = path
+		return nil
+	}
+}
+
 // WithHTTPPort sets the HTTP port for the server
 func WithHTTPPort(port int) ServerOption {
 	return func(cfg *Config) error {
@@ -114,44 +209,134 @@ func WithDisableHTTP() ServerOption {
 	}
 }
 
-// WithTLS enables TLS/SSL support
+// WithTLS enables TLS/SSL support on the HTTP listener.
+// HTTP/2 is negotiated via ALPN and the key pair is reloaded from disk when the files change.
 func WithTLS(certFile, keyFile string) ServerOption {
 	return func(cfg *Config) error {
 		if certFile == "" || keyFile == "" {
 			return fmt.Errorf("TLS requires both cert file and key file")
 		}
-		// Future: Add TLS configuration
+		cfg.tls = &tlsFiles{certFile: certFile, keyFile: keyFile}
 		return nil
 	}
 }
 
-// WithCORSHeaders enables CORS headers with configurable options
-func WithCORSHeaders(origins, methods, headers, exposedHeaders []string) ServerOption {
+// WithH2C controls cleartext HTTP/2 (h2c) on the HTTP listener.
+// h2c is enabled by default without TLS and disabled by default with TLS.
+func WithH2C(enable bool) ServerOption {
 	return func(cfg *Config) error {
-		// Default values if not provided
-		if len(origins) == 0 {
-			origins = []string{"*"}
+		cfg.h2c = &enable
+		return nil
+	}
+}
+
+// useH2C reports whether the HTTP listener should accept h2c connections
+func (cfg *Config) useH2C() bool {
+	if cfg.h2c != nil {
+		return *cfg.h2c
+	}
+	return cfg.tls == nil
+}
+
+// WithProtocolVersions registers the dRPC and web stream protocols for each of
+// the given wire versions side by side, so fleets can roll out wire changes
+// gradually. Clients negotiate the highest version both sides support.
+func WithProtocolVersions(versions ...string) ServerOption {
+	return func(cfg *Config) error {
+		if len(versions) == 0 {
+			return errors.New("at least one protocol version is required")
 		}
-		if len(methods) == 0 {
-			methods = []string{"GET", "POST", "OPTIONS"}
+		for _, v := range versions {
+			if _, err := core.ParseProtocolVersion(v); err != nil {
+				return err
+			}
 		}
-		if len(headers) == 0 {
-			headers = []string{"Content-Type", "Accept", "Authorization", "Connect-Accept-Encoding", "Connect-Content-Encoding", "Connect-Protocol-Version", "Connect-Timeout-Ms"}
+		cfg.protocolVersions = versions
+		return nil
+	}
+}
+
+// rpcProtocolIDs returns the dRPC and web stream protocol IDs to serve, highest version first
+func (cfg *Config) rpcProtocolIDs() (rpc, webStream []protocol.ID) {
+	versions := cfg.protocolVersions
+	if len(versions) == 0 {
+		versions = config.DRPC_PROTOCOL_VERSIONS
+	}
+	return core.ProtocolIDs(config.DRPC_PROTOCOL_PREFIX, versions),
+		core.ProtocolIDs(config.DRPC_WEB_STREAM_PROTOCOL_PREFIX, versions)
+}
+
+// WithResourceLimits sets the libp2p resource manager limits of the dRPC and
+// web stream protocols. Inbound streams are attached to the drpc and
+// drpc-webstream services, and web streams reserve memory for their copy
+// buffers; streams over a limit are reset with a resource limit error code.
+// It cannot be combined with a libp2p.ResourceManager passed through
+// WithLibP2POptions.
+func WithResourceLimits(limits core.ResourceLimits) ServerOption {
+	return func(cfg *Config) error {
+		cfg.resourceLimits = &limits
+		return nil
+	}
+}
+
+// WithAcceptQueue sets the queue of libp2p streams waiting for the server
+// (core.DefaultAcceptQueueSize streams, no per-peer cap and reset on overflow
+// by default). Streams over the queue size or the per-peer cap are refused
+// right away; with core.OverflowBusy clients see a retryable Unavailable error.
+func WithAcceptQueue(queue core.AcceptQueue) ServerOption {
+	return func(cfg *Config) error {
+		if queue.Size < 0 || queue.MaxPendingPerPeer < 0 {
+			return errors.New("invalid accept queue: sizes must not be negative")
 		}
-		if len(exposedHeaders) == 0 {
-			exposedHeaders = []string{"Content-Type", "Connect-Content-Encoding"}
+		if queue.Overflow != core.OverflowReset && queue.Overflow != core.OverflowBusy {
+			return fmt.Errorf("invalid accept queue overflow policy %d", queue.Overflow)
 		}
+		cfg.acceptQueue = &queue
+		return nil
+	}
+}
 
-		cfg.corsConfig = &gateway.CORSConfig{
-			AllowedOrigins: origins,
-			AllowedMethods: methods,
-			AllowedHeaders: headers,
-			ExposedHeaders: exposedHeaders,
-		}
+// WithHealthService mounts the standard grpc.health.v1.Health service next to
+// the application handlers, reachable over libp2p, the gateway and HTTP.
+// Use DRPCServer.Health to set per-service statuses.
+func WithHealthService() ServerOption {
+	return func(cfg *Config) error {
+		cfg.healthService = true
+		return nil
+	}
+}
+
+// WithCORSHeaders enables CORS headers with configurable options
+func WithCORSHeaders(origins, methods, headers, exposedHeaders []string) ServerOption {
+	return func(cfg *Config) error {
+		cfg.corsConfig = newCORSConfig(origins, methods, headers, exposedHeaders)
 		return nil
 	}
 }
 
+// newCORSConfig fills the empty lists of a CORS config with the defaults
+func newCORSConfig(origins, methods, headers, exposedHeaders []string) *gateway.CORSConfig {
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+	if len(methods) == 0 {
+		methods = []string{"GET", "POST", "OPTIONS"}
+	}
+	if len(headers) == 0 {
+		headers = []string{"Content-Type", "Accept", "Authorization", "Connect-Accept-Encoding", "Connect-Content-Encoding", "Connect-Protocol-Version", "Connect-Timeout-Ms"}
+	}
+	if len(exposedHeaders) == 0 {
+		exposedHeaders = []string{"Content-Type", "Connect-Content-Encoding"}
+	}
+
+	return &gateway.CORSConfig{
+		AllowedOrigins: origins,
+		AllowedMethods: methods,
+		AllowedHeaders: headers,
+		ExposedHeaders: exposedHeaders,
+	}
+}
+
 // WithDefaultCORSHeaders enables CORS headers with sensible defaults for development
 func WithDefaultCORSHeaders() ServerOption {
 	return WithCORSHeaders(nil, nil, nil, nil)
@@ -4,31 +4,56 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
-	"github.com/omgolab/drpc/pkg/config"
+	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/events"
 	h "github.com/omgolab/drpc/pkg/core/host"
+	"github.com/omgolab/drpc/pkg/core/pool"
+	"github.com/omgolab/drpc/pkg/core/tracing"
 	glog "github.com/omgolab/go-commons/pkg/log"
 )
 
 // P2PServerManager handles P2P server functionality
 type P2PServerManager struct {
-	host       host.Host
-	server     *http.Server
-	logger     glog.Logger
-	ctx        context.Context
-	handlerMux *http.ServeMux
+	host     host.Host
+	server   *http.Server
+	listener core.Libp2pListener // bridges dRPC streams to server
+	events   *events.Emitter     // lifecycle events, on the host's event bus
+	logger   glog.Logger
+	ctx      context.Context
+	handler  http.Handler
+	bridges  *rpcTracker // in-flight web stream bridges
+	// protocols are the dRPC and web stream protocol IDs registered on the host
+	protocols []protocol.ID
+	// namespaces are served next to handler on their own protocol IDs
+	namespaces []*p2pNamespace
+
+	servicesMu sync.Mutex
+	services   []string // services published through marker protocols and the DHT
+	accepting  bool
+}
+
+// p2pNamespace is a namespace added with WithNamespace
+type p2pNamespace struct {
+	name    string
+	handler http.Handler
+	server  *http.Server // set by Setup
 }
 
 // NewP2PServerManager creates a new P2P server manager
-func NewP2PServerManager(ctx context.Context, handlerMux *http.ServeMux, logger glog.Logger) *P2PServerManager {
+func NewP2PServerManager(ctx context.Context, handler http.Handler, logger glog.Logger) *P2PServerManager {
 	return &P2PServerManager{
-		ctx:        ctx,
-		handlerMux: handlerMux,
-		logger:     logger,
+		ctx:     ctx,
+		handler: handler,
+		logger:  logger,
+		bridges: newRPCTracker(),
 	}
 }
 
@@ -37,24 +62,100 @@ func (p *P2PServerManager) Setup(cfg *Config) error {
 	// Create libp2p host
 	var err error
 
-	p.host, err = h.CreateLibp2pHost(
+	libp2pOptions := cfg.libp2pOptions
+	if cfg.resourceLimits != nil {
+		namespaces := make([]string, 0, len(cfg.namespaces))
+		for _, ns := range cfg.namespaces {
+			namespaces = append(namespaces, ns.name)
+		}
+		rm, err := core.NewResourceManager(*cfg.resourceLimits, cfg.protocolVersions, namespaces...)
+		if err != nil {
+			return fmt.Errorf("failed to create resource manager: %w", err)
+		}
+		libp2pOptions = append(slices.Clone(libp2pOptions), libp2p.ResourceManager(rm))
+	}
+
+	hostOptions := []h.HostOption{h.WithHostLogger(cfg.logger)}
+	if cfg.configFile != nil {
+		hostOptions = append(hostOptions, h.WithHostConfig(*cfg.configFile))
+	}
+	if cfg.identity != nil {
+		hostOptions = append(hostOptions, h.WithHostIdentity(cfg.identity))
+	}
+	if cfg.privateNetwork != nil {
+		hostOptions = append(hostOptions, h.WithHostPrivateNetwork(cfg.privateNetwork))
+	}
+	if cfg.addressBookPath != "" {
+		hostOptions = append(hostOptions, h.WithAddressBook(cfg.addressBookPath))
+	}
+	if cfg.eventHandler != nil {
+		hostOptions = append(hostOptions, h.WithHostEventHandler(cfg.eventHandler))
+	}
+	managed, err := h.CreateLibp2pHost(
 		p.ctx,
-		h.WithHostLogger(cfg.logger),
-		h.WithHostLibp2pOptions(cfg.libp2pOptions...),
-		h.WithHostDHTOptions(cfg.dhtOptions...),
+		append(hostOptions,
+			h.WithHostLibp2pOptions(libp2pOptions...),
+			h.WithHostDHTOptions(cfg.dhtOptions...),
+		)...,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create libp2p host: %w", err)
 	}
+	p.host = managed
+	p.events = managed.Events()
+	// The server owns the pool of its host; it is removed when the host closes
+	var poolSettings pool.Settings
+	if cfg.configFile != nil {
+		poolSettings = pool.Settings{
+			MaxIdleTime: cfg.configFile.Pool.MaxIdleTime.Std(),
+			MaxStreams:  cfg.configFile.Pool.MaxStreams,
+		}
+	}
+	pool.GetPoolWithSettings(p.host, cfg.logger, poolSettings)
+
+	listener, rpcServer, err := p.serve(cfg, "", p.handler)
+	if err != nil {
+		return err
+	}
+	p.listener = listener
+	p.server = rpcServer
+	for _, ns := range p.namespaces {
+		if _, ns.server, err = p.serve(cfg, ns.name, ns.handler); err != nil {
+			return fmt.Errorf("namespace %s: %w", ns.name, err)
+		}
+	}
 
-	// Create libp2p to HTTP bridge listener
-	p2pBridgeListener := core.NewLibp2pListener(p.host, config.DRPC_PROTOCOL_ID)
+	p.logger.Info("Set libp2p stream handlers for dRPC protocols",
+		glog.LogFields{"protocolIDs": p.protocols})
+
+	p.servicesMu.Lock()
+	p.accepting = true
+	p.servicesMu.Unlock()
+
+	return nil
+}
+
+// serve bridges the dRPC and web stream protocols of namespace, empty for the
+// default one, to handler on the host
+func (p *P2PServerManager) serve(cfg *Config, namespace string, handler http.Handler) (core.Libp2pListener, *http.Server, error) {
+	// Create libp2p to HTTP bridge listener serving every configured wire version
+	rpcProtocols, webStreamProtocols := cfg.rpcProtocolIDs()
+	rpcProtocols = core.NamespaceProtocolIDs(rpcProtocols, namespace)
+	webStreamProtocols = core.NamespaceProtocolIDs(webStreamProtocols, namespace)
+	p.protocols = slices.Concat(p.protocols, rpcProtocols, webStreamProtocols)
+	listenerOpts := []core.ListenerOption{core.WithExtraProtocols(rpcProtocols[1:]...)}
+	if cfg.acceptQueue != nil {
+		listenerOpts = append(listenerOpts, core.WithAcceptQueue(*cfg.acceptQueue))
+	}
+	p2pBridgeListener := core.NewLibp2pListener(p.host, rpcProtocols[0], listenerOpts...)
 
 	// Create HTTP/2 server for the P2P listener
-	rpcServer, err := createHTTP2Server(p.handlerMux, p2pBridgeListener.Addr().String())
+	rpcServer, err := createHTTP2Server(handler, p2pBridgeListener.Addr().String(), nil, true, cfg.http2Server())
 	if err != nil {
-		return fmt.Errorf("failed to create p2p HTTP server: %w", err)
+		_ = p2pBridgeListener.Close()
+		return nil, nil, fmt.Errorf("failed to create p2p HTTP server: %w", err)
 	}
+	rpcServer.ConnContext = core.ConnContext
 
 	// Start RPC server
 	go func() {
@@ -63,18 +164,41 @@ func (p *P2PServerManager) Setup(cfg *Config) error {
 		}
 	}()
 
-	p.server = rpcServer
-
 	// Set up the web stream envelope protocol handler
-	p.host.SetStreamHandler(config.DRPC_WEB_STREAM_PROTOCOL_ID, func(stream network.Stream) {
-		// Use ServeWebStreamBridge for handling web stream protocol
-		core.ServeWebStreamBridge(p.ctx, p.logger, p.handlerMux, stream)
-	})
+	bridgeCtx := p.ctx
+	if cfg.tracerProvider != nil {
+		// Bridge spans are created before the RPC pipeline is reached
+		bridgeCtx = tracing.ContextWithTracerProvider(bridgeCtx, cfg.tracerProvider)
+	}
+	webStreamHandler := func(stream network.Stream) {
+		if !p.bridges.begin() {
+			// Draining: the handler is about to be removed, refuse late arrivals
+			_ = stream.Reset()
+			return
+		}
+		defer p.bridges.end()
 
-	p.logger.Info("Set libp2p stream handler for web stream envelope protocol",
-		glog.LogFields{"protocolID": config.DRPC_WEB_STREAM_PROTOCOL_ID})
+		// Use ServeWebStreamBridge for handling web stream protocol
+		core.ServeWebStreamBridge(bridgeCtx, p.logger, handler, stream)
+	}
+	for _, pid := range webStreamProtocols {
+		p.host.SetStreamHandler(pid, webStreamHandler)
+	}
+	return p2pBridgeListener, rpcServer, nil
+}
 
-	return nil
+// servers returns the HTTP servers of the default namespace and the others
+func (p *P2PServerManager) servers() []*http.Server {
+	var servers []*http.Server
+	if p.server != nil {
+		servers = append(servers, p.server)
+	}
+	for _, ns := range p.namespaces {
+		if ns.server != nil {
+			servers = append(servers, ns.server)
+		}
+	}
+	return servers
 }
 
 // GetHost returns the libp2p host
@@ -109,27 +233,121 @@ func (p *P2PServerManager) P2PAddrs() []string {
 	return addrs
 }
 
+// StopAccepting removes the dRPC stream handlers so that new libp2p streams
+// are refused. Streams and connections that are already established keep being served.
+func (p *P2PServerManager) StopAccepting() {
+	if p.host == nil {
+		return
+	}
+	for _, pid := range p.protocols {
+		p.host.RemoveStreamHandler(pid)
+	}
+	p.stopPublishing()
+}
+
+// SetServices publishes the services this host serves. Each one gets a marker
+// protocol, which identify pushes to connected peers, and is advertised on the
+// DHT so clients can find the host by service name. Services missing from a
+// later call are withdrawn.
+func (p *P2PServerManager) SetServices(services []string) {
+	p.servicesMu.Lock()
+	defer p.servicesMu.Unlock()
+	if p.host == nil || !p.accepting {
+		return
+	}
+
+	sd := h.GetServiceDiscovery(p.host)
+	for _, name := range p.services {
+		if !slices.Contains(services, name) {
+			p.host.RemoveStreamHandler(core.ServiceProtocolID(name))
+			if sd != nil {
+				sd.Unadvertise(name)
+			}
+		}
+	}
+	for _, name := range services {
+		if !slices.Contains(p.services, name) {
+			p.host.SetStreamHandler(core.ServiceProtocolID(name), resetStream)
+		}
+	}
+	if sd != nil {
+		sd.Advertise(services...)
+	}
+	p.services = slices.Clone(services)
+}
+
+// stopPublishing withdraws every published service and stops re-providing them
+func (p *P2PServerManager) stopPublishing() {
+	p.servicesMu.Lock()
+	defer p.servicesMu.Unlock()
+	p.accepting = false
+
+	for _, name := range p.services {
+		p.host.RemoveStreamHandler(core.ServiceProtocolID(name))
+	}
+	p.services = nil
+	if sd := h.GetServiceDiscovery(p.host); sd != nil {
+		sd.Close()
+	}
+}
+
+// resetStream refuses streams on marker protocols, which only announce services
+func resetStream(stream network.Stream) {
+	_ = stream.Reset()
+}
+
+// Shutdown stops accepting new streams, sends HTTP/2 GOAWAY on the bridged
+// connections and waits for in-flight web stream bridges until ctx is done.
+// The libp2p host is left running; call Close to tear it down.
+func (p *P2PServerManager) Shutdown(ctx context.Context) error {
+	p.StopAccepting()
+	bridgesIdle := p.bridges.drain()
+
+	var errs []error
+	for _, server := range p.servers() {
+		// Shutdown closes the bridge listener and triggers GOAWAY on every HTTP/2 connection
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("p2p server shutdown error: %w", err))
+		}
+	}
+
+	select {
+	case <-bridgesIdle:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("%d web stream bridges still active: %w", p.bridges.Active(), ctx.Err()))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("p2p shutdown errors: %v", errs)
+	}
+	return nil
+}
+
 // Close gracefully shuts down the P2P server
 func (p *P2PServerManager) Close() error {
 	var errs []error
 
-	// Close P2P server
-	if p.server != nil {
+	// Close P2P servers
+	if servers := p.servers(); len(servers) > 0 {
 		// Set shorter timeouts for server shutdown
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		p.server.ReadTimeout = 5 * time.Second
-		p.server.WriteTimeout = 5 * time.Second
-		p.server.IdleTimeout = 5 * time.Second
+		for _, server := range servers {
+			server.ReadTimeout = 5 * time.Second
+			server.WriteTimeout = 5 * time.Second
+			server.IdleTimeout = 5 * time.Second
 
-		if err := p.server.Shutdown(ctx); err != nil {
-			errs = append(errs, fmt.Errorf("p2p server shutdown error: %w", err))
+			if err := server.Shutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("p2p server shutdown error: %w", err))
+			}
 		}
 	}
 
 	// Close P2P host
 	if p.host != nil {
+		p.stopPublishing()
+
 		// Force close all connections before closing host to prevent connection leaks
 		network := p.host.Network()
 		for _, conn := range network.Conns() {
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	gv1 "github.com/omgolab/drpc/demo/gen/go/greeter/v1"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	"github.com/omgolab/drpc/pkg/drpc"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// peerEchoServer replies with the entry path and peer ID seen by the handler
+type peerEchoServer struct {
+	gv1connect.UnimplementedGreeterServiceHandler
+}
+
+func (peerEchoServer) SayHello(ctx context.Context, _ *connect.Request[gv1.SayHelloRequest]) (*connect.Response[gv1.SayHelloResponse], error) {
+	info, ok := drpc.PeerFromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("no peer info in context"))
+	}
+	return connect.NewResponse(&gv1.SayHelloResponse{Message: fmt.Sprintf("%s|%s", info.Entry, info.ID)}), nil
+}
+
+// newPeerEchoServer starts a server on loopback libp2p and HTTP listeners
+func newPeerEchoServer(t *testing.T, ctx context.Context, opts ...ServerOption) *DRPCServer {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle(gv1connect.NewGreeterServiceHandler(peerEchoServer{}))
+	opts = append([]ServerOption{
+		WithLibP2POptions(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0")),
+		WithHTTPPort(0),
+	}, opts...)
+	server, err := New(ctx, mux, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// newGreeterClient creates a greeter client for addr with a listen-less libp2p host
+func newGreeterClient(ctx context.Context, addr string, libp2pOpts ...libp2p.Option) (gv1connect.GreeterServiceClient, error) {
+	logger, _ := glog.New()
+	return client.New(ctx, addr, gv1connect.NewGreeterServiceClient,
+		client.WithLogger(logger),
+		client.WithLibp2pOptions(append([]libp2p.Option{libp2p.NoListenAddrs}, libp2pOpts...)...),
+	)
+}
+
+func newSayHelloRequest() *connect.Request[gv1.SayHelloRequest] {
+	return connect.NewRequest(&gv1.SayHelloRequest{Name: "peer"})
+}
+
+// callSayHello calls SayHello through a fresh client for addr
+func callSayHello(ctx context.Context, addr string, libp2pOpts ...libp2p.Option) (string, error) {
+	c, err := newGreeterClient(ctx, addr, libp2pOpts...)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.SayHello(ctx, newSayHelloRequest())
+	if err != nil {
+		return "", err
+	}
+	return resp.Msg.Message, nil
+}
+
+func sayHello(t *testing.T, ctx context.Context, addr string) string {
+	t.Helper()
+
+	msg, err := callSayHello(ctx, addr)
+	if err != nil {
+		t.Fatalf("SayHello via %s failed: %v", addr, err)
+	}
+	return msg
+}
+
+func TestPeerFromContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	target := newPeerEchoServer(t, ctx)
+	gatewayServer := newPeerEchoServer(t, ctx)
+	targetAddr := target.P2PAddrs()[0]
+
+	t.Run("http", func(t *testing.T) {
+		if got := sayHello(t, ctx, target.HTTPAddr()); got != "http|" {
+			t.Errorf("Unexpected peer info: %q", got)
+		}
+	})
+
+	t.Run("libp2p", func(t *testing.T) {
+		got := sayHello(t, ctx, targetAddr)
+		if !strings.HasPrefix(got, "libp2p|12D3") {
+			t.Errorf("Unexpected peer info: %q", got)
+		}
+	})
+
+	t.Run("gateway", func(t *testing.T) {
+		addr := gatewayServer.HTTPAddr() + "/@" + targetAddr + "/@"
+		want := "gateway|" + gatewayServer.P2PHost().ID().String()
+		if got := sayHello(t, ctx, addr); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	})
+}
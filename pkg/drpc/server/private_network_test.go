@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	"github.com/omgolab/drpc/pkg/core/host"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestWithPrivateNetwork(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	psk := make(pnet.PSK, 32)
+	server := newPeerEchoServer(t, ctx, WithPrivateNetwork(psk))
+	addr := server.P2PAddrs()[0]
+
+	call := func(key pnet.PSK) error {
+		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+		logger, _ := glog.New()
+		c, err := client.New(ctx, addr, gv1connect.NewGreeterServiceClient,
+			client.WithLogger(logger),
+			client.WithLibp2pOptions(libp2p.NoListenAddrs),
+			client.WithPrivateNetwork(key),
+		)
+		if err != nil {
+			return err
+		}
+		_, err = c.SayHello(ctx, newSayHelloRequest())
+		return err
+	}
+	if err := call(psk); err != nil {
+		t.Fatalf("Member client failed: %v", err)
+	}
+
+	other := make(pnet.PSK, 32)
+	other[0] = 1
+	if err := call(other); !errors.Is(err, host.ErrPrivateNetwork) {
+		t.Errorf("Client of another network: %v, want host.ErrPrivateNetwork", err)
+	}
+	publicCtx, cancelPublic := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelPublic()
+	if _, err := callSayHello(publicCtx, addr); err == nil {
+		t.Error("A public client called a private server")
+	}
+	if _, err := New(ctx, nil, WithPrivateNetwork(psk[:8])); err == nil {
+		t.Error("Expected an 8 byte key to be refused")
+	}
+}
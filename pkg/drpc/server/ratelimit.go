@@ -0,0 +1,249 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/omgolab/drpc/pkg/core"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitSweepInterval is how often buckets of idle callers are dropped
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit is a token bucket refilled with Rate tokens per second and holding
+// at most Burst tokens. Every RPC takes one token. The zero value disables it.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// ProcedureRateLimit limits each caller's calls to the procedures matching Procedure
+type ProcedureRateLimit struct {
+	// Procedure is a full procedure ("/pkg.Service/Method"), a service prefix
+	// ending in "/" ("/pkg.Service/") or "*" for every procedure
+	Procedure string
+	RateLimit
+}
+
+// RateLimits configures the token buckets in front of the RPC handlers.
+// Callers are told apart by remote peer ID, or by client IP on the local HTTP
+// listener; gateway-forwarded calls count against the gateway node.
+type RateLimits struct {
+	// PerCaller limits every caller across all procedures
+	PerCaller RateLimit
+	// Procedures limit every caller per procedure; the first match applies and
+	// each caller gets its own bucket per rule
+	Procedures []ProcedureRateLimit
+}
+
+// RateLimitStats represents rate limiter statistics
+type RateLimitStats struct {
+	Allowed   int64
+	Throttled int64
+	Callers   int // callers with buckets, including ones idle until the next sweep
+}
+
+// WithRateLimits throttles RPCs with per-caller and per-procedure token buckets.
+// Throttled calls fail with a Connect ResourceExhausted error on every path,
+// carrying a Retry-After hint in seconds.
+func WithRateLimits(limits RateLimits) ServerOption {
+	return func(cfg *Config) error {
+		limiter, err := newRateLimiter(limits)
+		if err != nil {
+			return fmt.Errorf("invalid rate limits: %w", err)
+		}
+		cfg.rateLimiter = limiter
+		return nil
+	}
+}
+
+// validate checks that an enabled limit can ever let a call through
+func (l RateLimit) validate() error {
+	if l.Rate < 0 || l.Burst < 0 {
+		return errors.New("rate and burst must not be negative")
+	}
+	if (l.Rate > 0) != (l.Burst > 0) {
+		return errors.New("rate and burst must both be set")
+	}
+	return nil
+}
+
+func (l RateLimit) enabled() bool { return l.Rate > 0 }
+
+// refillTime is how long an empty bucket takes to fill up again
+func (l RateLimit) refillTime() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// callerBuckets holds the buckets of one caller
+type callerBuckets struct {
+	all        *rate.Limiter   // nil unless PerCaller is enabled
+	procedures []*rate.Limiter // indexed like RateLimits.Procedures, created on first use
+	lastSeen   time.Time
+}
+
+// rateLimiter is the compiled form of RateLimits
+type rateLimiter struct {
+	limits  RateLimits
+	idleTTL time.Duration // buckets idle this long are full again and can be dropped
+
+	mu        sync.Mutex
+	callers   map[string]*callerBuckets
+	lastSweep time.Time
+
+	allowed, throttled atomic.Int64
+}
+
+// newRateLimiter validates limits
+func newRateLimiter(limits RateLimits) (*rateLimiter, error) {
+	if err := limits.PerCaller.validate(); err != nil {
+		return nil, fmt.Errorf("per caller: %w", err)
+	}
+	rl := &rateLimiter{limits: limits, callers: make(map[string]*callerBuckets), lastSweep: time.Now()}
+	if limits.PerCaller.enabled() {
+		rl.idleTTL = limits.PerCaller.refillTime()
+	}
+	for i, p := range limits.Procedures {
+		if !validProcedurePattern(p.Procedure) {
+			return nil, fmt.Errorf("limit %d: procedure %q must start with '/' or be '*'", i, p.Procedure)
+		}
+		if err := p.RateLimit.validate(); err != nil {
+			return nil, fmt.Errorf("limit %d: %w", i, err)
+		}
+		if !p.enabled() {
+			return nil, fmt.Errorf("limit %d: no rate given for %q", i, p.Procedure)
+		}
+		rl.idleTTL = max(rl.idleTTL, p.refillTime())
+	}
+	if !limits.PerCaller.enabled() && len(limits.Procedures) == 0 {
+		return nil, errors.New("no limits given")
+	}
+	return rl, nil
+}
+
+// procedureLimit returns the index of the first procedure limit matching procedure, or -1
+func (rl *rateLimiter) procedureLimit(procedure string) int {
+	for i := range rl.limits.Procedures {
+		if matchProcedure(rl.limits.Procedures[i].Procedure, procedure) {
+			return i
+		}
+	}
+	return -1
+}
+
+// reserve takes a token from every bucket of caller that applies to
+// procedure. If one of them is empty, no token is taken and the time until
+// the call would be allowed is returned.
+func (rl *rateLimiter) reserve(caller, procedure string) (bool, time.Duration) {
+	now := time.Now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if now.Sub(rl.lastSweep) >= rateLimitSweepInterval {
+		rl.sweepLocked(now)
+	}
+
+	buckets, ok := rl.callers[caller]
+	if !ok {
+		buckets = &callerBuckets{procedures: make([]*rate.Limiter, len(rl.limits.Procedures))}
+		if l := rl.limits.PerCaller; l.enabled() {
+			buckets.all = rate.NewLimiter(rate.Limit(l.Rate), l.Burst)
+		}
+		rl.callers[caller] = buckets
+	}
+	buckets.lastSeen = now
+
+	limiters := make([]*rate.Limiter, 0, 2)
+	if buckets.all != nil {
+		limiters = append(limiters, buckets.all)
+	}
+	if i := rl.procedureLimit(procedure); i >= 0 {
+		if buckets.procedures[i] == nil {
+			l := rl.limits.Procedures[i]
+			buckets.procedures[i] = rate.NewLimiter(rate.Limit(l.Rate), l.Burst)
+		}
+		limiters = append(limiters, buckets.procedures[i])
+	}
+
+	reservations := make([]*rate.Reservation, 0, len(limiters))
+	var wait time.Duration
+	for _, l := range limiters {
+		r := l.ReserveN(now, 1)
+		reservations = append(reservations, r)
+		wait = max(wait, r.DelayFrom(now))
+	}
+	if wait == 0 {
+		rl.allowed.Add(1)
+		return true, 0
+	}
+	for _, r := range reservations {
+		r.CancelAt(now)
+	}
+	rl.throttled.Add(1)
+	return false, wait
+}
+
+// sweepLocked drops the buckets of callers idle long enough for them to be full again
+func (rl *rateLimiter) sweepLocked(now time.Time) {
+	for caller, buckets := range rl.callers {
+		if now.Sub(buckets.lastSeen) >= rl.idleTTL {
+			delete(rl.callers, caller)
+		}
+	}
+	rl.lastSweep = now
+}
+
+// stats returns the limiter statistics
+func (rl *rateLimiter) stats() RateLimitStats {
+	rl.mu.Lock()
+	callers := len(rl.callers)
+	rl.mu.Unlock()
+	return RateLimitStats{
+		Allowed:   rl.allowed.Load(),
+		Throttled: rl.throttled.Load(),
+		Callers:   callers,
+	}
+}
+
+// rateLimitKey identifies the caller of r. next must run behind core.PeerInfoHandler.
+func rateLimitKey(r *http.Request) string {
+	if info, ok := core.PeerInfoFromContext(r.Context()); ok && info.ID != "" {
+		return "peer:" + info.ID.String()
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// wrap rejects throttled calls with a Connect ResourceExhausted error
+func (rl *rateLimiter) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := rl.reserve(rateLimitKey(r), r.URL.Path); !ok {
+			err := connect.NewError(connect.CodeResourceExhausted,
+				fmt.Errorf("rate limit exceeded for %s, retry after %s", r.URL.Path, wait.Round(time.Millisecond)))
+			err.Meta().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeConnectError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RateLimitStats returns the statistics of the limiter set with WithRateLimits
+func (s *DRPCServer) RateLimitStats() (RateLimitStats, bool) {
+	if s.rateLimiter == nil {
+		return RateLimitStats{}, false
+	}
+	return s.rateLimiter.stats(), true
+}
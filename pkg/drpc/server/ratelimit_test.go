@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+)
+
+func TestRateLimits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx, WithMetrics(""), WithRateLimits(RateLimits{
+		PerCaller: RateLimit{Rate: 0.001, Burst: 3},
+		Procedures: []ProcedureRateLimit{
+			{Procedure: "/greeter.v1.GreeterService/SayHello", RateLimit: RateLimit{Rate: 0.001, Burst: 1}},
+		},
+	}))
+
+	sayHello(t, ctx, server.HTTPAddr())
+	_, err := callSayHello(ctx, server.HTTPAddr())
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted once the procedure bucket is empty, got %v", err)
+	}
+	if got := connectErr.Meta().Get("Retry-After"); got == "" || got == "0" {
+		t.Errorf("Expected a Retry-After hint, got %q", got)
+	}
+
+	// Every peer has buckets of its own
+	sayHello(t, ctx, server.P2PAddrs()[0])
+
+	stats, ok := server.RateLimitStats()
+	if !ok {
+		t.Fatal("Expected rate limit stats")
+	}
+	if want := (RateLimitStats{Allowed: 2, Throttled: 1, Callers: 2}); stats != want {
+		t.Errorf("RateLimitStats() = %+v, want %+v", stats, want)
+	}
+	if metrics := scrapeMetrics(t, server, "/metrics"); !strings.Contains(metrics, "drpc_rate_limit_throttled_total 1") {
+		t.Error("Metrics do not report the throttled call")
+	}
+}
+
+func TestWithRateLimitsValidation(t *testing.T) {
+	for name, limits := range map[string]RateLimits{
+		"empty":              {},
+		"burst without rate": {PerCaller: RateLimit{Burst: 1}},
+		"negative rate":      {PerCaller: RateLimit{Rate: -1, Burst: 1}},
+		"relative procedure": {Procedures: []ProcedureRateLimit{
+			{Procedure: "pkg.Service/", RateLimit: RateLimit{Rate: 1, Burst: 1}},
+		}},
+		"disabled procedure limit": {Procedures: []ProcedureRateLimit{{Procedure: "*"}}},
+	} {
+		cfg := GetDefaultConfig()
+		if err := WithRateLimits(limits)(&cfg); err == nil {
+			t.Errorf("%s: expected the limits to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+
+	"connectrpc.com/grpcreflect"
+	"github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1/adminv1connect"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Fully-qualified names of the reflection services, listed alongside the application services
+const (
+	reflectionV1Name      = "grpc.reflection.v1.ServerReflection"
+	reflectionV1AlphaName = "grpc.reflection.v1alpha.ServerReflection"
+)
+
+// WithReflection serves the gRPC server reflection API (v1 and v1alpha), so
+// tools can list services and fetch descriptors from any peer without local
+// .proto files. It is reachable over libp2p, the gateway, the web stream
+// bridge and HTTP. Every service the server routes is listed, including those
+// added with Register until they are removed with Unregister, along with the
+// admin service; services names further ones, e.g. "greeter.v1.GreeterService",
+// to list whether they are routed or not. The health service is not listed, as
+// its schema is registered under a private package to avoid clashing with
+// grpc-go. Descriptors are resolved from the global protobuf registry, which
+// generated code populates.
+func WithReflection(services ...string) ServerOption {
+	return func(cfg *Config) error {
+		for _, name := range services {
+			d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+			if err != nil {
+				return fmt.Errorf("reflection: unknown service %q: %w", name, err)
+			}
+			if _, ok := d.(protoreflect.ServiceDescriptor); !ok {
+				return fmt.Errorf("reflection: %q is not a service", name)
+			}
+		}
+		cfg.reflection = true
+		cfg.reflectedServices = append(cfg.reflectedServices, services...)
+		return nil
+	}
+}
+
+// newReflector lists the services routed at the time of each request next to
+// the ones named in cfg and the enabled built-in ones
+func (s *DRPCServer) newReflector(cfg *Config) *grpcreflect.Reflector {
+	named := slices.Clone(cfg.reflectedServices)
+	if cfg.admin != nil {
+		named = append(named, adminv1connect.AdminServiceName)
+	}
+	named = append(named, reflectionV1Name, reflectionV1AlphaName)
+
+	return grpcreflect.NewReflector(grpcreflect.NamerFunc(func() []string {
+		names := slices.Clone(named)
+		for _, name := range s.router.services() {
+			// Handlers registered at runtime need not be generated from a schema
+			if _, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name)); err == nil {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		return slices.Compact(names)
+	}))
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpcreflect"
+	"github.com/libp2p/go-libp2p"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	"github.com/omgolab/drpc/pkg/drpc/proto/drpc/admin/v1/adminv1connect"
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func newReflectionClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) *grpcreflect.Client {
+	return grpcreflect.NewClient(httpClient, baseURL, opts...)
+}
+
+func TestReflection(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx, WithHealthService(), WithReflection(greeterServiceName))
+	gatewayServer := newPeerEchoServer(t, ctx)
+	p2pAddr := server.P2PAddrs()[0]
+	logger, _ := glog.New()
+
+	tests := []struct {
+		name string
+		addr string
+		opts []connect.ClientOption
+	}{
+		{"http", server.HTTPAddr(), nil},
+		{"libp2p", p2pAddr, nil},
+		{"gateway", gatewayServer.HTTPAddr() + "/@" + p2pAddr + "/@", nil},
+		// gRPC carries the status in HTTP trailers, which the gateway must forward
+		{"gateway_grpc", gatewayServer.HTTPAddr() + "/@" + p2pAddr + "/@", []connect.ClientOption{connect.WithGRPC()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+			defer cancel()
+			c, err := client.New(ctx, tt.addr, newReflectionClient,
+				client.WithLogger(logger),
+				client.WithLibp2pOptions(libp2p.NoListenAddrs),
+				client.WithConnectOptions(tt.opts...),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create reflection client: %v", err)
+			}
+			stream := c.NewStream(ctx)
+			defer stream.Close()
+
+			services, err := stream.ListServices()
+			if err != nil {
+				t.Fatalf("ListServices failed: %v", err)
+			}
+			for _, want := range []protoreflect.FullName{greeterServiceName, reflectionV1Name} {
+				if !slices.Contains(services, want) {
+					t.Errorf("Expected %s in %v", want, services)
+				}
+			}
+
+			files, err := stream.FileContainingSymbol(greeterServiceName)
+			if err != nil {
+				t.Fatalf("FileContainingSymbol failed: %v", err)
+			}
+			if len(files) == 0 || files[0].GetName() != "greeter/v1/greeter.proto" {
+				t.Errorf("Unexpected descriptors: %v", files)
+			}
+		})
+	}
+
+	t.Run("webstream", func(t *testing.T) {
+		resp := callWebStream(t, ctx, server, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", `{"listServices":""}`)
+		if !strings.Contains(resp, greeterServiceName) {
+			t.Errorf("Expected %s in reflection response %q", greeterServiceName, resp)
+		}
+	})
+}
+
+func TestReflectionFollowsRegister(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// No service is named: the routed ones are listed
+	server := newPeerEchoServer(t, ctx, WithReflection())
+	logger, _ := glog.New()
+	c, err := client.New(ctx, server.HTTPAddr(), newReflectionClient, client.WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := func(name protoreflect.FullName) bool {
+		t.Helper()
+		stream := c.NewStream(ctx)
+		defer stream.Close()
+		services, err := stream.ListServices()
+		if err != nil {
+			t.Fatalf("ListServices failed: %v", err)
+		}
+		return slices.Contains(services, name)
+	}
+
+	if !listed(greeterServiceName) {
+		t.Errorf("Routed service %s is not listed", greeterServiceName)
+	}
+	path, handler := adminv1connect.NewAdminServiceHandler(adminv1connect.UnimplementedAdminServiceHandler{})
+	if err := server.Register(path, handler); err != nil {
+		t.Fatal(err)
+	}
+	if !listed(adminv1connect.AdminServiceName) {
+		t.Error("Service added with Register is not listed")
+	}
+	if err := server.Unregister(path); err != nil {
+		t.Fatal(err)
+	}
+	if listed(adminv1connect.AdminServiceName) {
+		t.Error("Service removed with Unregister is still listed")
+	}
+}
+
+func TestWithReflectionUnknownService(t *testing.T) {
+	cfg := GetDefaultConfig()
+	if err := WithReflection("missing.v1.Service")(&cfg); err == nil {
+		t.Error("Expected unknown service to be rejected")
+	}
+	if err := WithReflection("greeter.v1.SayHelloRequest")(&cfg); err == nil {
+		t.Error("Expected message name to be rejected")
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/omgolab/drpc/pkg/core"
+)
+
+func TestResourceLimits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx, WithResourceLimits(core.ResourceLimits{
+		DRPC: core.StreamLimits{Streams: 1},
+		// Less than the copy buffers a web stream reserves
+		WebStream: core.StreamLimits{Memory: 1024},
+	}))
+
+	// The first client keeps its stream open, which uses up the drpc service
+	first, err := newGreeterClient(ctx, server.P2PAddrs()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.SayHello(ctx, newSayHelloRequest()); err != nil {
+		t.Fatalf("First call failed: %v", err)
+	}
+	if _, err := callSayHello(ctx, server.P2PAddrs()[0]); connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Errorf("Expected ResourceExhausted over the stream limit, got %v", err)
+	}
+
+	if _, err := tryWebStream(ctx, server, sayHelloProcedure, `{"name":"web"}`); !core.IsResourceLimitError(err) {
+		t.Errorf("Expected the web stream to be reset over the memory limit, got %v", err)
+	}
+
+	// The local HTTP listener does not go through the resource manager
+	sayHello(t, ctx, server.HTTPAddr())
+}
+
+func TestResourceLimitsValidation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	limits := core.ResourceLimits{DRPC: core.StreamLimits{StreamsPerPeer: -1}}
+	if _, err := New(ctx, http.NewServeMux(), WithResourceLimits(limits)); err == nil {
+		t.Error("Expected negative limits to be rejected")
+	}
+}
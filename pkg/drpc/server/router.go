@@ -0,0 +1,167 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// serviceRouter routes RPCs to handlers registered at runtime and falls back to
+// the mux given to New. ServeMux cannot drop routes, so every change builds a
+// fresh mux that is swapped in atomically; in-flight RPCs keep their handler.
+type serviceRouter struct {
+	base *http.ServeMux // may be nil
+
+	mu      sync.Mutex
+	routes  map[string]http.Handler
+	current atomic.Pointer[http.ServeMux]
+}
+
+// newServiceRouter creates a router serving base until handlers are registered
+func newServiceRouter(base *http.ServeMux) *serviceRouter {
+	r := &serviceRouter{base: base, routes: make(map[string]http.Handler)}
+	r.current.Store(r.buildLocked())
+	return r
+}
+
+func (r *serviceRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.current.Load().ServeHTTP(w, req)
+}
+
+// register routes path to handler. The path must look like "/<service>/" and
+// must not be routed by the base mux already.
+func (r *serviceRouter) register(path string, handler http.Handler) error {
+	if !strings.HasPrefix(path, "/") || !strings.HasSuffix(path, "/") || len(path) < 3 {
+		return fmt.Errorf("invalid service path %q: expected /<service>/", path)
+	}
+	if handler == nil {
+		return fmt.Errorf("handler for %q must not be nil", path)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.routes[path]; ok || muxRoutes(r.base, path) {
+		return fmt.Errorf("service path %q is already registered", path)
+	}
+	r.routes[path] = handler
+	r.current.Store(r.buildLocked())
+	return nil
+}
+
+// unregister removes a path added with register
+func (r *serviceRouter) unregister(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.routes[path]; !ok {
+		if muxRoutes(r.base, path) {
+			return fmt.Errorf("service path %q was passed to New and cannot be unregistered", path)
+		}
+		return fmt.Errorf("service path %q is not registered", path)
+	}
+	delete(r.routes, path)
+	r.current.Store(r.buildLocked())
+	return nil
+}
+
+// services returns the services routed by the base mux and the registered paths, sorted by name
+func (r *serviceRouter) services() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := muxServiceNames(r.base)
+	for path := range r.routes {
+		if name := strings.Trim(path, "/"); !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// handles reports whether a handler is routed on exactly path
+func (r *serviceRouter) handles(path string) bool {
+	return muxRoutes(r.current.Load(), path) || muxRoutes(r.base, path)
+}
+
+func (r *serviceRouter) buildLocked() *http.ServeMux {
+	mux := http.NewServeMux()
+	for path, handler := range r.routes {
+		mux.Handle(path, handler)
+	}
+	if r.base != nil {
+		mux.Handle("/", r.base)
+	}
+	return mux
+}
+
+// muxRoutes reports whether mux has a route for exactly path
+func muxRoutes(mux *http.ServeMux, path string) bool {
+	if mux == nil {
+		return false
+	}
+	_, pattern := mux.Handler(&http.Request{Method: http.MethodPost, URL: &url.URL{Path: path}})
+	return pattern == path
+}
+
+// Register routes path to handler on the running server, typically the pair
+// returned by a generated NewXxxServiceHandler. The service becomes reachable
+// on every entry path right away, and peers see it through identify, the
+// /p2pinfo endpoint and DHT provider records.
+func (s *DRPCServer) Register(path string, handler http.Handler) error {
+	if s.router == nil {
+		return errors.New("server is not running")
+	}
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+	if muxRoutes(s.builtinRoutes, path) {
+		return fmt.Errorf("service path %q is reserved for a built-in service", path)
+	}
+	if err := s.router.register(path, handler); err != nil {
+		return err
+	}
+	s.publishServices()
+	s.logger.Info("Registered service", glog.LogFields{"path": path})
+	return nil
+}
+
+// Unregister removes a path added with Register. New RPCs on it fail with
+// Unimplemented while in-flight ones run to completion; the service is
+// withdrawn from identify, /p2pinfo and the DHT. Routes of the mux passed to
+// New cannot be removed.
+func (s *DRPCServer) Unregister(path string) error {
+	if s.router == nil {
+		return errors.New("server is not running")
+	}
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+	if err := s.router.unregister(path); err != nil {
+		return err
+	}
+	s.publishServices()
+	s.logger.Info("Unregistered service", glog.LogFields{"path": path})
+	return nil
+}
+
+// Services returns the services the server currently routes, sorted by name
+func (s *DRPCServer) Services() []string {
+	if s.router == nil {
+		return nil
+	}
+	return s.router.services()
+}
+
+// publishServices announces the current service set to other peers
+func (s *DRPCServer) publishServices() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.p2pManager != nil {
+		s.p2pManager.SetServices(s.router.services())
+	}
+}
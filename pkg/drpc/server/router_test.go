@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	"github.com/omgolab/drpc/pkg/core"
+)
+
+// fetchP2PInfoServices returns the services listed by the /p2pinfo endpoint of server
+func fetchP2PInfoServices(t *testing.T, server *DRPCServer) []string {
+	t.Helper()
+
+	resp, err := http.Get(server.HTTPAddr() + "/p2pinfo")
+	if err != nil {
+		t.Fatalf("Failed to fetch /p2pinfo: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var info struct{ Services []string }
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatalf("Failed to decode /p2pinfo: %v", err)
+	}
+	return info.Services
+}
+
+// waitForPeerServices waits until identify reports want as the services of a peer
+func waitForPeerServices(t *testing.T, ctx context.Context, protocols func() []string, want []string) {
+	t.Helper()
+
+	for !slices.Equal(protocols(), want) {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("Peer services = %v, want %v", protocols(), want)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestRegisterUnregisterService(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server, err := New(ctx, http.NewServeMux(),
+		WithLibP2POptions(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0")),
+		WithHTTPPort(0),
+		WithHealthService(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	// A connected peer learns the service set through identify pushes
+	observer, err := libp2p.New(libp2p.NoListenAddrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer observer.Close()
+	serverHost := server.P2PHost()
+	if err := observer.Connect(ctx, peer.AddrInfo{ID: serverHost.ID(), Addrs: serverHost.Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+	observedServices := func() []string {
+		protocols, _ := observer.Peerstore().GetProtocols(serverHost.ID())
+		return core.ServiceNames(protocols)
+	}
+
+	if _, err := callSayHello(ctx, server.HTTPAddr()); connect.CodeOf(err) != connect.CodeUnimplemented {
+		t.Fatalf("Expected Unimplemented before registration, got %v", err)
+	}
+
+	path, handler := gv1connect.NewGreeterServiceHandler(peerEchoServer{})
+	if err := server.Register(path, handler); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	sayHello(t, ctx, server.HTTPAddr())
+	sayHello(t, ctx, server.P2PAddrs()[0])
+
+	want := []string{greeterServiceName}
+	if got := server.Services(); !slices.Equal(got, want) {
+		t.Errorf("Services() = %v, want %v", got, want)
+	}
+	if got := fetchP2PInfoServices(t, server); !slices.Equal(got, want) {
+		t.Errorf("/p2pinfo services = %v, want %v", got, want)
+	}
+	waitForPeerServices(t, ctx, observedServices, want)
+
+	if err := server.Register(path, handler); err == nil {
+		t.Error("Expected duplicate registration to fail")
+	}
+	healthPath, _ := server.Health().handler()
+	if err := server.Register(healthPath, handler); err == nil {
+		t.Error("Expected registration over a built-in service to fail")
+	}
+
+	if err := server.Unregister(path); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	if _, err := callSayHello(ctx, server.HTTPAddr()); connect.CodeOf(err) != connect.CodeUnimplemented {
+		t.Errorf("Expected Unimplemented after unregistration, got %v", err)
+	}
+	if got := server.Services(); len(got) != 0 {
+		t.Errorf("Expected no services after unregistration, got %v", got)
+	}
+	if got := fetchP2PInfoServices(t, server); len(got) != 0 {
+		t.Errorf("Expected /p2pinfo to list no services, got %v", got)
+	}
+	waitForPeerServices(t, ctx, observedServices, nil)
+
+	if err := server.Unregister(path); err == nil {
+		t.Error("Expected unregistering an unknown path to fail")
+	}
+}
+
+func TestUnregisterRejectsInitialRoutes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx)
+	path, _ := gv1connect.NewGreeterServiceHandler(peerEchoServer{})
+	if err := server.Unregister(path); err == nil {
+		t.Error("Expected routes of the initial mux to stay registered")
+	}
+	if got := server.Services(); !slices.Equal(got, []string{greeterServiceName}) {
+		t.Errorf("Services() = %v, want [%s]", got, greeterServiceName)
+	}
+}
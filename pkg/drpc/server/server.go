@@ -7,8 +7,11 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/omgolab/drpc/pkg/config"
+	"github.com/omgolab/drpc/pkg/core"
 	"github.com/omgolab/drpc/pkg/detach"
 	glog "github.com/omgolab/go-commons/pkg/log"
 )
@@ -20,12 +23,22 @@ type DRPCServer struct {
 	httpManager *HTTPServerManager
 
 	// Core components
-	handlerMux *http.ServeMux
-	logger     glog.Logger
-	ctx        context.Context
+	handlerMux    *http.ServeMux
+	router        *serviceRouter // handlerMux plus services registered at runtime
+	builtinRoutes *http.ServeMux // routes of the built-in services, nil if none are enabled
+	logger        glog.Logger
+	ctx           context.Context
+	tracker       *rpcTracker    // in-flight RPCs, used for graceful drain
+	health        *HealthService // nil unless WithHealthService is set
+	metrics       *serverMetrics // nil unless WithMetrics is set
+	tracing       *serverTracing // nil unless WithTracing or WithTracerProvider is set
+	rateLimiter   *rateLimiter   // nil unless WithRateLimits is set
+
+	effectiveConfig config.File
 
 	// State management
-	mu sync.RWMutex
+	mu         sync.RWMutex
+	registryMu sync.Mutex // serializes Register and Unregister
 }
 
 // New creates a new dRPC server that uses both libp2p and HTTP/2 for transport with connectRPC based handlers.
@@ -44,7 +57,7 @@ func New(
 
 	// Start detached process if enabled
 	if cfg.isDetachServer {
-		server := &DRPCServer{ctx: ctx}
+		server := &DRPCServer{ctx: ctx, logger: cfg.logger, tracker: newRPCTracker()}
 		detachOpts := []detach.DetachOption{detach.WithLogger(cfg.logger)}
 		if len(cfg.detachOptions) > 0 {
 			detachOpts = append(detachOpts, cfg.detachOptions...)
@@ -55,21 +68,62 @@ func New(
 
 	// Create server instance
 	server := &DRPCServer{
-		handlerMux: connectRpcMuxHandler,
-		ctx:        ctx,
-		logger:     cfg.logger,
+		handlerMux:  connectRpcMuxHandler,
+		router:      newServiceRouter(connectRpcMuxHandler),
+		ctx:         ctx,
+		logger:      cfg.logger,
+		tracker:     newRPCTracker(),
+		rateLimiter: cfg.rateLimiter,
+
+		effectiveConfig: cfg.effectiveConfig(),
+	}
+
+	if cfg.healthService {
+		server.health = newHealthService(server.servingStatus)
+	}
+	if cfg.metricsPath != "" {
+		if cfg.httpPort < 0 {
+			return nil, errors.New("metrics are served on the HTTP listener, which is disabled")
+		}
+		server.metrics = newServerMetrics(server.P2PHost, server.AcceptStats, server.emitter, cfg.logger)
+		if server.rateLimiter != nil {
+			server.metrics.registerRateLimiter(server.rateLimiter)
+		}
 	}
+	server.tracing = newServerTracing(&cfg)
+
+	// Every path runs the same per-RPC pipeline in front of the mux
+	rpcHandler := server.buildRPCHandler(&cfg, server.mountBuiltinServices(&cfg, server.router), server.knownProcedure)
+	namespaces := server.namespaceHandlers(&cfg)
 
 	// Setup P2P server
-	server.p2pManager = NewP2PServerManager(ctx, connectRpcMuxHandler, cfg.logger)
+	server.p2pManager = NewP2PServerManager(ctx, server.tracker.wrap(rpcHandler), cfg.logger)
+	for _, ns := range namespaces {
+		server.p2pManager.namespaces = append(server.p2pManager.namespaces,
+			&p2pNamespace{name: ns.name, handler: server.tracker.wrap(ns.handler)})
+	}
 	if err := server.p2pManager.Setup(&cfg); err != nil {
+		// Setup may fail after starting the host and some of its bridges
+		_ = server.p2pManager.Close()
+		_ = server.tracing.shutdown(ctx)
 		return nil, err
 	}
 
+	// Let peers find this server by the services it routes
+	server.p2pManager.SetServices(server.router.services())
+
 	// Start HTTP server if enabled
 	if cfg.httpPort >= 0 {
-		server.httpManager = NewHTTPServerManager(ctx, connectRpcMuxHandler, cfg.logger)
+		server.httpManager = NewHTTPServerManager(ctx, rpcHandler, cfg.logger)
+		server.httpManager.tracker = server.tracker
+		server.httpManager.metrics = server.metrics
+		server.httpManager.events = server.emitter()
+		// Report the listener state to health watchers as soon as it is known
+		server.httpManager.health = server.health
+		server.httpManager.namespaces = namespaces
 		if err := server.httpManager.Setup(&cfg, server.p2pManager.Host()); err != nil {
+			_ = server.p2pManager.Close()
+			_ = server.tracing.shutdown(ctx)
 			return nil, err
 		}
 	}
@@ -88,6 +142,18 @@ func (s *DRPCServer) P2PHost() host.Host {
 	return s.p2pManager.Host()
 }
 
+// AcceptStats returns the statistics of the queue of libp2p streams waiting
+// for the server, set with WithAcceptQueue
+func (s *DRPCServer) AcceptStats() (core.AcceptStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.p2pManager == nil || s.p2pManager.listener == nil {
+		return core.AcceptStats{}, false
+	}
+	return s.p2pManager.listener.Stats(), true
+}
+
 // HTTPAddr returns the listening HTTP address (host:port) as a string.
 // Blocks until the HTTP server is listening or context is canceled.
 func (s *DRPCServer) HTTPAddr() string {
@@ -97,6 +163,30 @@ func (s *DRPCServer) HTTPAddr() string {
 	return s.httpManager.HTTPAddr()
 }
 
+// Health returns the built-in health service, or nil unless WithHealthService is set
+func (s *DRPCServer) Health() *HealthService {
+	return s.health
+}
+
+// servingStatus reports whether the server as a whole is serving
+func (s *DRPCServer) servingStatus() ServingStatus {
+	if !s.IsP2PRunning() || s.tracker.isDraining() {
+		return StatusNotServing
+	}
+	if s.httpManager != nil && !s.httpManager.isListening() {
+		return StatusNotServing
+	}
+	return StatusServing
+}
+
+// ReloadTLSCertificates re-reads the HTTP listener's TLS key pair from disk
+func (s *DRPCServer) ReloadTLSCertificates() error {
+	if s.httpManager == nil {
+		return errors.New("HTTP server is not running")
+	}
+	return s.httpManager.ReloadCertificates()
+}
+
 // P2PAddrs returns all p2p listening addresses
 func (s *DRPCServer) P2PAddrs() []string {
 	s.mu.RLock()
@@ -133,10 +223,78 @@ func (s *DRPCServer) GetP2PHost() host.Host {
 	return s.p2pManager.GetHost()
 }
 
+// Shutdown drains the server before closing it. New libp2p streams on the dRPC
+// protocols are refused, new RPCs are answered with Connect Unavailable and
+// HTTP/2 connections receive GOAWAY. In-flight handlers are given until ctx is
+// done to finish, after which the servers and the libp2p host are torn down.
+func (s *DRPCServer) Shutdown(ctx context.Context) error {
+	idle := s.tracker.drain()
+	if s.health != nil {
+		// Report NOT_SERVING and release health watchers so they do not hold up the drain
+		s.health.stop()
+	}
+
+	// Stop intake on the libp2p side first so no new streams race the drain
+	if s.p2pManager != nil {
+		s.p2pManager.StopAccepting()
+	}
+
+	var (
+		errs   []error
+		errsMu sync.Mutex
+		wg     sync.WaitGroup
+	)
+	addErr := func(err error) {
+		errsMu.Lock()
+		errs = append(errs, err)
+		errsMu.Unlock()
+	}
+
+	if s.httpManager != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.httpManager.Shutdown(ctx); err != nil {
+				addErr(err)
+			}
+		}()
+	}
+	if s.p2pManager != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.p2pManager.Shutdown(ctx); err != nil {
+				addErr(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-idle:
+		s.logger.Info("All in-flight RPCs drained")
+	case <-ctx.Done():
+		addErr(fmt.Errorf("%d RPCs still in flight: %w", s.tracker.Active(), ctx.Err()))
+	}
+
+	if err := s.Close(); err != nil {
+		addErr(err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("shutdown errors: %v", errs)
+}
+
 // Close gracefully shuts down both servers and the libp2p host.
 func (s *DRPCServer) Close() error {
 	var errs []error
 
+	if s.health != nil {
+		s.health.stop()
+	}
+
 	// Components to shut down (in order)
 	components := []struct {
 		name     string
@@ -154,6 +312,12 @@ func (s *DRPCServer) Close() error {
 			}
 			return s.p2pManager.Close()
 		}},
+		{"tracer provider", func() error {
+			// Flush the spans of the RPCs that just finished
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return s.tracing.shutdown(ctx)
+		}},
 	}
 
 	// Shut down all components
@@ -9,7 +9,11 @@ import (
 	"time" // Import time package
 
 	"github.com/libp2p/go-libp2p"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	"github.com/omgolab/drpc/pkg/core/leakcheck"
 	"github.com/omgolab/drpc/pkg/detach"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	glog "github.com/omgolab/go-commons/pkg/log"
 )
 
 const testTimeout = 10 * time.Second // Define a reasonable timeout for tests
@@ -195,3 +199,27 @@ func TestDetachedMode(t *testing.T) {
 	}
 	t.Log("Closed server")
 }
+
+func TestCloseReleasesGoroutines(t *testing.T) {
+	leakcheck.Check(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	server := newPeerEchoServer(t, ctx)
+	logger, _ := glog.New()
+	session, err := client.NewSession(ctx, client.WithLogger(logger), client.WithLibp2pOptions(libp2p.NoListenAddrs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := client.NewServiceClient(session, server.P2PAddrs()[0], gv1connect.NewGreeterServiceClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SayHello(ctx, newSayHelloRequest()); err != nil {
+		t.Fatalf("SayHello failed: %v", err)
+	}
+	// The client's host is closed with its session, the server's host with the server
+	if err := session.Close(); err != nil {
+		t.Errorf("Closing the client session failed: %v", err)
+	}
+}
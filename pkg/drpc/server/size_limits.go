@@ -0,0 +1,255 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"golang.org/x/net/http2"
+)
+
+// responseErrorAllowance is what may still be written after a response went
+// over its limit, so the handler can end the RPC with the ResourceExhausted error
+const responseErrorAllowance = 16 << 10
+
+// SizeLimits bound the data of a single RPC on every entry path. Sizes are
+// measured on the wire, after compression. Zero values leave a limit unset.
+type SizeLimits struct {
+	// MaxRequestBytes bounds the request body, across all messages of a stream
+	MaxRequestBytes int64
+	// MaxMessageBytes bounds each request message: the body of unary Connect
+	// calls and every enveloped message of streams and gRPC calls
+	MaxMessageBytes int64
+	// MaxResponseBytes bounds the response body, across all messages of a stream
+	MaxResponseBytes int64
+	// MaxConcurrentStreams bounds the concurrent RPCs of every HTTP/2
+	// connection: HTTP clients and bridged libp2p streams. Streams per peer are
+	// bounded with WithResourceLimits.
+	MaxConcurrentStreams uint32
+}
+
+// WithSizeLimits bounds request, message and response sizes and concurrent
+// HTTP/2 streams. RPCs over a size limit fail with a Connect ResourceExhausted
+// error; the handler sees it when reading or sending the offending message.
+func WithSizeLimits(limits SizeLimits) ServerOption {
+	return func(cfg *Config) error {
+		if limits.MaxRequestBytes < 0 || limits.MaxMessageBytes < 0 || limits.MaxResponseBytes < 0 {
+			return errors.New("invalid size limits: sizes must not be negative")
+		}
+		cfg.sizeLimits = &limits
+		return nil
+	}
+}
+
+// http2Server returns the HTTP/2 settings of the server's listeners
+func (cfg *Config) http2Server() *http2.Server {
+	h2s := &http2.Server{}
+	if cfg.sizeLimits != nil {
+		h2s.MaxConcurrentStreams = cfg.sizeLimits.MaxConcurrentStreams
+	}
+	return h2s
+}
+
+// wrap enforces the size limits on the RPCs served by next
+func (l SizeLimits) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		maxRequest := l.MaxRequestBytes
+		enveloped := isEnveloped(r)
+		if !enveloped && l.MaxMessageBytes > 0 && (maxRequest == 0 || l.MaxMessageBytes < maxRequest) {
+			// The body of a unary Connect call is its message
+			maxRequest = l.MaxMessageBytes
+		}
+		if maxRequest > 0 && r.ContentLength > maxRequest {
+			writeConnectError(w, r, connect.NewError(connect.CodeResourceExhausted,
+				fmt.Errorf("request body of %d bytes exceeds the limit of %d bytes", r.ContentLength, maxRequest)))
+			return
+		}
+
+		if maxRequest > 0 || (enveloped && l.MaxMessageBytes > 0) {
+			body := &limitedBody{body: r.Body, maxBody: maxRequest}
+			if enveloped {
+				body.maxMessage = l.MaxMessageBytes
+			}
+			r.Body = body
+		}
+		if l.MaxResponseBytes > 0 {
+			lw := &limitedResponseWriter{ResponseWriter: w, request: r, limit: l.MaxResponseBytes, enveloped: enveloped}
+			defer lw.commit()
+			w = lw
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isEnveloped reports whether the request body is a sequence of 5 byte
+// prefixed messages, as with Connect streams, gRPC and gRPC-Web
+func isEnveloped(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/connect+") || strings.HasPrefix(contentType, "application/grpc")
+}
+
+// envelopeScanner follows the 5 byte prefixes of enveloped messages in a
+// stream of bytes
+type envelopeScanner struct {
+	prefix    [5]byte
+	prefixLen int   // bytes of the current prefix seen
+	remaining int64 // bytes of the current message still to come
+}
+
+// scan advances over data, calling check with the position just past each
+// complete prefix and the size of its message. It stops at the first error.
+func (s *envelopeScanner) scan(data []byte, check func(end int, size int64) error) error {
+	pos := 0
+	for pos < len(data) {
+		if s.remaining > 0 {
+			skip := min(s.remaining, int64(len(data)-pos))
+			s.remaining -= skip
+			pos += int(skip)
+			continue
+		}
+		copied := copy(s.prefix[s.prefixLen:], data[pos:])
+		s.prefixLen += copied
+		pos += copied
+		if s.prefixLen < len(s.prefix) {
+			return nil
+		}
+		s.prefixLen = 0
+		s.remaining = int64(binary.BigEndian.Uint32(s.prefix[1:]))
+		if err := check(pos, s.remaining); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flags returns the flags of the prefix check was last called for
+func (s *envelopeScanner) flags() byte {
+	return s.prefix[0]
+}
+
+// limitedBody fails reads once the body or one of its enveloped messages
+// exceeds its limit
+type limitedBody struct {
+	body       io.ReadCloser
+	maxBody    int64 // 0 is unlimited
+	maxMessage int64 // 0 is unlimited or not enveloped
+	read       int64
+	envelopes  envelopeScanner
+	err        error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.maxBody > 0 && int64(len(p)) > b.maxBody-b.read+1 {
+		// Read one byte past the limit to detect bodies over it
+		p = p[:b.maxBody-b.read+1]
+	}
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if b.maxBody > 0 && b.read > b.maxBody {
+		b.err = connect.NewError(connect.CodeResourceExhausted,
+			fmt.Errorf("request body exceeds the limit of %d bytes", b.maxBody))
+		return 0, b.err
+	}
+	if b.maxMessage > 0 {
+		b.err = b.envelopes.scan(p[:n], func(_ int, size int64) error {
+			if size > b.maxMessage {
+				return connect.NewError(connect.CodeResourceExhausted,
+					fmt.Errorf("request message of %d bytes exceeds the limit of %d bytes", size, b.maxMessage))
+			}
+			return nil
+		})
+		if b.err != nil {
+			return 0, b.err
+		}
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+// limitedResponseWriter fails writes that would take the response over its
+// limit. Enveloped messages are refused as a whole from their prefix on, so
+// the stream can still end with an error, and writes after a failed one get a
+// small allowance for it. The status is held back until the first write, so a
+// unary response refused as a whole is replaced with the error.
+type limitedResponseWriter struct {
+	http.ResponseWriter
+	request   *http.Request
+	limit     int64
+	written   int64
+	enveloped bool
+	envelopes envelopeScanner
+	status    int
+	committed bool
+	exceeded  error
+}
+
+// WriteHeader holds status back; a later status replaces it until the first write
+func (w *limitedResponseWriter) WriteHeader(status int) {
+	if !w.committed {
+		w.status = status
+	}
+}
+
+func (w *limitedResponseWriter) Write(p []byte) (int, error) {
+	limit := w.limit
+	if w.exceeded != nil {
+		limit += responseErrorAllowance
+	}
+	end := w.written + int64(len(p))
+	envelopes := w.envelopes
+	if w.enveloped {
+		_ = envelopes.scan(p, func(pos int, size int64) error {
+			end = max(end, w.written+int64(pos)+size)
+			return nil
+		})
+	}
+	if end > limit {
+		w.exceeded = connect.NewError(connect.CodeResourceExhausted,
+			fmt.Errorf("response exceeds the limit of %d bytes", w.limit))
+		return 0, w.exceeded
+	}
+	w.envelopes = envelopes
+	w.commit()
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Flush sends the held back status, then flushes
+func (w *limitedResponseWriter) Flush() {
+	w.commit()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// commit writes the held back status, if any. A unary handler considers its
+// response sent even if the write failed, so the error is written in its place.
+func (w *limitedResponseWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	if w.exceeded != nil && !w.enveloped {
+		w.Header().Del("Content-Encoding")
+		w.Header().Del("Content-Length")
+		writeConnectError(w.ResponseWriter, w.request, w.exceeded)
+		return
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *limitedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/libp2p/go-libp2p"
+	gv1 "github.com/omgolab/drpc/demo/gen/go/greeter/v1"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+)
+
+// sizeEchoServer answers with payloads derived from the request size
+type sizeEchoServer struct {
+	gv1connect.UnimplementedGreeterServiceHandler
+}
+
+// SayHello answers with four times as many characters as the name has
+func (sizeEchoServer) SayHello(_ context.Context, req *connect.Request[gv1.SayHelloRequest]) (*connect.Response[gv1.SayHelloResponse], error) {
+	return connect.NewResponse(&gv1.SayHelloResponse{Message: randomText(4 * len(req.Msg.Name))}), nil
+}
+
+// StreamingEcho sends four messages as long as the request's
+func (sizeEchoServer) StreamingEcho(_ context.Context, req *connect.Request[gv1.StreamingEchoRequest], stream *connect.ServerStream[gv1.StreamingEchoResponse]) error {
+	for range 4 {
+		if err := stream.Send(&gv1.StreamingEchoResponse{Message: randomText(len(req.Msg.Message))}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BidiStreamingEcho acknowledges every message
+func (sizeEchoServer) BidiStreamingEcho(_ context.Context, stream *connect.BidiStream[gv1.BidiStreamingEchoRequest, gv1.BidiStreamingEchoResponse]) error {
+	for {
+		if _, err := stream.Receive(); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := stream.Send(&gv1.BidiStreamingEchoResponse{Greeting: "ok"}); err != nil {
+			return err
+		}
+	}
+}
+
+// randomText returns n incompressible characters, as limits apply after compression
+func randomText(n int) string {
+	data := make([]byte, n)
+	_, _ = rand.Read(data)
+	return base64.StdEncoding.EncodeToString(data)[:n]
+}
+
+// isResourceExhausted reports whether err is a Connect ResourceExhausted error
+func isResourceExhausted(err error) bool {
+	return connect.CodeOf(err) == connect.CodeResourceExhausted
+}
+
+func TestSizeLimits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	mux := http.NewServeMux()
+	mux.Handle(gv1connect.NewGreeterServiceHandler(sizeEchoServer{}))
+	server, err := New(ctx, mux,
+		WithLibP2POptions(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0")),
+		WithHTTPPort(0),
+		WithSizeLimits(SizeLimits{MaxRequestBytes: 4096, MaxMessageBytes: 1024, MaxResponseBytes: 2048}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	for entry, addr := range map[string]string{"http": server.HTTPAddr(), "libp2p": server.P2PAddrs()[0]} {
+		c, err := newGreeterClient(ctx, addr)
+		if err != nil {
+			t.Fatalf("%s: failed to create client: %v", entry, err)
+		}
+		sayHello := func(n int) error {
+			_, err := c.SayHello(ctx, connect.NewRequest(&gv1.SayHelloRequest{Name: randomText(n)}))
+			return err
+		}
+
+		if err := sayHello(300); err != nil {
+			t.Errorf("%s: call within the limits failed: %v", entry, err)
+		}
+		if err := sayHello(2000); !isResourceExhausted(err) {
+			t.Errorf("%s: unary message over the limit: %v, want ResourceExhausted", entry, err)
+		}
+		if err := sayHello(800); !isResourceExhausted(err) {
+			t.Errorf("%s: unary response over the limit: %v, want ResourceExhausted", entry, err)
+		}
+
+		// Three responses of about 600 bytes fit, the fourth does not
+		stream, err := c.StreamingEcho(ctx, connect.NewRequest(&gv1.StreamingEchoRequest{Message: randomText(600)}))
+		if err != nil {
+			t.Fatalf("%s: StreamingEcho failed: %v", entry, err)
+		}
+		received := 0
+		for stream.Receive() {
+			received++
+		}
+		if received != 3 || !isResourceExhausted(stream.Err()) {
+			t.Errorf("%s: streamed %d responses and ended with %v, want 3 and ResourceExhausted", entry, received, stream.Err())
+		}
+
+		for name, sizes := range map[string][]int{
+			"message over the limit":        {100, 1500},
+			"request body over the limit":   {900, 900, 900, 900, 900},
+			"request body within the limit": {900, 900, 900},
+		} {
+			bidi := c.BidiStreamingEcho(ctx)
+			var err error
+			for _, n := range sizes {
+				if err = bidi.Send(&gv1.BidiStreamingEchoRequest{Name: randomText(n)}); err != nil {
+					break
+				}
+				if _, err = bidi.Receive(); err != nil {
+					break
+				}
+			}
+			_ = bidi.CloseRequest()
+			if _, receiveErr := bidi.Receive(); err == nil && !errors.Is(receiveErr, io.EOF) {
+				err = receiveErr
+			}
+			_ = bidi.CloseResponse()
+
+			if within := strings.HasSuffix(name, "within the limit"); within && err != nil {
+				t.Errorf("%s: bidi stream with %s failed: %v", entry, name, err)
+			} else if !within && !isResourceExhausted(err) {
+				t.Errorf("%s: bidi stream with %s: %v, want ResourceExhausted", entry, name, err)
+			}
+		}
+	}
+}
+
+func TestWithSizeLimits(t *testing.T) {
+	cfg := GetDefaultConfig()
+	if cfg.http2Server().MaxConcurrentStreams != 0 {
+		t.Error("Expected the HTTP/2 default concurrent streams without size limits")
+	}
+	if err := WithSizeLimits(SizeLimits{MaxConcurrentStreams: 8})(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.http2Server().MaxConcurrentStreams; got != 8 {
+		t.Errorf("MaxConcurrentStreams = %d, want 8", got)
+	}
+	if err := WithSizeLimits(SizeLimits{MaxMessageBytes: -1})(&cfg); err == nil {
+		t.Error("Expected negative sizes to be rejected")
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"golang.org/x/net/http2"
+)
+
+// certCheckInterval bounds how often the certificate files are stat'ed for changes
+const certCheckInterval = time.Second
+
+// tlsFiles holds the certificate and key paths configured with WithTLS
+type tlsFiles struct {
+	certFile string
+	keyFile  string
+}
+
+// certReloader serves the configured key pair and reloads it from disk when
+// either file changes, so certificates can be rotated without a restart.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   glog.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	lastCheck time.Time
+}
+
+// newCertReloader loads the key pair once and returns a reloader for it
+func newCertReloader(certFile, keyFile string, logger glog.Logger) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the key pair from disk unconditionally.
+// On failure the previously loaded certificate is kept.
+func (r *certReloader) Reload() error {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	r.lastCheck = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.maybeReload()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// maybeReload reloads the key pair if the files changed since the last load
+func (r *certReloader) maybeReload() {
+	r.mu.RLock()
+	due := time.Since(r.lastCheck) >= certCheckInterval
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	certMod, keyMod, err := r.modTimes()
+
+	r.mu.Lock()
+	r.lastCheck = time.Now()
+	changed := err == nil && (!certMod.Equal(r.certMod) || !keyMod.Equal(r.keyMod))
+	r.mu.Unlock()
+
+	if err != nil {
+		r.logger.Error("Failed to stat TLS certificate files", err)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	if err := r.Reload(); err != nil {
+		r.logger.Error("Failed to reload TLS certificate, keeping previous one", err)
+		return
+	}
+	r.logger.Info("Reloaded TLS certificate", glog.LogFields{"certFile": r.certFile})
+}
+
+// modTimes returns the modification times of the certificate and key files
+func (r *certReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat TLS cert file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat TLS key file: %w", err)
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// serverTLSConfig returns a TLS config that negotiates h2 via ALPN and
+// takes its certificate from the reloader
+func serverTLSConfig(r *certReloader) *tls.Config {
+	return &tls.Config{
+		GetCertificate: r.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{http2.NextProtoTLS, "http/1.1"},
+	}
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"golang.org/x/net/http2"
+)
+
+// writeTestCert writes a self-signed localhost certificate with the given serial
+// and returns its parsed form for verification.
+func writeTestCert(t *testing.T, certFile, keyFile string, serial int64) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+// fetchPeerSerial performs an HTTP/2 request over a fresh TLS connection and
+// returns the serial of the certificate presented by the server
+func fetchPeerSerial(t *testing.T, url string, roots *x509.CertPool) *big.Int {
+	t.Helper()
+
+	httpClient := &http.Client{
+		Transport: &http2.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}},
+		Timeout:   testTimeout,
+	}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status: %d", resp.StatusCode)
+	}
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("Expected HTTP/2, got %s", resp.Proto)
+	}
+	return resp.TLS.PeerCertificates[0].SerialNumber
+}
+
+func TestTLSServer(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	first := writeTestCert(t, certFile, keyFile, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "OK")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	server, err := New(ctx, mux,
+		WithLibP2POptions(libp2p.NoListenAddrs),
+		WithHTTPPort(0),
+		WithTLS(certFile, keyFile),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	addr := server.HTTPAddr()
+	if !strings.HasPrefix(addr, "https://") {
+		t.Fatalf("Expected https address, got %q", addr)
+	}
+	url := strings.Replace(addr, "127.0.0.1", "localhost", 1) + "/"
+
+	roots := x509.NewCertPool()
+	roots.AddCert(first)
+	if got := fetchPeerSerial(t, url, roots); got.Cmp(first.SerialNumber) != 0 {
+		t.Fatalf("Expected serial %v, got %v", first.SerialNumber, got)
+	}
+
+	// Rotate the certificate on disk; the reloader picks it up on the next handshake
+	second := writeTestCert(t, certFile, keyFile, 2)
+	future := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, future, future); err != nil {
+			t.Fatalf("Failed to touch %s: %v", f, err)
+		}
+	}
+	time.Sleep(certCheckInterval + 100*time.Millisecond)
+
+	roots.AddCert(second)
+	if got := fetchPeerSerial(t, url, roots); got.Cmp(second.SerialNumber) != 0 {
+		t.Fatalf("Expected reloaded serial %v, got %v", second.SerialNumber, got)
+	}
+}
+
+func TestTLSInvalidKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, []byte("not a cert"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	server, err := New(ctx, http.NewServeMux(),
+		WithLibP2POptions(libp2p.NoListenAddrs),
+		WithHTTPPort(0),
+		WithTLS(certFile, keyFile),
+	)
+	if err == nil {
+		server.Close()
+		t.Fatal("Expected error for invalid key pair")
+	}
+}
+
+func TestPlaintextHTTPServer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "OK")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	server, err := New(ctx, mux, WithLibP2POptions(libp2p.NoListenAddrs), WithHTTPPort(0))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	addr := server.HTTPAddr()
+	if !strings.HasPrefix(addr, "http://") {
+		t.Fatalf("Expected http address without TLS, got %q", addr)
+	}
+	resp, err := http.Get(addr + "/")
+	if err != nil {
+		t.Fatalf("Plaintext request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status: %d", resp.StatusCode)
+	}
+}
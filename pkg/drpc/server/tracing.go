@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/omgolab/drpc/pkg/core"
+	"github.com/omgolab/drpc/pkg/core/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracing exports OpenTelemetry spans to exporter through a batching
+// tracer provider owned by the server and flushed on Close. Incoming W3C
+// traceparent headers are continued on every entry path; spans cover handler
+// execution, gateway forwarding, peer connects, pool stream acquisition and
+// web stream bridging.
+func WithTracing(exporter sdktrace.SpanExporter) ServerOption {
+	return func(cfg *Config) error {
+		if exporter == nil {
+			return errors.New("span exporter cannot be nil")
+		}
+		cfg.spanExporter = exporter
+		cfg.tracerProvider = nil
+		return nil
+	}
+}
+
+// WithTracerProvider is like WithTracing but creates spans with an existing
+// tracer provider, which the caller keeps ownership of.
+func WithTracerProvider(tp trace.TracerProvider) ServerOption {
+	return func(cfg *Config) error {
+		if tp == nil {
+			return errors.New("tracer provider cannot be nil")
+		}
+		cfg.tracerProvider = tp
+		cfg.spanExporter = nil
+		return nil
+	}
+}
+
+// serverTracing starts a span for every RPC served by a server
+type serverTracing struct {
+	provider trace.TracerProvider
+	owned    *sdktrace.TracerProvider // nil unless created for WithTracing
+}
+
+// newServerTracing returns the tracing configured in cfg, or nil if it is disabled.
+// It resolves an exporter to the provider the other components read from cfg.
+func newServerTracing(cfg *Config) *serverTracing {
+	t := &serverTracing{provider: cfg.tracerProvider}
+	if cfg.spanExporter != nil {
+		t.owned = sdktrace.NewTracerProvider(sdktrace.WithBatcher(cfg.spanExporter))
+		t.provider = t.owned
+		cfg.tracerProvider = t.owned
+	}
+	if t.provider == nil {
+		return nil
+	}
+	return t
+}
+
+// wrap continues the caller's trace with a server span around next. known
+// reports whether a service is routed on a procedure; other paths get one
+// span name so unknown calls do not flood the backend with names.
+func (t *serverTracing) wrap(next http.Handler, known func(procedure string) bool) http.Handler {
+	tracer := t.provider.Tracer(tracing.TracerName)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := unknownProcedure
+		attrs := []attribute.KeyValue{attribute.String("rpc.system", "connect_rpc")}
+		if known(r.URL.Path) {
+			name = strings.TrimPrefix(r.URL.Path, "/")
+			service, method, _ := strings.Cut(name, "/")
+			attrs = append(attrs, attribute.String("rpc.service", service), attribute.String("rpc.method", method))
+		}
+		if info, ok := core.PeerInfoFromContext(r.Context()); ok {
+			attrs = append(attrs, attribute.String("drpc.entry", string(info.Entry)))
+			if info.ID != "" {
+				attrs = append(attrs, attribute.String("drpc.peer.id", info.ID.String()))
+			}
+		}
+
+		ctx := tracing.ContextWithTracerProvider(tracing.Extract(r.Context(), r.Header), t.provider)
+		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+		defer span.End()
+		rec := &rpcRecorder{ResponseWriter: w, enveloped: isEnveloped(r)}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		// Failed calls are reported with the Connect error code they ended with
+		if code := rec.code(); code != 0 {
+			span.SetAttributes(attribute.String("rpc.connect_rpc.error_code", code.String()))
+			span.SetStatus(codes.Error, code.String())
+		}
+	})
+}
+
+// shutdown flushes and stops the tracer provider if the server owns it
+func (t *serverTracing) shutdown(ctx context.Context) error {
+	if t == nil || t.owned == nil {
+		return nil
+	}
+	return t.owned.Shutdown(ctx)
+}
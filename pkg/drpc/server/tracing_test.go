@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/omgolab/drpc/pkg/core"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const sayHelloSpan = greeterServiceName + "/SayHello"
+
+// waitForSpan waits until exporter holds the span named name of the trace traceID
+func waitForSpan(t *testing.T, ctx context.Context, exporter *tracetest.InMemoryExporter, traceID trace.TraceID, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for {
+		for _, span := range exporter.GetSpans() {
+			if span.SpanContext.TraceID() == traceID && span.Name == name {
+				return span
+			}
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("Span %q of trace %s was not exported", name, traceID)
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// spanEntry returns the entry path attribute of span
+func spanEntry(span tracetest.SpanStub) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == "drpc.entry" {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestTracePropagation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	target := newPeerEchoServer(t, ctx, WithTracerProvider(tp))
+	gatewayServer := newPeerEchoServer(t, ctx, WithTracerProvider(tp))
+
+	// call runs fn under a fresh client span and returns its span context
+	call := func(fn func(ctx context.Context)) trace.SpanContext {
+		spanCtx, span := tp.Tracer("test").Start(ctx, "client")
+		defer span.End()
+		fn(spanCtx)
+		return span.SpanContext()
+	}
+
+	for _, tc := range []struct {
+		name  string
+		entry core.EntryPath
+		addr  string
+	}{
+		{"http", core.EntryHTTP, target.HTTPAddr()},
+		{"libp2p", core.EntryLibp2p, target.P2PAddrs()[0]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := call(func(ctx context.Context) { sayHello(t, ctx, tc.addr) })
+			span := waitForSpan(t, ctx, exporter, parent.TraceID(), sayHelloSpan)
+			if span.Parent.SpanID() != parent.SpanID() {
+				t.Errorf("Handler span parent = %s, want the client span %s", span.Parent.SpanID(), parent.SpanID())
+			}
+			if got := spanEntry(span); got != string(tc.entry) {
+				t.Errorf("Handler span entry = %q, want %q", got, tc.entry)
+			}
+			if span.Status.Code != codes.Unset {
+				t.Errorf("Handler span status = %v for a successful call", span.Status)
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		denying := newPeerEchoServer(t, ctx, WithTracerProvider(tp), WithAccessPolicy(AccessPolicy{DefaultDeny: true}))
+		parent := call(func(ctx context.Context) {
+			if _, err := callSayHello(ctx, denying.P2PAddrs()[0]); connect.CodeOf(err) != connect.CodePermissionDenied {
+				t.Errorf("SayHello returned %v, want PermissionDenied", err)
+			}
+		})
+		span := waitForSpan(t, ctx, exporter, parent.TraceID(), sayHelloSpan)
+		if span.Status.Code != codes.Error || span.Status.Description != connect.CodePermissionDenied.String() {
+			t.Errorf("Handler span status = %v, want an error with permission_denied", span.Status)
+		}
+		found := false
+		for _, attr := range span.Attributes {
+			found = found || attr.Key == "rpc.connect_rpc.error_code" && attr.Value.AsString() == "permission_denied"
+		}
+		if !found {
+			t.Errorf("Handler span lacks the error code: %v", span.Attributes)
+		}
+	})
+
+	t.Run("gateway", func(t *testing.T) {
+		parent := call(func(ctx context.Context) {
+			sayHello(t, ctx, gatewayServer.HTTPAddr()+"/@"+target.P2PAddrs()[0]+"/@")
+		})
+		forward := waitForSpan(t, ctx, exporter, parent.TraceID(), "drpc.gateway.forward")
+		if forward.Parent.SpanID() != parent.SpanID() {
+			t.Errorf("Forward span parent = %s, want the client span %s", forward.Parent.SpanID(), parent.SpanID())
+		}
+		for _, name := range []string{"drpc.peer.connect", "drpc.pool.get_stream", "drpc.gateway.copy"} {
+			if span := waitForSpan(t, ctx, exporter, parent.TraceID(), name); span.Parent.SpanID() != forward.SpanContext.SpanID() {
+				t.Errorf("Span %q is not a child of the forward span", name)
+			}
+		}
+		handler := waitForSpan(t, ctx, exporter, parent.TraceID(), sayHelloSpan)
+		if handler.Parent.SpanID() != forward.SpanContext.SpanID() {
+			t.Error("Target handler span is not a child of the forward span")
+		}
+		if got := spanEntry(handler); got != string(core.EntryGateway) {
+			t.Errorf("Handler span entry = %q, want %q", got, core.EntryGateway)
+		}
+	})
+
+	t.Run("web stream", func(t *testing.T) {
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		traceparent := fmt.Sprintf("00-%s-00f067aa0ba902b7-01", traceID)
+		callWebStream(t, ctx, target, "/greeter.v1.GreeterService/SayHello?traceparent="+traceparent, `{"name":"web"}`)
+
+		bridge := waitForSpan(t, ctx, exporter, traceID, "drpc.webstream.bridge")
+		if got := bridge.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+			t.Errorf("Bridge span parent = %s, want the traceparent span", got)
+		}
+		handler := waitForSpan(t, ctx, exporter, traceID, sayHelloSpan)
+		if handler.Parent.SpanID() != bridge.SpanContext.SpanID() {
+			t.Error("Handler span is not a child of the bridge span")
+		}
+		if got := spanEntry(handler); got != string(core.EntryWebStream) {
+			t.Errorf("Handler span entry = %q, want %q", got, core.EntryWebStream)
+		}
+	})
+}
+
+// keepingExporter keeps its spans on Shutdown so they can be inspected after Close
+type keepingExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepingExporter) Shutdown(context.Context) error { return nil }
+
+func TestWithTracingFlushesOnClose(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	exporter := keepingExporter{tracetest.NewInMemoryExporter()}
+	server := newPeerEchoServer(t, ctx, WithTracing(exporter))
+	sayHello(t, ctx, server.HTTPAddr())
+	if err := server.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != sayHelloSpan {
+		t.Fatalf("Expected the handler span to be flushed on Close, got %d spans", len(spans))
+	}
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	gv1connect "github.com/omgolab/drpc/demo/gen/go/greeter/v1/greeterv1connect"
+	"github.com/omgolab/drpc/pkg/drpc"
+	"github.com/omgolab/drpc/pkg/drpc/client"
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// protocolRecorder returns middleware reporting the negotiated protocol of each RPC
+func protocolRecorder(ch chan<- protocol.ID) ServerOption {
+	return WithHTTPMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			info, _ := drpc.PeerFromContext(r.Context())
+			ch <- info.Protocol
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+func TestProtocolVersionNegotiation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	protocols := make(chan protocol.ID, 4)
+	server := newPeerEchoServer(t, ctx,
+		WithProtocolVersions("1.0.0", "1.1.0"),
+		protocolRecorder(protocols),
+	)
+	addr := server.P2PAddrs()[0]
+	logger, _ := glog.New()
+
+	tests := []struct {
+		name     string
+		versions []string
+		want     protocol.ID
+	}{
+		{"default_client", nil, "/drpc/1.0.0"},
+		{"newer_client", []string{"1.0.0", "1.1.0", "2.0.0"}, "/drpc/1.1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []client.Option{client.WithLogger(logger), client.WithLibp2pOptions(libp2p.NoListenAddrs)}
+			if tt.versions != nil {
+				opts = append(opts, client.WithProtocolVersions(tt.versions...))
+			}
+			c, err := client.New(ctx, addr, gv1connect.NewGreeterServiceClient, opts...)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			if _, err := c.SayHello(ctx, newSayHelloRequest()); err != nil {
+				t.Fatalf("SayHello failed: %v", err)
+			}
+			if got := <-protocols; got != tt.want {
+				t.Errorf("Negotiated %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no_common_version", func(t *testing.T) {
+		c, err := client.New(ctx, addr, gv1connect.NewGreeterServiceClient,
+			client.WithLogger(logger),
+			client.WithLibp2pOptions(libp2p.NoListenAddrs),
+			client.WithProtocolVersions("2.0.0"),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		if _, err := c.SayHello(ctx, newSayHelloRequest()); err == nil {
+			t.Fatal("Expected negotiation failure without a common version")
+		}
+	})
+}
+
+func TestWithProtocolVersionsValidation(t *testing.T) {
+	cfg := GetDefaultConfig()
+	if err := WithProtocolVersions()(&cfg); err == nil {
+		t.Error("Expected empty version list to be rejected")
+	}
+	if err := WithProtocolVersions("1.x")(&cfg); err == nil {
+		t.Error("Expected malformed version to be rejected")
+	}
+}
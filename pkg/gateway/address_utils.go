@@ -8,6 +8,35 @@ import (
 	ma "github.com/multiformats/go-multiaddr"
 )
 
+// ServiceAddrPrefix marks a target given by service name instead of peer
+// addresses, e.g. "service:greeter.v1.GreeterService". Providers of the
+// service are looked up on the DHT.
+const ServiceAddrPrefix = "service:"
+
+// ParseServiceAddress returns the service name of a "service:<name>" target
+func ParseServiceAddress(addr string) (string, bool) {
+	name, ok := strings.CutPrefix(strings.TrimPrefix(addr, "/"), ServiceAddrPrefix)
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// ParseGatewayServiceTarget parses gateway paths targeting a service by name:
+// /@/service:{name}/@{/service/method}
+// It returns false for address based targets.
+func ParseGatewayServiceTarget(path string) (string, string, bool) {
+	parts := strings.Split(path, GatewayPrefix)
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "/") {
+		return "", "", false
+	}
+	name, ok := ParseServiceAddress(parts[1])
+	if !ok {
+		return "", "", false
+	}
+	return name, parts[2], true
+}
+
 // ParseGatewayP2PAddresses parses addresses in the following formats:
 // HTTP gateway format (concise): /@{addr1,addr2,addr3...}/@{/service/method} (first and last /@ as delimiters)
 // Returns:
@@ -101,6 +101,50 @@ func TestParseGatewayPath(t *testing.T) {
 	}
 }
 
+func TestParseGatewayServiceTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		wantService string
+		wantPath    string
+		wantOK      bool
+	}{
+		{
+			name:        "Service target",
+			path:        "/@/service:greeter.v1.GreeterService/@/greeter.v1.GreeterService/SayHello",
+			wantService: "greeter.v1.GreeterService",
+			wantPath:    "/greeter.v1.GreeterService/SayHello",
+			wantOK:      true,
+		},
+		{
+			name:   "Address target",
+			path:   "/@//ip4/127.0.0.1/tcp/9090/p2p/12D3KooWRcDTroYkRCArLG69PasPsg26mbG9Pt5NvHjqJ9qfipx4/@/greeter/SayHello",
+			wantOK: false,
+		},
+		{
+			name:   "Empty service name",
+			path:   "/@/service:/@/greeter/SayHello",
+			wantOK: false,
+		},
+		{
+			name:   "No service path",
+			path:   "/@/service:greeter.v1.GreeterService",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, path, ok := ParseGatewayServiceTarget(tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseGatewayServiceTarget() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if service != tt.wantService || path != tt.wantPath {
+				t.Errorf("ParseGatewayServiceTarget() = (%q, %q), want (%q, %q)", service, path, tt.wantService, tt.wantPath)
+			}
+		})
+	}
+}
+
 func TestExtractPeerID(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/omgolab/drpc/pkg/core"
 	glog "github.com/omgolab/go-commons/pkg/log"
 )
 
@@ -71,6 +72,12 @@ func setCORSHeaders(w http.ResponseWriter, corsConfig *CORSConfig) {
 	header.Set("Access-Control-Expose-Headers", strings.Join(corsConfig.ExposedHeaders, ", "))
 }
 
+// CORSHandler sets the CORS headers of corsConfig on the responses of next
+// and answers preflight requests
+func CORSHandler(next http.Handler, corsConfig *CORSConfig) http.Handler {
+	return createOptimizedCORSMiddleware(next, corsConfig)
+}
+
 // createOptimizedCORSMiddleware creates CORS middleware that's optimized for hot paths
 func createOptimizedCORSMiddleware(next http.Handler, corsConfig *CORSConfig) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -99,12 +106,14 @@ func p2pInfoHandler(w http.ResponseWriter, r *http.Request, h host.Host, logger
 	}
 
 	info := struct {
-		ID    string   `json:"ID"`
-		Addrs []string `json:"Addrs"`
-		Port  string   `json:"Port"`
+		ID       string   `json:"ID"`
+		Addrs    []string `json:"Addrs"`
+		Port     string   `json:"Port"`
+		Services []string `json:"Services"`
 	}{
-		ID:    h.ID().String(),
-		Addrs: make([]string, 0, len(h.Addrs())),
+		ID:       h.ID().String(),
+		Addrs:    make([]string, 0, len(h.Addrs())),
+		Services: core.ServiceNames(h.Mux().Protocols()),
 	}
 
 	// Add addresses with peer ID
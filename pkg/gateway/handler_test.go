@@ -215,3 +215,25 @@ func getGatewayHandler(t *testing.T, h host.Host, customLogger ...glog.Logger) h
 
 	return SetupHandler(baseHandler, logger, h, nil)
 }
+
+func TestAdaptiveStreamCopyFlushesStreamsOnly(t *testing.T) {
+	logger, _ := glog.New()
+	for _, tt := range []struct {
+		contentType string
+		flushed     bool
+	}{
+		{"application/proto", false},
+		{"application/json", false},
+		{"application/connect+proto", true},
+		{"application/grpc", true},
+		{"application/grpc-web+proto", true},
+	} {
+		rec := httptest.NewRecorder()
+		if _, err := adaptiveStreamCopy(rec, strings.NewReader("body"), isStreamingContentType(tt.contentType), logger); err != nil {
+			t.Fatalf("adaptiveStreamCopy failed: %v", err)
+		}
+		if rec.Flushed != tt.flushed {
+			t.Errorf("Flushed = %v for %s, want %v", rec.Flushed, tt.contentType, tt.flushed)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package gateway
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"crypto/tls"
@@ -16,8 +19,12 @@ import (
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/omgolab/drpc/pkg/config"
 	"github.com/omgolab/drpc/pkg/core"
+	dhost "github.com/omgolab/drpc/pkg/core/host"
 	"github.com/omgolab/drpc/pkg/core/pool"
+	"github.com/omgolab/drpc/pkg/core/tracing"
 	glog "github.com/omgolab/go-commons/pkg/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/http2"
 )
 
@@ -48,6 +55,29 @@ var (
 	addressKeys    []string // Track insertion order for LRU eviction
 )
 
+// Address cache lookup counters
+var addressCacheHits, addressCacheMisses atomic.Int64
+
+// AddressCacheStats represents gateway address cache statistics
+type AddressCacheStats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+}
+
+// GetAddressCacheStats returns the gateway address cache statistics
+func GetAddressCacheStats() AddressCacheStats {
+	addressCacheMu.RLock()
+	entries := len(addressCache)
+	addressCacheMu.RUnlock()
+
+	return AddressCacheStats{
+		Hits:    addressCacheHits.Load(),
+		Misses:  addressCacheMisses.Load(),
+		Entries: entries,
+	}
+}
+
 // getCachedAddress retrieves a parsed address from cache if not expired
 func getCachedAddress(path string) (map[peer.ID][]ma.Multiaddr, string, bool) {
 	addressCacheMu.RLock()
@@ -55,10 +85,12 @@ func getCachedAddress(path string) (map[peer.ID][]ma.Multiaddr, string, bool) {
 
 	if entry, exists := addressCache[path]; exists {
 		if time.Now().Before(entry.expires) {
+			addressCacheHits.Add(1)
 			return entry.peerAddrs, entry.servicePath, true
 		}
 		// Entry expired but we'll clean it up later to avoid lock upgrade
 	}
+	addressCacheMisses.Add(1)
 	return nil, "", false
 }
 
@@ -172,7 +204,16 @@ func max(a, b int) int {
 
 // ForwardHTTPRequest handles the entire request forwarding process using standard Go HTTP client
 // Enhanced with address caching, adaptive buffering, and improved error recovery.
+// The incoming W3C trace context is continued by a forward span, whose context
+// is passed on to the target peer.
 func ForwardHTTPRequest(w http.ResponseWriter, r *http.Request, p2pHost host.Host, logger glog.Logger) {
+	ctx, span := tracing.Start(tracing.Extract(r.Context(), r.Header), "drpc.gateway.forward",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("url.path", r.URL.Path)),
+	)
+	var err error
+	defer func() { tracing.End(span, err) }()
+
 	// DEBUG: Log incoming request method, proto, headers
 	if config.DEBUG {
 		logger.Printf("[DEBUG] Incoming request: Method=%s Proto=%s ProtoMajor=%d ProtoMinor=%d URI=%s", r.Method, r.Proto, r.ProtoMajor, r.ProtoMinor, r.RequestURI)
@@ -183,9 +224,17 @@ func ForwardHTTPRequest(w http.ResponseWriter, r *http.Request, p2pHost host.Hos
 	// Check cache for parsed addresses first
 	var peerAddrs map[peer.ID][]ma.Multiaddr
 	var servicePath string
-	var err error
 
-	if cachedPeerAddrs, cachedServicePath, found := getCachedAddress(r.URL.Path); found {
+	if serviceName, path, ok := ParseGatewayServiceTarget(r.URL.Path); ok {
+		// Providers change over time, so service lookups are not cached
+		servicePath = path
+		peerAddrs, err = findServiceProviders(ctx, p2pHost, serviceName)
+		if err != nil {
+			logger.Printf("Failed to find providers of service '%s': %v", serviceName, err)
+			http.Error(w, fmt.Sprintf("Failed to find providers: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+	} else if cachedPeerAddrs, cachedServicePath, found := getCachedAddress(r.URL.Path); found {
 		// Fast path: use cached parsed addresses
 		peerAddrs = cachedPeerAddrs
 		servicePath = cachedServicePath
@@ -213,12 +262,13 @@ func ForwardHTTPRequest(w http.ResponseWriter, r *http.Request, p2pHost host.Hos
 
 	// Try connecting to peers in parallel with improved error recovery
 	connectedPeerID, err := pool.ConnectToFirstAvailablePeer(
-		r.Context(),
+		ctx,
 		p2pHost,
 		addrInfoMap,
 		logger,
 	)
 	if err != nil {
+		err = dhost.PrivateNetworkError(p2pHost, err)
 		logger.Printf("Failed to connect to any peer: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to connect to any peer: %v", err), http.StatusInternalServerError)
 		return
@@ -233,11 +283,12 @@ func ForwardHTTPRequest(w http.ResponseWriter, r *http.Request, p2pHost host.Hos
 	transport := &http2.Transport{
 		AllowHTTP: true,
 		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
-			protocolID := config.DRPC_PROTOCOL_ID
-			stream, err := connPool.GetStream(r.Context(), connectedPeerID, protocolID)
+			// Offer every supported wire version, highest first
+			protocolIDs := core.DRPCProtocolIDs()
+			stream, err := connPool.GetStream(ctx, connectedPeerID, protocolIDs...)
 			if err != nil {
-				logger.Printf("DialTLS: Dialing %s with app protocol %s", connectedPeerID, protocolID)
-				logger.Printf("Failed to get stream for dial to %s using protocol %s: %v", connectedPeerID, protocolID, err)
+				logger.Printf("DialTLS: Dialing %s with app protocols %v", connectedPeerID, protocolIDs)
+				logger.Printf("Failed to get stream for dial to %s using protocols %v: %v", connectedPeerID, protocolIDs, err)
 				return nil, err
 			}
 			// Return the stream wrapped in net.Conn
@@ -247,7 +298,7 @@ func ForwardHTTPRequest(w http.ResponseWriter, r *http.Request, p2pHost host.Hos
 	}
 
 	// Clone the request to modify it
-	req := r.Clone(r.Context())
+	req := r.Clone(ctx)
 
 	// Modify the request path to be the service path expected by the ConnectRPC handler
 	// servicePath already includes the leading '/'
@@ -261,6 +312,12 @@ func ForwardHTTPRequest(w http.ResponseWriter, r *http.Request, p2pHost host.Hos
 	// Clear RequestURI, as it should not be set in client requests
 	req.RequestURI = ""
 
+	// Let the remote handler know the call came through a gateway
+	req.Header.Set(core.GatewayForwardedHeader, "1")
+
+	// Make the remote handler span a child of the forward span
+	tracing.Inject(ctx, req.Header)
+
 	// Set Connect-RPC headers if needed
 	if r.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/connect+proto")
@@ -317,24 +374,75 @@ func ForwardHTTPRequest(w http.ResponseWriter, r *http.Request, p2pHost host.Hos
 		}
 	}
 
-	// Set response status
+	// Set response status and send the headers right away so streaming
+	// clients (server and bidi streams) are not blocked on the first message
 	w.WriteHeader(resp.StatusCode)
+	streaming := isStreamingContentType(resp.Header.Get("Content-Type"))
+	if flusher, ok := w.(http.Flusher); ok && streaming {
+		flusher.Flush()
+	}
 
 	// Copy response body using adaptive buffering with pipelining
-	if _, err = adaptiveStreamCopy(w, resp.Body, logger); err != nil {
+	_, copySpan := tracing.Start(ctx, "drpc.gateway.copy")
+	copied, err := adaptiveStreamCopy(w, resp.Body, streaming, logger)
+	copySpan.SetAttributes(attribute.Int64("drpc.bytes", copied))
+	tracing.End(copySpan, err)
+	if err != nil {
 		logger.Printf("Failed to copy response body: %v", err)
 		// Too late to change the status code here, client already has headers
 		return
 	}
 
+	// Forward trailers, which carry the status for the gRPC protocol
+	for key, values := range resp.Trailer {
+		for _, value := range values {
+			w.Header().Add(http.TrailerPrefix+key, value)
+		}
+	}
+
 	logger.Printf("ForwardHTTPRequest - Successfully forwarded request to %s", servicePath)
 }
 
-// adaptiveStreamCopy uses adaptive buffering and pipelining for optimized copying
-func adaptiveStreamCopy(dst io.Writer, src io.Reader, logger glog.Logger) (int64, error) {
+// findServiceProviders looks up the peers providing serviceName on the DHT of h
+func findServiceProviders(ctx context.Context, h host.Host, serviceName string) (map[peer.ID][]ma.Multiaddr, error) {
+	sd := dhost.GetServiceDiscovery(h)
+	if sd == nil {
+		return nil, fmt.Errorf("service discovery is not available on this host")
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, config.PEER_CONNECTION_TIMEOUT)
+	defer cancel()
+	providers, err := sd.FindProviders(lookupCtx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	peerAddrs := make(map[peer.ID][]ma.Multiaddr, len(providers))
+	for _, p := range providers {
+		peerAddrs[p.ID] = append(peerAddrs[p.ID], p.Addrs...)
+	}
+	return peerAddrs, nil
+}
+
+// isStreamingContentType reports whether a response carries enveloped
+// messages, i.e. a Connect stream or a gRPC or gRPC-Web call, rather than the
+// single body of a unary Connect call
+func isStreamingContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/connect+") || strings.HasPrefix(contentType, "application/grpc")
+}
+
+// adaptiveStreamCopy uses adaptive buffering and pipelining for optimized
+// copying. With flush, every chunk is flushed so streamed messages reach the
+// client as they arrive.
+func adaptiveStreamCopy(dst io.Writer, src io.Reader, flush bool, logger glog.Logger) (int64, error) {
 	adaptiveBuf := newAdaptiveBuffer()
 	defer adaptiveBuf.close()
 
+	var flusher http.Flusher
+	if flush {
+		flusher, _ = dst.(http.Flusher)
+	}
+
 	totalBytes := int64(0)
 	chunkCount := 0
 
@@ -354,6 +462,9 @@ func adaptiveStreamCopy(dst io.Writer, src io.Reader, logger glog.Logger) (int64
 				return totalBytes, fmt.Errorf("error writing chunk: bytesWritten=%d/%d, totalBytes=%d: %w",
 					n, bytesRead, totalBytes, writeErr)
 			}
+			if flusher != nil {
+				flusher.Flush()
+			}
 
 			// Adjust buffer size based on usage
 			adaptiveBuf.adjustSize(bytesRead)